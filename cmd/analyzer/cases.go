@@ -0,0 +1,335 @@
+// This source file is part of the attendance list project
+// as a part of the go lecture by H. Neemann.
+// For this reason you have no permission to use, modify or
+// share this code without the agreement of the authors.
+//
+// Matriculation numbers of the authors: 5703004, 5736465
+
+package main
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/dateiexplorer/attendancelist/internal/journal"
+	"github.com/dateiexplorer/attendancelist/internal/timeutil"
+)
+
+// An indexCase is a person of a case list with the date of the onset of the
+// symptoms.
+type indexCase struct {
+	ID        string
+	FirstName string
+	LastName  string
+	Onset     timeutil.Date
+}
+
+// readCases reads a case list from the CSV file at filePath with the columns
+//
+// caseID,firstName,lastName,onset
+//
+// where onset is of form YYYY/mm/dd. A first line which starts with "caseID"
+// is treated as header and skipped.
+//
+// Returns an error if the file cannot be read, a line has not four columns,
+// an onset cannot be parsed or a case ID is used twice.
+func readCases(filePath string) ([]indexCase, error) {
+	f, err := os.Open(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("cannot open case list: %w", err)
+	}
+	defer f.Close()
+
+	reader := csv.NewReader(f)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("cannot parse case list: %w", err)
+	}
+
+	cases := make([]indexCase, 0, len(records))
+	ids := make(map[string]bool, len(records))
+	for i, record := range records {
+		if len(record) != 4 {
+			return nil, fmt.Errorf("cannot parse case list: line %v has %v columns, expected 4", i+1, len(record))
+		}
+
+		if i == 0 && strings.EqualFold(record[0], "caseid") {
+			continue
+		}
+
+		onset, err := timeutil.ParseDate(record[3])
+		if err != nil {
+			return nil, fmt.Errorf("cannot parse case list: onset on line %v: %w", i+1, err)
+		}
+
+		if ids[record[0]] {
+			return nil, fmt.Errorf("cannot parse case list: case ID \"%v\" on line %v is not unique", record[0], i+1)
+		}
+
+		ids[record[0]] = true
+		cases = append(cases, indexCase{record[0], record[1], record[2], onset})
+	}
+
+	return cases, nil
+}
+
+// A caseContact is a Contact of the index case with the ID CaseID.
+type caseContact struct {
+	CaseID string
+	journal.Contact
+}
+
+// A caseContactList holds the contacts of several index cases.
+type caseContactList []caseContact
+
+// NextEntry returns a read-only channel that loops through the hole
+// caseContactList and returns the case ID and the data of the Contact as a
+// string slice.
+//
+// Used to convert a caseContactList to any file format.
+func (l caseContactList) NextEntry() <-chan []string {
+	entries := make(chan []string)
+	go func() {
+		for _, c := range l {
+			for e := range (journal.ContactList{c.Contact}).NextEntry() {
+				entries <- append([]string{c.CaseID}, e...)
+			}
+		}
+
+		close(entries)
+	}()
+
+	return entries
+}
+
+// Header returns a string slice which describes the data given by the NextEntry
+// function.
+//
+// Used to convert a caseContactList to any file format.
+func (l caseContactList) Header() []string {
+	return append([]string{"CaseID"}, journal.ContactList{}.Header()...)
+}
+
+// An exposure is a person who had contact with several index cases.
+type exposure struct {
+	Person  journal.Person
+	CaseIDs []string
+}
+
+// An exposureList is the cross-case summary of a caseContactList.
+type exposureList []exposure
+
+// exposures returns the persons of the caseContactList who had contact with at
+// least two different index cases, the persons with most cases first and
+// otherwise ordered by name.
+func (l caseContactList) exposures() exposureList {
+	cases := make(map[journal.Person][]string)
+	persons := make([]journal.Person, 0)
+	for _, c := range l {
+		ids, ok := cases[c.Person]
+		if !ok {
+			persons = append(persons, c.Person)
+		}
+
+		if len(ids) == 0 || ids[len(ids)-1] != c.CaseID {
+			cases[c.Person] = append(ids, c.CaseID)
+		}
+	}
+
+	exposures := exposureList{}
+	for _, p := range persons {
+		if len(cases[p]) > 1 {
+			exposures = append(exposures, exposure{p, cases[p]})
+		}
+	}
+
+	sort.Slice(exposures, func(i, j int) bool {
+		a, b := exposures[i], exposures[j]
+		if len(a.CaseIDs) != len(b.CaseIDs) {
+			return len(a.CaseIDs) > len(b.CaseIDs)
+		}
+
+		return a.Person.String() < b.Person.String()
+	})
+
+	return exposures
+}
+
+// NextEntry returns a read-only channel that loops through the hole
+// exposureList and returns the data of the person, the number of cases and the
+// case IDs separated by ";" as a string slice.
+//
+// Used to convert an exposureList to any file format.
+func (l exposureList) NextEntry() <-chan []string {
+	entries := make(chan []string)
+	go func() {
+		for _, e := range l {
+			p := e.Person
+			entries <- []string{p.FirstName, p.LastName, p.Address.Street, p.Address.Number, p.Address.ZipCode, p.Address.City,
+				fmt.Sprint(len(e.CaseIDs)), strings.Join(e.CaseIDs, ";")}
+		}
+
+		close(entries)
+	}()
+
+	return entries
+}
+
+// Header returns a string slice which describes the data given by the NextEntry
+// function.
+//
+// Used to convert an exposureList to any file format.
+func (l exposureList) Header() []string {
+	return []string{"FirstName", "LastName", "Street", "Number", "ZipCode", "City", "Cases", "CaseIDs"}
+}
+
+// traceCases returns the contacts of all index cases. The contacts of a case
+// are searched in the journals from daysBefore days before to daysAfter days
+// after its onset. The person of a case is searched by name in the same
+// journals. The cases are traced by the given number of workers in parallel,
+// at least one, the contacts are returned in the order of the cases.
+//
+// The IDs of the cases whose person didn't visit any location in this time
+// are returned as well, these cases have no contacts.
+//
+// Returns an error if more than one person matches the name of a case.
+func traceCases(journals []journal.Journal, cases []indexCase, daysBefore int, daysAfter int, workers int) (caseContactList, []string, error) {
+	windows := make([][]journal.Journal, len(cases))
+	persons := make([]*journal.Person, len(cases))
+	missing := make([]string, 0)
+	for i, c := range cases {
+		windows[i] = caseJournals(journals, c, daysBefore, daysAfter)
+		candidates := bestCandidates(rankPersons(uniquePersons(windows[i]...), nameTerms(c.FirstName, c.LastName)))
+		if len(candidates) == 0 {
+			missing = append(missing, c.ID)
+			continue
+		}
+
+		p, err := selectCandidate(candidates)
+		if err != nil {
+			return nil, nil, fmt.Errorf("cannot trace case %v: %w", c.ID, err)
+		}
+
+		persons[i] = &p
+	}
+
+	results := make([]caseContactList, len(cases))
+	indices := make(chan int)
+	var wg sync.WaitGroup
+	if workers < 1 {
+		workers = 1
+	}
+
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indices {
+				results[i] = traceCase(windows[i], cases[i], persons[i])
+			}
+		}()
+	}
+
+	for i := range cases {
+		if persons[i] != nil {
+			indices <- i
+		}
+	}
+
+	close(indices)
+	wg.Wait()
+
+	contacts := caseContactList{}
+	for _, r := range results {
+		contacts = append(contacts, r...)
+	}
+
+	return contacts, missing, nil
+}
+
+// caseJournals returns the journals from daysBefore days before to daysAfter
+// days after the onset of the index case c.
+func caseJournals(journals []journal.Journal, c indexCase, daysBefore int, daysAfter int) []journal.Journal {
+	from, to := c.Onset.AddDays(-daysBefore), c.Onset.AddDays(daysAfter)
+	window := make([]journal.Journal, 0)
+	for _, j := range journals {
+		if !j.Date.Before(from) && !to.Before(j.Date) {
+			window = append(window, j)
+		}
+	}
+
+	return window
+}
+
+// traceCase returns the contacts of the person p of the index case c in the
+// journals ordered by their start.
+func traceCase(journals []journal.Journal, c indexCase, p *journal.Person) caseContactList {
+	contacts := caseContactList{}
+	for _, j := range journals {
+		for _, contact := range j.GetContactsForPerson(p) {
+			contacts = append(contacts, caseContact{c.ID, contact})
+		}
+	}
+
+	sort.Slice(contacts, func(i, j int) bool {
+		a, b := contacts[i], contacts[j]
+		if !a.Start.Equal(b.Start.Time) {
+			return a.Start.Before(b.Start.Time)
+		}
+
+		return a.Person.String() < b.Person.String()
+	})
+
+	return contacts
+}
+
+// createCaseContacts writes the contacts of all index cases of the case list
+// at casesPath to filePath and the persons exposed to several cases to
+// summaryPath, if it is set. The returned message lists these persons. The
+// cases are traced with opts.workers workers.
+func createCaseContacts(journals []journal.Journal, casesPath string, daysBefore int, daysAfter int, opts readOptions, filePath string, summaryPath string, format string) (string, error) {
+	if daysBefore < 0 || daysAfter < 0 {
+		return "", errors.New("the number of days before and after the onset must not be negative")
+	}
+
+	cases, err := readCases(casesPath)
+	if err != nil {
+		return "", err
+	}
+
+	contacts, missing, err := traceCases(journals, cases, daysBefore, daysAfter, opts.workers)
+	if err != nil {
+		return "", err
+	}
+
+	msg, err := writeOutput(contacts, filePath, format)
+	if err != nil {
+		return "", err
+	}
+
+	exposures := contacts.exposures()
+	if len(summaryPath) > 0 {
+		if _, err := writeOutput(exposures, summaryPath, format); err != nil {
+			return "", err
+		}
+	}
+
+	msg += fmt.Sprintf("%v contacts of %v cases found, %v persons were exposed to several cases.\n", len(contacts), len(cases), len(exposures))
+	if len(missing) > 0 {
+		msg += fmt.Sprintf("No visits found for the cases %v.\n", strings.Join(missing, ", "))
+	}
+
+	for _, e := range exposures {
+		msg += fmt.Sprintf("  %v (%v)\n", e.Person.String(), strings.Join(e.CaseIDs, ", "))
+	}
+
+	return msg, nil
+}
@@ -0,0 +1,166 @@
+// This source file is part of the attendance list project
+// as a part of the go lecture by H. Neemann.
+// For this reason you have no permission to use, modify or
+// share this code without the agreement of the authors.
+//
+// Matriculation numbers of the authors: 5703004, 5736465
+
+package main
+
+import (
+	"os"
+	"path"
+	"strings"
+	"testing"
+
+	"github.com/dateiexplorer/attendancelist/internal/journal"
+	"github.com/dateiexplorer/attendancelist/internal/timeutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReadCases(t *testing.T) {
+	expected := []indexCase{
+		{"C1", "Hans", "Mueller", timeutil.NewDate(2021, 10, 14)},
+		{"C2", "Gisela", "Musterfrau", timeutil.NewDate(2021, 11, 30)},
+		{"C3", "Max", "Mustermann", timeutil.NewDate(2021, 10, 16)},
+	}
+
+	actual, err := readCases("testdata/cases.csv")
+	assert.NoError(t, err)
+	assert.Equal(t, expected, actual)
+}
+
+func TestReadCasesFailed(t *testing.T) {
+	_, err := readCases("testdata/missing.csv")
+	assert.Error(t, err)
+
+	filePath := path.Join(t.TempDir(), "cases.csv")
+	for _, content := range []string{"C1,Hans,Müller\n", "C1,Hans,Müller,15.10.2021\n", "C1,Hans,Müller,2021/10/15\nC1,Anne,Meier,2021/10/15\n"} {
+		assert.NoError(t, os.WriteFile(filePath, []byte(content), 0600))
+		_, err = readCases(filePath)
+		assert.Error(t, err, content)
+	}
+}
+
+func TestTraceCases(t *testing.T) {
+	journals, err := readJournals("testdata", timeutil.NewDate(2021, 10, 15), timeutil.NewDate(2021, 11, 30), readOptions{})
+	assert.NoError(t, err)
+
+	cases, err := readCases("testdata/cases.csv")
+	assert.NoError(t, err)
+
+	contacts, missing, err := traceCases(journals, cases, 2, 10, 4)
+	assert.NoError(t, err)
+	assert.Empty(t, missing)
+
+	// The cases are traced in parallel but listed in the order of the list.
+	ids := make([]string, 0, len(contacts))
+	for _, c := range contacts {
+		ids = append(ids, c.CaseID)
+	}
+
+	assert.Equal(t, strings.Split("C1,C1,C1,C1,C1,C2,C2,C2,C2,C3,C3,C3,C3", ","), ids)
+
+	// There is no journal file for the day of the onset of C1.
+	contacts, missing, err = traceCases(journals, cases[:1], 0, 0, 4)
+	assert.NoError(t, err)
+	assert.Empty(t, contacts)
+	assert.Equal(t, []string{"C1"}, missing)
+
+	contacts, missing, err = traceCases(journals, []indexCase{{"C4", "Erika", "Mustermann", timeutil.NewDate(2021, 10, 15)}}, 2, 10, 4)
+	assert.NoError(t, err)
+	assert.Empty(t, contacts)
+	assert.Equal(t, []string{"C4"}, missing)
+}
+
+func TestTraceCasesNamesWithSeparators(t *testing.T) {
+	anne := journal.NewPerson("Anne", "Meier=Schulz, geb. Braun", "Hauptstraße", "18", "74821", "Mosbach")
+	journals := []journal.Journal{{Date: timeutil.NewDate(2021, 10, 15), Entries: []journal.JournalEntry{
+		journal.NewJournalEntry(timeutil.NewTimestamp(2021, 10, 15, 9, 0, 0), "1ce7549a51133e9f", journal.Login, "DHBW Mosbach", anne),
+		journal.NewJournalEntry(timeutil.NewTimestamp(2021, 10, 15, 10, 0, 0), "68e7faee906ffd4c", journal.Login, "DHBW Mosbach", lieschen),
+		journal.NewJournalEntry(timeutil.NewTimestamp(2021, 10, 15, 11, 0, 0), "1ce7549a51133e9f", journal.Logout, "DHBW Mosbach", anne),
+		journal.NewJournalEntry(timeutil.NewTimestamp(2021, 10, 15, 12, 0, 0), "68e7faee906ffd4c", journal.Logout, "DHBW Mosbach", lieschen),
+	}}}
+
+	contacts, missing, err := traceCases(journals, []indexCase{{"C1", "Anne", "Meier=Schulz, geb. Braun", timeutil.NewDate(2021, 10, 15)}}, 0, 0, 4)
+	assert.NoError(t, err)
+	assert.Empty(t, missing)
+	assert.Equal(t, 1, len(contacts))
+	assert.Equal(t, lieschen, contacts[0].Person)
+}
+
+func TestTraceCasesOnlyPersonsOfWindow(t *testing.T) {
+	other := journal.NewPerson("Hans", "Müller", "Feldweg", "14", "74722", "Buchen")
+	journals := []journal.Journal{
+		{Date: timeutil.NewDate(2021, 10, 15), Entries: []journal.JournalEntry{
+			journal.NewJournalEntry(timeutil.NewTimestamp(2021, 10, 15, 9, 0, 0), "d61ec70b78628e15", journal.Login, "DHBW Mosbach", hans),
+			journal.NewJournalEntry(timeutil.NewTimestamp(2021, 10, 15, 10, 0, 0), "68e7faee906ffd4c", journal.Login, "DHBW Mosbach", lieschen),
+			journal.NewJournalEntry(timeutil.NewTimestamp(2021, 10, 15, 11, 0, 0), "d61ec70b78628e15", journal.Logout, "DHBW Mosbach", hans),
+			journal.NewJournalEntry(timeutil.NewTimestamp(2021, 10, 15, 12, 0, 0), "68e7faee906ffd4c", journal.Logout, "DHBW Mosbach", lieschen),
+		}},
+		{Date: timeutil.NewDate(2021, 11, 30), Entries: []journal.JournalEntry{
+			journal.NewJournalEntry(timeutil.NewTimestamp(2021, 11, 30, 9, 0, 0), "5faacdf0e6e7b44a", journal.Login, "Alte Mälzerei", other),
+			journal.NewJournalEntry(timeutil.NewTimestamp(2021, 11, 30, 10, 0, 0), "5faacdf0e6e7b44a", journal.Logout, "Alte Mälzerei", other),
+		}},
+	}
+
+	// The other Hans Müller doesn't visit any location in the window of the
+	// case, so the name is unambiguous.
+	contacts, missing, err := traceCases(journals, []indexCase{{"C1", "Hans", "Müller", timeutil.NewDate(2021, 10, 15)}}, 2, 10, 4)
+	assert.NoError(t, err)
+	assert.Empty(t, missing)
+	assert.Equal(t, 1, len(contacts))
+	assert.Equal(t, lieschen, contacts[0].Person)
+
+	_, _, err = traceCases(journals, []indexCase{{"C1", "Hans", "Müller", timeutil.NewDate(2021, 10, 15)}}, 2, 50, 4)
+	assert.Error(t, err)
+}
+
+func TestCaseContactListExposures(t *testing.T) {
+	hans := journal.NewPerson("Hans", "Müller", "Feldweg", "12", "74722", "Buchen")
+	anne := journal.NewPerson("Anne", "Meier", "Hauptstraße", "18", "74821", "Mosbach")
+	otto := journal.NewPerson("Otto", "Normalverbraucher", "Dieselstraße", "52", "70376", "Stuttgart")
+	start := timeutil.NewTimestamp(2021, 10, 15, 9, 0, 0)
+	end := timeutil.NewTimestamp(2021, 10, 15, 10, 0, 0)
+	contacts := caseContactList{
+		{"C1", journal.NewContact(hans, "DHBW Mosbach", start, end)},
+		{"C1", journal.NewContact(anne, "DHBW Mosbach", start, end)},
+		{"C1", journal.NewContact(anne, "Alte Mälzerei", start, end)},
+		{"C2", journal.NewContact(anne, "DHBW Mosbach", start, end)},
+		{"C2", journal.NewContact(otto, "DHBW Mosbach", start, end)},
+		{"C3", journal.NewContact(otto, "DHBW Mosbach", start, end)},
+		{"C3", journal.NewContact(anne, "DHBW Mosbach", start, end)},
+	}
+
+	assert.Equal(t, exposureList{{anne, []string{"C1", "C2", "C3"}}, {otto, []string{"C2", "C3"}}}, contacts.exposures())
+
+	entry := <-contacts.NextEntry()
+	assert.Equal(t, "C1", entry[0])
+	assert.Equal(t, len(contacts.Header()), len(entry))
+}
+
+func TestCreateCaseContacts(t *testing.T) {
+	journals, err := readJournals("testdata", timeutil.NewDate(2021, 10, 15), timeutil.NewDate(2021, 11, 30), readOptions{})
+	assert.NoError(t, err)
+
+	dir := t.TempDir()
+	msg, err := createCaseContacts(journals, "testdata/cases.csv", 2, 10, readOptions{workers: 2}, path.Join(dir, "contacts.csv"), path.Join(dir, "summary.csv"), "csv")
+	assert.NoError(t, err)
+	assert.Equal(t, "Output successfully written.\n"+
+		"13 contacts of 3 cases found, 4 persons were exposed to several cases.\n"+
+		"  Anne,Meier,Hauptstraße,18,74821,Mosbach (C1, C2, C3)\n"+
+		"  Lieschen,Müller,Lindenstraße,15,10115,Berlin (C1, C2, C3)\n"+
+		"  Gisela,Musterfrau,Musterstraße,10,74821,Mosbach (C1, C3)\n"+
+		"  Max,Mustermann,Musterstraße,20,74821,Mosbach (C1, C2)\n", msg)
+
+	summary, err := os.ReadFile(path.Join(dir, "summary.csv"))
+	assert.NoError(t, err)
+	assert.Equal(t, "FirstName,LastName,Street,Number,ZipCode,City,Cases,CaseIDs\n"+
+		"Anne,Meier,Hauptstraße,18,74821,Mosbach,3,C1;C2;C3\n"+
+		"Lieschen,Müller,Lindenstraße,15,10115,Berlin,3,C1;C2;C3\n"+
+		"Gisela,Musterfrau,Musterstraße,10,74821,Mosbach,2,C1;C3\n"+
+		"Max,Mustermann,Musterstraße,20,74821,Mosbach,2,C1;C2\n", string(summary))
+
+	_, err = createCaseContacts(journals, "testdata/cases.csv", -1, 10, readOptions{}, "", "", "csv")
+	assert.Error(t, err)
+}
@@ -0,0 +1,91 @@
+// This source file is part of the attendance list project
+// as a part of the go lecture by H. Neemann.
+// For this reason you have no permission to use, modify or
+// share this code without the agreement of the authors.
+//
+// Matriculation numbers of the authors: 5703004, 5736465
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/dateiexplorer/attendancelist/internal/chart"
+	"github.com/dateiexplorer/attendancelist/internal/journal"
+	"github.com/dateiexplorer/attendancelist/internal/timeutil"
+)
+
+// createChart renders the chart of the kind occupancy, heatmap or dwell for
+// the journals of the date range from to to and writes it to filePath. The
+// image format is taken from the file extension, .png or .svg. If location is
+// set, only the visits of this location are shown. bin is the width of the
+// bars of the dwell time distribution.
+func createChart(journals []journal.Journal, kind string, from, to timeutil.Date, location string, bin time.Duration, filePath string) (string, error) {
+	ext := strings.ToLower(filepath.Ext(filePath))
+	if ext != ".png" && ext != ".svg" {
+		return "", fmt.Errorf("unknown image format \"%v\", the filename must end with .png or .svg", ext)
+	}
+
+	if bin <= 0 {
+		return "", fmt.Errorf("the width of the bars must be positive")
+	}
+
+	occupancy := journal.OccupancyList{}
+	durations := make([]time.Duration, 0)
+	for _, j := range journals {
+		if len(location) > 0 {
+			occupancy = append(occupancy, j.GetOccupancyForLocation(journal.Location(location)))
+		} else {
+			occupancy = append(occupancy, j.GetOccupancy()...)
+		}
+
+		for _, s := range j.GetSessions() {
+			if len(location) == 0 || s.Location == journal.Location(location) {
+				durations = append(durations, s.Duration(j.Date))
+			}
+		}
+	}
+
+	period := from.String()
+	if from != to {
+		period += " to " + to.String()
+	}
+
+	if len(location) > 0 {
+		period += " at " + location
+	}
+
+	var d *chart.Drawing
+	switch kind {
+	case "occupancy":
+		d = chart.Occupancy("Occupancy "+period, occupancy.Timeline(), from.At(0), to.AddDays(1).At(0))
+	case "heatmap":
+		d = chart.Heatmap("Visitors per hour "+period, occupancy)
+	case "dwell":
+		d = chart.Dwell("Time of stay "+period, durations, bin)
+	default:
+		return "", fmt.Errorf("unknown chart \"%v\", use occupancy, heatmap or dwell", kind)
+	}
+
+	f, err := os.Create(filePath)
+	if err != nil {
+		return "", fmt.Errorf("cannot create file: %w", err)
+	}
+	defer f.Close()
+
+	if ext == ".svg" {
+		err = d.WriteSVG(f)
+	} else {
+		err = d.WritePNG(f)
+	}
+
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintln("Output successfully written."), nil
+}
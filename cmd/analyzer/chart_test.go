@@ -0,0 +1,58 @@
+// This source file is part of the attendance list project
+// as a part of the go lecture by H. Neemann.
+// For this reason you have no permission to use, modify or
+// share this code without the agreement of the authors.
+//
+// Matriculation numbers of the authors: 5703004, 5736465
+
+package main
+
+import (
+	"fmt"
+	"image/png"
+	"os"
+	"path"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dateiexplorer/attendancelist/internal/timeutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCreateChart(t *testing.T) {
+	from, to := timeutil.NewDate(2021, 10, 15), timeutil.NewDate(2021, 11, 30)
+	journals, err := readJournals("testdata", from, to, readOptions{})
+	assert.NoError(t, err)
+
+	dir := t.TempDir()
+	for _, kind := range []string{"occupancy", "heatmap", "dwell"} {
+		filePath := path.Join(dir, kind+".png")
+		msg, err := createChart(journals, kind, from, to, "", 30*time.Minute, filePath)
+		assert.NoError(t, err)
+		assert.Equal(t, fmt.Sprintln("Output successfully written."), msg)
+
+		f, err := os.Open(filePath)
+		assert.NoError(t, err)
+		_, err = png.Decode(f)
+		assert.NoError(t, err, kind)
+		f.Close()
+	}
+
+	filePath := path.Join(dir, "heatmap.svg")
+	_, err = createChart(journals, "heatmap", from, to, "Alte Mälzerei", 30*time.Minute, filePath)
+	assert.NoError(t, err)
+
+	content, err := os.ReadFile(filePath)
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(string(content), "<svg"))
+	assert.Contains(t, string(content), ">Visitors per hour 2021-10-15 to 2021-11-30 at Alte Mälzerei</text>")
+	assert.NotContains(t, string(content), ">DHBW Mosbach</text>")
+
+	_, err = createChart(journals, "pie", from, to, "", 30*time.Minute, path.Join(dir, "pie.png"))
+	assert.Error(t, err)
+	_, err = createChart(journals, "dwell", from, to, "", 0, path.Join(dir, "dwell.png"))
+	assert.Error(t, err)
+	_, err = createChart(journals, "occupancy", from, to, "", 30*time.Minute, path.Join(dir, "occupancy.jpg"))
+	assert.Error(t, err)
+}
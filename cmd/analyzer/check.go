@@ -0,0 +1,76 @@
+// This source file is part of the attendance list project
+// as a part of the go lecture by H. Neemann.
+// For this reason you have no permission to use, modify or
+// share this code without the agreement of the authors.
+//
+// Matriculation numbers of the authors: 5703004, 5736465
+
+package main
+
+import (
+	"fmt"
+
+	"github.com/dateiexplorer/attendancelist/internal/journal"
+	"github.com/dateiexplorer/attendancelist/internal/timeutil"
+)
+
+// checkJournals scans all journals for anomalies and returns a report with the
+// count and at most examples Anomalies for every kind.
+//
+// The thresholds map the kinds to the maximum accepted number of Anomalies,
+// a negative or missing value means that there is no limit. The returned bool
+// is true if any threshold is exceeded.
+// If known is not nil, the Locations of all Sessions are checked against it.
+func checkJournals(journals []journal.Journal, known []journal.Location, thresholds map[journal.AnomalyKind]int, examples int) (string, journal.AnomalyList, bool) {
+	anomalies := journal.AnomalyList{}
+	for _, j := range journals {
+		anomalies = append(anomalies, j.CheckAnomalies(known)...)
+	}
+
+	msg := fmt.Sprintf("Checked %v journal files.\n", len(journals))
+	count := anomalies.Count()
+	exceeded := false
+	for _, kind := range journal.AnomalyKinds {
+		if kind == journal.UnknownLocation && known == nil {
+			continue
+		}
+
+		msg += fmt.Sprintf("\n%v: %v", kind, count[kind])
+		if limit, ok := thresholds[kind]; ok && limit >= 0 && count[kind] > limit {
+			msg += fmt.Sprintf(" (limit of %v exceeded)", limit)
+			exceeded = true
+		}
+		msg += "\n"
+
+		shown := 0
+		for _, a := range anomalies {
+			if a.Kind != kind {
+				continue
+			}
+
+			if shown == examples {
+				msg += fmt.Sprintf("  ... and %v more\n", count[kind]-shown)
+				break
+			}
+
+			msg += fmt.Sprintf("  %v %v %v %v\n", a.Date, a.Session.Location, a.Session.Person.String(), formatSessionTimes(a.Session))
+			shown++
+		}
+	}
+
+	return msg, anomalies, exceeded
+}
+
+// formatSessionTimes returns the login and the logout time of a Session with
+// a question mark for unknown times.
+func formatSessionTimes(s journal.Session) string {
+	login, logout := "?", "?"
+	if s.Login != timeutil.InvalidTimestamp {
+		login = s.Login.Clock()
+	}
+	if s.Logout != timeutil.InvalidTimestamp {
+		logout = s.Logout.Clock()
+	}
+
+	return fmt.Sprintf("%v-%v", login, logout)
+}
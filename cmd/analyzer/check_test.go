@@ -0,0 +1,69 @@
+// This source file is part of the attendance list project
+// as a part of the go lecture by H. Neemann.
+// For this reason you have no permission to use, modify or
+// share this code without the agreement of the authors.
+//
+// Matriculation numbers of the authors: 5703004, 5736465
+
+package main
+
+import (
+	"os"
+	"path"
+	"testing"
+
+	"github.com/dateiexplorer/attendancelist/internal/journal"
+	"github.com/dateiexplorer/attendancelist/internal/timeutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckJournals(t *testing.T) {
+	journals, err := readJournals("testdata", timeutil.NewDate(2021, 10, 15), timeutil.NewDate(2021, 11, 30), readOptions{})
+	assert.NoError(t, err)
+
+	expected := `Checked 2 journal files.
+
+logout without login: 1
+  2021-11-30 DHBW Mosbach Torsten,Test,Teststraße,10,74821,Mosbach ?-14:00:00
+
+concurrent sessions: 0
+
+unterminated session: 4 (limit of 3 exceeded)
+  2021-10-15 Alte Mälzerei Hans,Müller,Feldweg,12,74722,Buchen 13:40:11-?
+  2021-11-30 Alte Mälzerei Hans,Müller,Feldweg,12,74722,Buchen 08:30:00-?
+  ... and 2 more
+`
+
+	thresholds := map[journal.AnomalyKind]int{journal.LogoutWithoutLogin: 1, journal.UnterminatedSession: 3}
+	msg, anomalies, exceeded := checkJournals(journals, nil, thresholds, 2)
+	assert.Equal(t, expected, msg)
+	assert.Len(t, anomalies, 5)
+	assert.True(t, exceeded)
+
+	_, _, exceeded = checkJournals(journals, nil, map[journal.AnomalyKind]int{journal.UnterminatedSession: -1}, 2)
+	assert.False(t, exceeded)
+}
+
+func TestRunCheckWithLocations(t *testing.T) {
+	journals, err := readJournals("testdata", timeutil.NewDate(2021, 10, 15), timeutil.NewDate(2021, 11, 30), readOptions{})
+	assert.NoError(t, err)
+
+	dir := t.TempDir()
+	locationsPath := path.Join(dir, "locations.xml")
+	err = os.WriteFile(locationsPath, []byte("<Locations><Location>DHBW Mosbach</Location></Locations>"), 0644)
+	assert.NoError(t, err)
+
+	thresholds := map[journal.AnomalyKind]int{journal.UnknownLocation: 2}
+	filePath := path.Join(dir, "anomalies.csv")
+	msg, exceeded, err := runCheck(journals, locationsPath, thresholds, 0, filePath, "csv")
+	assert.NoError(t, err)
+	assert.True(t, exceeded)
+	assert.Contains(t, msg, "unknown location: 3 (limit of 2 exceeded)")
+
+	content, err := os.ReadFile(filePath)
+	assert.NoError(t, err)
+	assert.Contains(t, string(content), "2021-10-15,unknown location,848dc86c0b5e62a0,Alte Mälzerei,Otto")
+
+	_, _, err = runCheck(journals, path.Join(dir, "missing.xml"), thresholds, 0, "", "csv")
+	assert.Error(t, err)
+}
@@ -0,0 +1,77 @@
+// This source file is part of the attendance list project
+// as a part of the go lecture by H. Neemann.
+// For this reason you have no permission to use, modify or
+// share this code without the agreement of the authors.
+//
+// Matriculation numbers of the authors: 5703004, 5736465
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/dateiexplorer/attendancelist/internal/journal"
+	"github.com/dateiexplorer/attendancelist/internal/synthetic"
+	"github.com/dateiexplorer/attendancelist/internal/web"
+)
+
+// generateLocations returns the Locations of the XML file at locationsPath or,
+// if the path is empty, count Locations named "Location <number>".
+func generateLocations(locationsPath string, count int) ([]journal.Location, error) {
+	if len(locationsPath) > 0 {
+		locs, err := web.ReadLocationsFromXML(locationsPath)
+		if err != nil {
+			return nil, err
+		}
+
+		return locs.Slice, nil
+	}
+
+	if count <= 0 {
+		return nil, errors.New("the number of locations must be greater than zero")
+	}
+
+	locs := make([]journal.Location, 0, count)
+	for i := 1; i <= count; i++ {
+		locs = append(locs, journal.Location(fmt.Sprintf("Location %v", i)))
+	}
+
+	return locs, nil
+}
+
+// generateJournals writes synthetic journal files as described by the Config c
+// to the dir directory. If contactsPath is set, the known contacts of the
+// generated journals are written to this file.
+func generateJournals(c synthetic.Config, dir string, contactsPath string, format string) (string, error) {
+	// Check the format before anything is written.
+	if _, err := converterFor(format); err != nil {
+		return "", err
+	}
+
+	d, err := synthetic.Generate(c)
+	if err != nil {
+		return "", err
+	}
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("cannot create journal directory: %w", err)
+	}
+
+	if err := d.Write(dir, journal.WriteToJournalFile); err != nil {
+		return "", err
+	}
+
+	msg := fmt.Sprintf("Generated %v visits of %v persons on %v days in %v.\n", len(d.Visits), len(d.Persons), c.Days, dir)
+	if len(contactsPath) == 0 {
+		return msg, nil
+	}
+
+	contacts := d.Contacts()
+	if _, err := writeOutput(contacts, contactsPath, format); err != nil {
+		return "", err
+	}
+
+	return msg + fmt.Sprintf("%v known contacts written to %v.\n", len(contacts), contactsPath), nil
+}
@@ -0,0 +1,113 @@
+// This source file is part of the attendance list project
+// as a part of the go lecture by H. Neemann.
+// For this reason you have no permission to use, modify or
+// share this code without the agreement of the authors.
+//
+// Matriculation numbers of the authors: 5703004, 5736465
+
+package main
+
+import (
+	"os"
+	"path"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dateiexplorer/attendancelist/internal/journal"
+	"github.com/dateiexplorer/attendancelist/internal/synthetic"
+	"github.com/dateiexplorer/attendancelist/internal/timeutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGenerateLocations(t *testing.T) {
+	locs, err := generateLocations("", 2)
+	assert.NoError(t, err)
+	assert.Equal(t, []journal.Location{"Location 1", "Location 2"}, locs)
+
+	locs, err = generateLocations("../../example/locations.xml", 2)
+	assert.NoError(t, err)
+	assert.Contains(t, locs, journal.Location("DHBW Mosbach"))
+
+	_, err = generateLocations("", 0)
+	assert.Error(t, err)
+
+	_, err = generateLocations("testdata/missing.xml", 2)
+	assert.Error(t, err)
+}
+
+func TestGenerateJournals(t *testing.T) {
+	dir := path.Join(t.TempDir(), "data")
+	contactsPath := path.Join(t.TempDir(), "contacts.csv")
+	c := synthetic.Config{
+		People:       20,
+		Locations:    []journal.Location{"DHBW Mosbach", "Alte Mälzerei"},
+		From:         timeutil.NewDate(2021, 10, 15),
+		Days:         2,
+		VisitsPerDay: 2,
+		Arrival:      synthetic.Uniform{Min: 8 * time.Hour, Max: 18 * time.Hour},
+		Dwell:        synthetic.Normal{Mean: time.Hour, StdDev: 20 * time.Minute},
+		Seed:         7,
+	}
+
+	msg, err := generateJournals(c, dir, contactsPath, "csv")
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(msg, "Generated "))
+	assert.Contains(t, msg, "known contacts written to "+contactsPath)
+
+	journals, err := readJournals(dir, c.From, c.From.AddDays(1), readOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(journals))
+
+	content, err := os.ReadFile(contactsPath)
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(string(content), "Person,Contact,Location,Start,End,Duration\n"))
+
+	// Existing journal files are not extended.
+	_, err = generateJournals(c, dir, "", "csv")
+	assert.Error(t, err)
+
+	_, err = generateJournals(c, t.TempDir(), "", "xml")
+	assert.Error(t, err)
+}
+
+func TestReadJournalsConcurrentlyIdentical(t *testing.T) {
+	dir := path.Join(t.TempDir(), "data")
+	c := synthetic.Config{
+		People:       50,
+		Locations:    []journal.Location{"DHBW Mosbach", "Alte Mälzerei", "Mensa"},
+		From:         timeutil.NewDate(2021, 10, 1),
+		Days:         21,
+		VisitsPerDay: 2,
+		Arrival:      synthetic.Normal{Mean: 12 * time.Hour, StdDev: 3 * time.Hour},
+		Dwell:        synthetic.Exponential{Mean: time.Hour},
+		Seed:         3,
+	}
+
+	_, err := generateJournals(c, dir, "", "csv")
+	assert.NoError(t, err)
+
+	from, to := c.From, c.From.AddDays(c.Days-1)
+	expected, err := readJournals(dir, from, to, readOptions{workers: 1})
+	assert.NoError(t, err)
+	assert.Equal(t, c.Days, len(expected))
+
+	expectedVisitors, err := getVisitorsInWindow(expected, "Mensa", "11:00", "14:00")
+	assert.NoError(t, err)
+	assert.NotEmpty(t, expectedVisitors)
+
+	for _, workers := range []int{2, 4, 16} {
+		actual, err := readJournals(dir, from, to, readOptions{workers: workers})
+		assert.NoError(t, err)
+		assert.Equal(t, expected, actual, workers)
+
+		// Dropping the sessions of the other locations doesn't change the
+		// visitors of the location.
+		filtered, err := readJournals(dir, from, to, readOptions{workers: workers, location: "Mensa"})
+		assert.NoError(t, err)
+		visitors, err := getVisitorsInWindow(filtered, "Mensa", "11:00", "14:00")
+		assert.NoError(t, err)
+		assert.Equal(t, expectedVisitors, visitors, workers)
+		assert.Less(t, len(filtered[0].Entries), len(expected[0].Entries))
+	}
+}
@@ -0,0 +1,184 @@
+// This source file is part of the attendance list project
+// as a part of the go lecture by H. Neemann.
+// For this reason you have no permission to use, modify or
+// share this code without the agreement of the authors.
+//
+// Matriculation numbers of the authors: 5703004, 5736465
+
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/dateiexplorer/attendancelist/internal/journal"
+)
+
+// similar reports whether two attributes are equal after normalization or
+// differ only by a typo.
+func similar(a, b string) bool {
+	a, b = normalize(a), normalize(b)
+	if a == b {
+		return true
+	}
+
+	return levenshtein(a, b) <= tolerance(a) && levenshtein(a, b) <= tolerance(b)
+}
+
+// probablyIdentical reports whether the persons a and b are probably the same
+// visitor. This is the case if they live at the same address and their names
+// are similar. The house number and the zip code must be equal after
+// normalization, all other attributes may contain typos.
+func probablyIdentical(a, b journal.Person) bool {
+	return normalize(a.Address.Number) == normalize(b.Address.Number) &&
+		normalize(a.Address.ZipCode) == normalize(b.Address.ZipCode) &&
+		similar(a.Address.Street, b.Address.Street) &&
+		similar(a.Address.City, b.Address.City) &&
+		similar(a.FirstName, b.FirstName) &&
+		similar(a.LastName, b.LastName)
+}
+
+// findIdentityClusters groups all persons of the Journal j which are probably
+// identical. Only groups with more than one person are returned.
+//
+// The first Person of a cluster is the spelling with the most journal entries,
+// which is proposed as the canonical Person. The other persons follow in the
+// order of their first appearance.
+func findIdentityClusters(j journal.Journal) [][]journal.Person {
+	persons := uniquePersons(j)
+	count := make(map[journal.Person]int)
+	for _, e := range j.Entries {
+		count[e.Person]++
+	}
+
+	// Assign every person to the cluster of the first similar person.
+	// Clusters are merged if a person is similar to multiple clusters.
+	cluster := make([]int, len(persons))
+	for i := range persons {
+		cluster[i] = i
+		for k := 0; k < i; k++ {
+			if !probablyIdentical(persons[i], persons[k]) {
+				continue
+			}
+
+			from, to := cluster[i], cluster[k]
+			if from < to {
+				from, to = to, from
+			}
+
+			for n := 0; n <= i; n++ {
+				if cluster[n] == from {
+					cluster[n] = to
+				}
+			}
+		}
+	}
+
+	groups := make(map[int][]journal.Person)
+	order := make([]int, 0)
+	for i, p := range persons {
+		if _, ok := groups[cluster[i]]; !ok {
+			order = append(order, cluster[i])
+		}
+
+		groups[cluster[i]] = append(groups[cluster[i]], p)
+	}
+
+	clusters := make([][]journal.Person, 0)
+	for _, c := range order {
+		group := groups[c]
+		if len(group) < 2 {
+			continue
+		}
+
+		// Move the most frequent spelling to the front.
+		best := 0
+		for i, p := range group {
+			if count[p] > count[group[best]] {
+				best = i
+			}
+		}
+
+		ordered := []journal.Person{group[best]}
+		for i, p := range group {
+			if i != best {
+				ordered = append(ordered, p)
+			}
+		}
+
+		clusters = append(clusters, ordered)
+	}
+
+	return clusters
+}
+
+// printIdentityClusters returns a human readable listing of the clusters.
+func printIdentityClusters(clusters [][]journal.Person) string {
+	if len(clusters) == 0 {
+		return fmt.Sprintln("No probably identical persons found.")
+	}
+
+	msg := ""
+	for i, c := range clusters {
+		msg += fmt.Sprintf("%v) %v\n", i+1, c[0].String())
+		for _, p := range c[1:] {
+			msg += fmt.Sprintf("   = %v\n", p.String())
+		}
+	}
+
+	return msg
+}
+
+// confirmMerges asks the operator for every cluster on w whether the persons
+// should be merged and reads the answers from r. Confirmed clusters are merged
+// into the PersonMapping m.
+//
+// Returns the number of confirmed merges.
+func confirmMerges(r io.Reader, w io.Writer, clusters [][]journal.Person, m journal.PersonMapping) int {
+	scanner := bufio.NewScanner(r)
+	merged := 0
+	for _, c := range clusters {
+		fmt.Fprintf(w, "Merge into %v?\n", c[0].String())
+		for _, p := range c[1:] {
+			fmt.Fprintf(w, "   %v\n", p.String())
+		}
+		fmt.Fprint(w, "[y/N]: ")
+
+		if !scanner.Scan() {
+			break
+		}
+
+		switch strings.ToLower(strings.TrimSpace(scanner.Text())) {
+		case "y", "yes":
+			m.Merge(c[0], c[1:]...)
+			merged++
+		}
+	}
+
+	return merged
+}
+
+func identifyPersons(j journal.Journal, mappingPath string, confirm bool, r io.Reader, w io.Writer) (string, error) {
+	clusters := findIdentityClusters(j)
+	if !confirm || len(clusters) == 0 {
+		return printIdentityClusters(clusters), nil
+	}
+
+	m, err := journal.ReadPersonMappingIfExists(mappingPath)
+	if err != nil {
+		return "", err
+	}
+
+	merged := confirmMerges(r, w, clusters, m)
+	if merged == 0 {
+		return fmt.Sprintln("No merges confirmed."), nil
+	}
+
+	if err := m.WriteToFile(mappingPath); err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("%v merges written to %v.\n", merged, mappingPath), nil
+}
@@ -0,0 +1,96 @@
+// This source file is part of the attendance list project
+// as a part of the go lecture by H. Neemann.
+// For this reason you have no permission to use, modify or
+// share this code without the agreement of the authors.
+//
+// Matriculation numbers of the authors: 5703004, 5736465
+
+package main
+
+import (
+	"bytes"
+	"path"
+	"strings"
+	"testing"
+
+	"github.com/dateiexplorer/attendancelist/internal/journal"
+	"github.com/dateiexplorer/attendancelist/internal/timeutil"
+	"github.com/stretchr/testify/assert"
+)
+
+var hans = journal.NewPerson("Hans", "Müller", "Feldweg", "12", "74722", "Buchen")
+var hansTypo = journal.NewPerson("hans ", "Mueler", "Feldweg", "12", "74722", "Buchen")
+var lieschen = journal.NewPerson("Lieschen", "Müller", "Lindenstraße", "15", "10115", "Berlin")
+
+// typoJournal returns a journal in which Hans Müller visits two locations with
+// different spellings of his name.
+func typoJournal() journal.Journal {
+	return journal.Journal{Date: timeutil.NewDate(2021, 10, 15), Entries: []journal.JournalEntry{
+		journal.NewJournalEntry(timeutil.NewTimestamp(2021, 10, 15, 6, 20, 13), "d61ec70b78628e15", journal.Login, "DHBW Mosbach", hansTypo),
+		journal.NewJournalEntry(timeutil.NewTimestamp(2021, 10, 15, 9, 0, 0), "68e7faee906ffd4c", journal.Login, "DHBW Mosbach", lieschen),
+		journal.NewJournalEntry(timeutil.NewTimestamp(2021, 10, 15, 13, 40, 10), "d61ec70b78628e15", journal.Logout, "DHBW Mosbach", hansTypo),
+		journal.NewJournalEntry(timeutil.NewTimestamp(2021, 10, 15, 13, 40, 11), "5faacdf0e6e7b44a", journal.Login, "Alte Mälzerei", hans),
+		journal.NewJournalEntry(timeutil.NewTimestamp(2021, 10, 15, 15, 0, 0), "5faacdf0e6e7b44a", journal.Logout, "Alte Mälzerei", hans),
+		journal.NewJournalEntry(timeutil.NewTimestamp(2021, 10, 15, 15, 42, 23), "68e7faee906ffd4c", journal.Logout, "DHBW Mosbach", lieschen),
+		journal.NewJournalEntry(timeutil.NewTimestamp(2021, 10, 15, 16, 0, 0), "aaaaaaaaaaaaaaaa", journal.Login, "DHBW Mosbach", hans),
+	}}
+}
+
+func TestProbablyIdentical(t *testing.T) {
+	assert.True(t, probablyIdentical(hans, hansTypo))
+	assert.False(t, probablyIdentical(hans, lieschen))
+
+	// Same name in another house is another person.
+	other := journal.NewPerson("Hans", "Müller", "Feldweg", "14", "74722", "Buchen")
+	assert.False(t, probablyIdentical(hans, other))
+}
+
+func TestFindIdentityClusters(t *testing.T) {
+	expected := [][]journal.Person{{hans, hansTypo}}
+	actual := findIdentityClusters(typoJournal())
+	assert.Equal(t, expected, actual)
+}
+
+func TestFindIdentityClustersNoDuplicates(t *testing.T) {
+	j, err := journal.ReadJournal("testdata", timeutil.NewDate(2021, 10, 15))
+	assert.NoError(t, err)
+
+	assert.Empty(t, findIdentityClusters(j))
+	assert.Equal(t, "No probably identical persons found.\n", printIdentityClusters(nil))
+}
+
+func TestConfirmMerges(t *testing.T) {
+	m := journal.PersonMapping{}
+	out := new(bytes.Buffer)
+	merged := confirmMerges(strings.NewReader("y\n"), out, [][]journal.Person{{hans, hansTypo}}, m)
+
+	assert.Equal(t, 1, merged)
+	assert.Equal(t, hans, m.Resolve(hansTypo))
+	assert.Contains(t, out.String(), hansTypo.String())
+
+	m = journal.PersonMapping{}
+	merged = confirmMerges(strings.NewReader("\n"), out, [][]journal.Person{{hans, hansTypo}}, m)
+	assert.Equal(t, 0, merged)
+	assert.Empty(t, m)
+}
+
+func TestIdentifyPersonsAppliesMapping(t *testing.T) {
+	dir := t.TempDir()
+	for _, e := range typoJournal().Entries {
+		assert.NoError(t, journal.WriteToJournalFile(dir, &e))
+	}
+
+	mappingPath := path.Join(dir, journal.MappingFileName)
+	msg, err := identifyPersons(typoJournal(), mappingPath, true, strings.NewReader("yes\n"), new(bytes.Buffer))
+	assert.NoError(t, err)
+	assert.Contains(t, msg, "1 merges written")
+
+	j, err := readJournal(dir, timeutil.NewDate(2021, 10, 15), readOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, []journal.Person{hans, lieschen}, uniquePersons(j))
+
+	msg, err = printVisitedLocationsForPerson(j, "Hans")
+	assert.NoError(t, err)
+	assert.Contains(t, msg, "DHBW Mosbach")
+	assert.Contains(t, msg, "Alte Mälzerei")
+}
@@ -0,0 +1,120 @@
+// This source file is part of the attendance list project
+// as a part of the go lecture by H. Neemann.
+// For this reason you have no permission to use, modify or
+// share this code without the agreement of the authors.
+//
+// Matriculation numbers of the authors: 5703004, 5736465
+
+package main
+
+import (
+	"crypto"
+	"fmt"
+	"io/ioutil"
+	"path"
+	"strings"
+
+	"github.com/dateiexplorer/attendancelist/internal/journal"
+	"github.com/dateiexplorer/attendancelist/internal/shamir"
+)
+
+// splitKey splits the private key in the file at keyPath into n shares, where
+// k shares are required to recover the key. The shares are written to the files
+// "share-<number>.pem" in the dir directory.
+func splitKey(keyPath string, n int, k int, dir string) (string, error) {
+	data, err := ioutil.ReadFile(keyPath)
+	if err != nil {
+		return "", fmt.Errorf("cannot read key file: %w", err)
+	}
+
+	// Only split valid keys, otherwise the error shows up after combining.
+	if _, err := journal.ParsePrivateKey(data); err != nil {
+		return "", err
+	}
+
+	shares, err := shamir.Split(data, n, k)
+	if err != nil {
+		return "", err
+	}
+
+	msg := fmt.Sprintf("Key split into %v shares, %v of them are required to recover the key:\n", n, k)
+	for _, s := range shares {
+		filePath := path.Join(dir, fmt.Sprintf("share-%v.pem", s.X))
+		if err := ioutil.WriteFile(filePath, s.MarshalPEM(), 0600); err != nil {
+			return "", fmt.Errorf("cannot write share: %w", err)
+		}
+
+		msg += fmt.Sprintln("  " + filePath)
+	}
+
+	return msg, nil
+}
+
+// combineShares recovers the private key from the share files.
+func combineShares(sharePaths []string) ([]byte, error) {
+	shares := make([]shamir.Share, 0, len(sharePaths))
+	for _, p := range sharePaths {
+		data, err := ioutil.ReadFile(p)
+		if err != nil {
+			return nil, fmt.Errorf("cannot read share: %w", err)
+		}
+
+		s, err := shamir.ParsePEM(data)
+		if err != nil {
+			return nil, fmt.Errorf("%v: %w", p, err)
+		}
+
+		shares = append(shares, s)
+	}
+
+	data, err := shamir.Combine(shares)
+	if err != nil {
+		return nil, err
+	}
+
+	// Wrong shares of the right size produce garbage.
+	if _, err := journal.ParsePrivateKey(data); err != nil {
+		return nil, fmt.Errorf("shares don't recover a valid key: %w", err)
+	}
+
+	return data, nil
+}
+
+// combineKey recovers the private key from the share files and writes it to
+// filePath. If no file path set, the key is written to the console.
+func combineKey(sharePaths []string, filePath string) (string, error) {
+	data, err := combineShares(sharePaths)
+	if err != nil {
+		return "", err
+	}
+
+	if len(filePath) == 0 {
+		return string(data), nil
+	}
+
+	if err := ioutil.WriteFile(filePath, data, 0600); err != nil {
+		return "", fmt.Errorf("cannot write key file: %w", err)
+	}
+
+	return fmt.Sprintln("Key successfully recovered."), nil
+}
+
+// loadPrivateKey loads the private key to decrypt the journal files from the
+// file at keyPath or, if keyPath is empty, recovers it from the comma-separated
+// share files. Returns nil if both are empty.
+func loadPrivateKey(keyPath string, sharePaths string) (crypto.PrivateKey, error) {
+	if len(keyPath) > 0 {
+		return journal.ReadPrivateKey(keyPath)
+	}
+
+	if len(sharePaths) == 0 {
+		return nil, nil
+	}
+
+	data, err := combineShares(strings.Split(sharePaths, ","))
+	if err != nil {
+		return nil, err
+	}
+
+	return journal.ParsePrivateKey(data)
+}
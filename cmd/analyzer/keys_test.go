@@ -0,0 +1,100 @@
+// This source file is part of the attendance list project
+// as a part of the go lecture by H. Neemann.
+// For this reason you have no permission to use, modify or
+// share this code without the agreement of the authors.
+//
+// Matriculation numbers of the authors: 5703004, 5736465
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"strings"
+	"testing"
+
+	"github.com/dateiexplorer/attendancelist/internal/journal"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSplitAndCombineKey(t *testing.T) {
+	dir := t.TempDir()
+	msg, err := splitKey("testdata/key.pem", 5, 2, dir)
+	assert.NoError(t, err)
+	assert.Contains(t, msg, "Key split into 5 shares, 2 of them are required to recover the key:\n")
+	assert.Contains(t, msg, path.Join(dir, "share-5.pem"))
+
+	expected, err := os.ReadFile("testdata/key.pem")
+	assert.NoError(t, err)
+
+	// Recover the key to the console.
+	msg, err = combineKey([]string{path.Join(dir, "share-4.pem"), path.Join(dir, "share-2.pem")}, "")
+	assert.NoError(t, err)
+	assert.Equal(t, string(expected), msg)
+
+	// Recover the key to a file.
+	filePath := path.Join(dir, "key.pem")
+	msg, err = combineKey([]string{path.Join(dir, "share-1.pem"), path.Join(dir, "share-3.pem")}, filePath)
+	assert.NoError(t, err)
+	assert.Equal(t, fmt.Sprintln("Key successfully recovered."), msg)
+
+	actual, err := os.ReadFile(filePath)
+	assert.NoError(t, err)
+	assert.Equal(t, expected, actual)
+}
+
+func TestCombineKeyFailed(t *testing.T) {
+	dir := t.TempDir()
+	_, err := splitKey("testdata/key.pem", 3, 2, dir)
+	assert.NoError(t, err)
+
+	// Not enough shares
+	_, err = combineKey([]string{path.Join(dir, "share-1.pem")}, "")
+	assert.Error(t, err)
+
+	// Missing share
+	_, err = combineKey([]string{path.Join(dir, "share-1.pem"), path.Join(dir, "share-9.pem")}, "")
+	assert.Error(t, err)
+
+	// No share
+	_, err = combineKey([]string{path.Join(dir, "share-1.pem"), "testdata/key.pem"}, "")
+	assert.Error(t, err)
+}
+
+func TestSplitKeyFailed(t *testing.T) {
+	_, err := splitKey("testdata/missing.pem", 5, 2, t.TempDir())
+	assert.Error(t, err)
+
+	// A certificate is no private key.
+	_, err = splitKey("testdata/cert.pem", 5, 2, t.TempDir())
+	assert.Error(t, err)
+
+	_, err = splitKey("testdata/key.pem", 2, 5, t.TempDir())
+	assert.Error(t, err)
+}
+
+func TestLoadPrivateKey(t *testing.T) {
+	priv, err := loadPrivateKey("", "")
+	assert.NoError(t, err)
+	assert.Nil(t, priv)
+
+	expected, err := journal.ReadPrivateKey("testdata/key.pem")
+	assert.NoError(t, err)
+
+	priv, err = loadPrivateKey("testdata/key.pem", "")
+	assert.NoError(t, err)
+	assert.Equal(t, expected, priv)
+
+	dir := t.TempDir()
+	_, err = splitKey("testdata/key.pem", 5, 3, dir)
+	assert.NoError(t, err)
+
+	shares := []string{path.Join(dir, "share-1.pem"), path.Join(dir, "share-2.pem"), path.Join(dir, "share-5.pem")}
+	priv, err = loadPrivateKey("", strings.Join(shares, ","))
+	assert.NoError(t, err)
+	assert.Equal(t, expected, priv)
+
+	_, err = loadPrivateKey("", strings.Join(shares[:2], ","))
+	assert.Error(t, err)
+}
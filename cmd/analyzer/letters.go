@@ -0,0 +1,270 @@
+// This source file is part of the attendance list project
+// as a part of the go lecture by H. Neemann.
+// For this reason you have no permission to use, modify or
+// share this code without the agreement of the authors.
+//
+// Matriculation numbers of the authors: 5703004, 5736465
+
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	htmltemplate "html/template"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	texttemplate "text/template"
+	"time"
+	"unicode"
+
+	"github.com/dateiexplorer/attendancelist/internal/journal"
+	"github.com/dateiexplorer/attendancelist/internal/timeutil"
+)
+
+// A letterContact is a contact of a letter formatted for the template.
+type letterContact struct {
+	Location string
+	Date     string
+	Start    string
+	End      string
+	Duration string
+}
+
+// A letter holds the data of the notification letter to a contact person.
+//
+// The fields of the embedded letterContact are those of the first contact, so
+// templates for persons with only one contact don't need to loop through the
+// Contacts.
+type letter struct {
+	FirstName string
+	LastName  string
+	Street    string
+	Number    string
+	ZipCode   string
+	City      string
+	letterContact
+	Contacts []letterContact
+	Today    string
+}
+
+// newLetters returns a letter for every person of the contacts, ordered by
+// last and first name. The contacts of a person are ordered by their start.
+func newLetters(contacts journal.ContactList, today timeutil.Date) []letter {
+	sorted := make(journal.ContactList, len(contacts))
+	copy(sorted, contacts)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Start.Before(sorted[j].Start.Time)
+	})
+
+	letters := make([]letter, 0)
+	index := make(map[journal.Person]int)
+	for _, c := range sorted {
+		lc := letterContact{
+			Location: string(c.Location),
+			Date:     c.Start.Date().String(),
+			Start:    c.Start.Format("15:04"),
+			End:      c.End.Format("15:04"),
+			Duration: formatOverlap(c.Duration),
+		}
+
+		i, ok := index[c.Person]
+		if !ok {
+			p := c.Person
+			i = len(letters)
+			index[p] = i
+			letters = append(letters, letter{p.FirstName, p.LastName, p.Address.Street, p.Address.Number, p.Address.ZipCode, p.Address.City,
+				lc, nil, today.String()})
+		}
+
+		letters[i].Contacts = append(letters[i].Contacts, lc)
+	}
+
+	sort.SliceStable(letters, func(i, j int) bool {
+		a, b := letters[i], letters[j]
+		if a.LastName != b.LastName {
+			return a.LastName < b.LastName
+		}
+
+		return a.FirstName < b.FirstName
+	})
+
+	return letters
+}
+
+// formatOverlap returns the duration d rounded to minutes, e.g. "1h30m".
+func formatOverlap(d time.Duration) string {
+	s := d.Round(time.Minute).String()
+	if strings.HasSuffix(s, "m0s") {
+		s = strings.TrimSuffix(s, "0s")
+	}
+
+	return s
+}
+
+// A letterTemplate renders letters with a text/template or html/template.
+type letterTemplate struct {
+	template interface {
+		Execute(w io.Writer, data interface{}) error
+	}
+	// html is true for an html/template, whose output is already escaped.
+	html bool
+	// ext is the file extension of the template, used for the letter files.
+	ext string
+}
+
+// readLetterTemplate reads the template at filePath. Files ending with .html or
+// .htm are parsed as html/template, all others as text/template.
+func readLetterTemplate(filePath string) (letterTemplate, error) {
+	data, err := ioutil.ReadFile(filePath)
+	if err != nil {
+		return letterTemplate{}, fmt.Errorf("cannot read template: %w", err)
+	}
+
+	ext := strings.ToLower(filepath.Ext(filePath))
+	if ext == ".html" || ext == ".htm" {
+		t, err := htmltemplate.New(filepath.Base(filePath)).Option("missingkey=error").Parse(string(data))
+		if err != nil {
+			return letterTemplate{}, fmt.Errorf("cannot parse template: %w", err)
+		}
+
+		return letterTemplate{t, true, ext}, nil
+	}
+
+	t, err := texttemplate.New(filepath.Base(filePath)).Option("missingkey=error").Parse(string(data))
+	if err != nil {
+		return letterTemplate{}, fmt.Errorf("cannot parse template: %w", err)
+	}
+
+	if len(ext) == 0 {
+		ext = ".txt"
+	}
+
+	return letterTemplate{t, false, ext}, nil
+}
+
+// render returns the letter l rendered with the template.
+func (t letterTemplate) render(l letter) ([]byte, error) {
+	var b bytes.Buffer
+	if err := t.template.Execute(&b, l); err != nil {
+		return nil, fmt.Errorf("cannot render letter to %v %v: %w", l.FirstName, l.LastName, err)
+	}
+
+	return b.Bytes(), nil
+}
+
+// letterFileName returns the name of the file of the i-th letter l with the
+// extension ext. Characters other than letters and digits are replaced.
+func letterFileName(i int, l letter, ext string) string {
+	name := strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return r
+		}
+
+		return '_'
+	}, l.LastName+"_"+l.FirstName)
+
+	return fmt.Sprintf("%03d-%v%v", i+1, name, ext)
+}
+
+// The frame of the combined HTML file, every letter starts on a new page.
+const combinedHeader = `<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Letters</title>
+<style>
+.letter { page-break-after: always; }
+.letter:last-child { page-break-after: auto; }
+pre { font-family: inherit; white-space: pre-wrap; }
+</style>
+</head>
+<body>
+`
+
+const combinedFooter = `</body>
+</html>
+`
+
+// createLetters renders a letter with the template at templatePath for every
+// contact person of the person in the journals. The letters are written to one
+// file per letter in the dir directory, which must be empty or not exist, or,
+// if combinedPath is set, to one printable HTML file. The letters of a text template are escaped and kept
+// preformatted in the HTML file.
+func createLetters(journals []journal.Journal, person string, templatePath string, dir string, combinedPath string, today timeutil.Date) (string, error) {
+	t, err := readLetterTemplate(templatePath)
+	if err != nil {
+		return "", err
+	}
+
+	p, err := selectPersonFromJournals(journals, person)
+	if err != nil {
+		return "", err
+	}
+
+	contacts := journal.ContactList{}
+	for _, j := range journals {
+		contacts = append(contacts, j.GetContactsForPerson(&p)...)
+	}
+
+	letters := newLetters(contacts, today)
+	rendered := make([][]byte, 0, len(letters))
+	for _, l := range letters {
+		r, err := t.render(l)
+		if err != nil {
+			return "", err
+		}
+
+		rendered = append(rendered, r)
+	}
+
+	if len(combinedPath) > 0 {
+		var b bytes.Buffer
+		b.WriteString(combinedHeader)
+		for _, r := range rendered {
+			b.WriteString("<div class=\"letter\">\n")
+			if t.html {
+				b.Write(r)
+			} else {
+				b.WriteString("<pre>" + htmltemplate.HTMLEscapeString(string(r)) + "</pre>")
+			}
+
+			b.WriteString("\n</div>\n")
+		}
+
+		b.WriteString(combinedFooter)
+		if err := ioutil.WriteFile(combinedPath, b.Bytes(), 0600); err != nil {
+			return "", fmt.Errorf("cannot write letters: %w", err)
+		}
+
+		return fmt.Sprintf("%v letters to the contacts of %v written to %v.\n", len(letters), p.String(), combinedPath), nil
+	}
+
+	// The file names only count the letters, so letters of an earlier run
+	// would be mixed up with the new ones.
+	files, err := ioutil.ReadDir(dir)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return "", fmt.Errorf("cannot read directory for letters: %w", err)
+	}
+
+	if len(files) > 0 {
+		return "", fmt.Errorf("cannot write letters: directory %v is not empty", dir)
+	}
+
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return "", fmt.Errorf("cannot create directory for letters: %w", err)
+	}
+
+	for i, r := range rendered {
+		if err := ioutil.WriteFile(path.Join(dir, letterFileName(i, letters[i], t.ext)), r, 0600); err != nil {
+			return "", fmt.Errorf("cannot write letters: %w", err)
+		}
+	}
+
+	return fmt.Sprintf("%v letters to the contacts of %v written to %v.\n", len(letters), p.String(), dir), nil
+}
@@ -0,0 +1,97 @@
+// This source file is part of the attendance list project
+// as a part of the go lecture by H. Neemann.
+// For this reason you have no permission to use, modify or
+// share this code without the agreement of the authors.
+//
+// Matriculation numbers of the authors: 5703004, 5736465
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"testing"
+	"time"
+
+	"github.com/dateiexplorer/attendancelist/internal/journal"
+	"github.com/dateiexplorer/attendancelist/internal/timeutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewLetters(t *testing.T) {
+	anne := journal.NewPerson("Anne", "Meier", "Hauptstraße", "18", "74821", "Mosbach")
+	max := journal.NewPerson("Max", "Mustermann", "Musterstraße", "20", "74821", "Mosbach")
+	contacts := journal.ContactList{
+		{Person: max, Location: "DHBW Mosbach", Start: timeutil.NewTimestamp(2021, 10, 15, 12, 15, 30), End: timeutil.NewTimestamp(2021, 10, 15, 13, 40, 11), Duration: 84*time.Minute + 41*time.Second},
+		{Person: anne, Location: "Alte Mälzerei", Start: timeutil.NewTimestamp(2021, 11, 30, 8, 30, 0), End: timeutil.NewTimestamp(2021, 11, 30, 9, 0, 0), Duration: 30 * time.Minute},
+		{Person: anne, Location: "DHBW Mosbach", Start: timeutil.NewTimestamp(2021, 10, 15, 12, 17, 20), End: timeutil.NewTimestamp(2021, 10, 15, 13, 40, 11), Duration: 82*time.Minute + 51*time.Second},
+	}
+
+	letters := newLetters(contacts, timeutil.NewDate(2021, 12, 1))
+	assert.Len(t, letters, 2)
+	assert.Equal(t, "Meier", letters[0].LastName)
+	assert.Equal(t, "Mustermann", letters[1].LastName)
+	assert.Equal(t, letterContact{"DHBW Mosbach", "2021-10-15", "12:17", "13:40", "1h23m"}, letters[0].letterContact)
+	assert.Equal(t, []letterContact{
+		{"DHBW Mosbach", "2021-10-15", "12:17", "13:40", "1h23m"},
+		{"Alte Mälzerei", "2021-11-30", "08:30", "09:00", "30m"},
+	}, letters[0].Contacts)
+	assert.Equal(t, "Hauptstraße", letters[0].Street)
+	assert.Equal(t, "2021-12-01", letters[0].Today)
+}
+
+func TestCreateLetters(t *testing.T) {
+	from, to := timeutil.NewDate(2021, 10, 15), timeutil.NewDate(2021, 11, 30)
+	journals, err := readJournals("testdata", from, to, readOptions{})
+	assert.NoError(t, err)
+
+	dir := t.TempDir()
+	textPath := path.Join(dir, "letter.txt")
+	assert.NoError(t, os.WriteFile(textPath, []byte("Dear {{.FirstName}} {{.LastName}} <{{.City}}>,\n{{range .Contacts}}{{.Location}} {{.Date}}\n{{end}}"), 0600))
+	htmlPath := path.Join(dir, "letter.html")
+	assert.NoError(t, os.WriteFile(htmlPath, []byte("<p>Dear {{.FirstName}} {{.LastName}}, {{.Location}}</p>"), 0600))
+
+	today := timeutil.NewDate(2021, 12, 1)
+	outDir := path.Join(dir, "letters")
+	msg, err := createLetters(journals, "Hans,Müller", textPath, outDir, "", today)
+	assert.NoError(t, err)
+	assert.Equal(t, fmt.Sprintf("5 letters to the contacts of Hans,Müller,Feldweg,12,74722,Buchen written to %v.\n", outDir), msg)
+
+	content, err := os.ReadFile(path.Join(outDir, "001-Meier_Anne.txt"))
+	assert.NoError(t, err)
+	assert.Equal(t, "Dear Anne Meier <Mosbach>,\nDHBW Mosbach 2021-10-15\n", string(content))
+
+	// A second run doesn't mix its letters with the letters of the first one.
+	_, err = createLetters(journals, "Hans,Müller", textPath, outDir, "", today)
+	assert.Error(t, err)
+	files, err := os.ReadDir(outDir)
+	assert.NoError(t, err)
+	assert.Len(t, files, 5)
+
+	// Text letters are escaped in the combined file.
+	combined := path.Join(dir, "letters.html")
+	_, err = createLetters(journals, "Hans,Müller", textPath, outDir, combined, today)
+	assert.NoError(t, err)
+	content, err = os.ReadFile(combined)
+	assert.NoError(t, err)
+	assert.Contains(t, string(content), "<div class=\"letter\">\n<pre>Dear Anne Meier &lt;Mosbach&gt;,\n")
+
+	_, err = createLetters(journals, "Hans,Müller", htmlPath, outDir, combined, today)
+	assert.NoError(t, err)
+	content, err = os.ReadFile(combined)
+	assert.NoError(t, err)
+	assert.Contains(t, string(content), "<div class=\"letter\">\n<p>Dear Otto Normalverbraucher, Alte Mälzerei</p>\n</div>")
+
+	// Unknown fields are reported instead of rendered as empty text.
+	assert.NoError(t, os.WriteFile(textPath, []byte("{{.Phone}}"), 0600))
+	_, err = createLetters(journals, "Hans,Müller", textPath, outDir, "", today)
+	assert.Error(t, err)
+
+	assert.NoError(t, os.WriteFile(textPath, []byte("{{.FirstName"), 0600))
+	_, err = createLetters(journals, "Hans,Müller", textPath, outDir, "", today)
+	assert.Error(t, err)
+
+	_, err = createLetters(journals, "Hans,Müller", path.Join(dir, "missing.txt"), outDir, "", today)
+	assert.Error(t, err)
+}
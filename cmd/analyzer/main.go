@@ -8,19 +8,42 @@
 package main
 
 import (
+	"crypto"
 	"flag"
 	"fmt"
 	"io"
+	"log"
 	"os"
+	"path"
+	"runtime"
 	"strings"
+	"time"
 
 	"github.com/dateiexplorer/attendancelist/internal/convert"
 	"github.com/dateiexplorer/attendancelist/internal/journal"
+	"github.com/dateiexplorer/attendancelist/internal/schedule"
+	"github.com/dateiexplorer/attendancelist/internal/synthetic"
 	"github.com/dateiexplorer/attendancelist/internal/timeutil"
+	"github.com/dateiexplorer/attendancelist/internal/web"
 )
 
+// The directory where the journal files are stored.
+const journalDir = "data"
+
 func main() {
-	var person, location, filePath string
+	var person, location, filePath, format, fromClock, toClock string
+	var locationsPath, schedulePath, rosterPath, certFile, keyFile, privateKeyPath, keySharesPath, shareDir string
+	var generateDir, contactsPath, arrival, dwell, tokensPath, logPath, casesPath, summaryPath, summaryKeyPath string
+	var chartType, templatePath, combinedPath string
+	// The commands with a default filename need their own variable, because
+	// every flag definition sets its variable to the default.
+	var reportPath, chartPath, lettersDir, dbPath string
+	var confirm, timeline, lectures, idsOnly, dryRun, showOccupancy, history, newKey bool
+	var minOverlap, resolution, interval, bin time.Duration
+	var maxNoLogin, maxConcurrent, maxNoLogout, maxUnknownLocation, examples, shareCount, threshold, k, zipDigits int
+	var people, locationCount, port, daysBefore, daysAfter, workers int
+	var visitsPerDay float64
+	var seed int64
 
 	// Subcommands
 	locationsCommand := flag.NewFlagSet("locations", flag.ExitOnError)
@@ -29,10 +52,191 @@ func main() {
 	contactsCommand := flag.NewFlagSet("contacts", flag.ExitOnError)
 	contactsCommand.StringVar(&person, "person", "", "person for whom the locations are determined")
 	contactsCommand.StringVar(&filePath, "w", "", "filename")
+	contactsCommand.StringVar(&format, "format", "csv", "output format, csv or json")
+	contactsCommand.StringVar(&casesPath, "cases", "", "the case list `path`, a CSV file with caseID,firstName,lastName,onset, traces all cases within a date range")
+	contactsCommand.IntVar(&daysBefore, "days-before", 2, "number of days before the onset which are traced for every case")
+	contactsCommand.IntVar(&daysAfter, "days-after", 10, "number of days after the onset which are traced for every case")
+	contactsCommand.StringVar(&summaryPath, "summary", "", "filename for the persons exposed to several cases")
 
 	attendancesCommand := flag.NewFlagSet("attendances", flag.ExitOnError)
 	attendancesCommand.StringVar(&location, "location", "", "location for which an attendance list is created")
 	attendancesCommand.StringVar(&filePath, "w", "", "filename")
+	attendancesCommand.StringVar(&format, "format", "csv", "output format, csv or json")
+
+	occupancyCommand := flag.NewFlagSet("occupancy", flag.ExitOnError)
+	occupancyCommand.StringVar(&location, "location", "", "location for which the occupancy is computed, all locations if empty")
+	occupancyCommand.BoolVar(&timeline, "timeline", false, "print the number of visitors over time instead of the statistics")
+	occupancyCommand.StringVar(&filePath, "w", "", "filename")
+	occupancyCommand.StringVar(&format, "format", "csv", "output format, csv or json")
+
+	identitiesCommand := flag.NewFlagSet("identities", flag.ExitOnError)
+	identitiesCommand.BoolVar(&confirm, "confirm", false, "ask for every group of persons whether they should be merged")
+
+	atCommand := flag.NewFlagSet("at", flag.ExitOnError)
+	atCommand.StringVar(&location, "location", "", "location for which the present persons are determined, all locations if empty")
+	atCommand.StringVar(&filePath, "w", "", "filename")
+	atCommand.StringVar(&format, "format", "csv", "output format, csv or json")
+
+	outbreakCommand := flag.NewFlagSet("outbreak", flag.ExitOnError)
+	outbreakCommand.StringVar(&location, "location", "", "location which is the source of the outbreak")
+	outbreakCommand.StringVar(&fromClock, "from", "00:00", "begin of the time window on each day, hh:mm[:ss]")
+	outbreakCommand.StringVar(&toClock, "to", "23:59:59", "end of the time window on each day, hh:mm[:ss]")
+	outbreakCommand.StringVar(&filePath, "w", "", "filename")
+	outbreakCommand.StringVar(&format, "format", "csv", "output format, csv or json")
+
+	timelineCommand := flag.NewFlagSet("timeline", flag.ExitOnError)
+	timelineCommand.StringVar(&person, "person", "", "person for whom the visits are listed")
+	timelineCommand.StringVar(&filePath, "w", "", "filename")
+	timelineCommand.StringVar(&format, "format", "csv", "output format, csv, json or ics")
+
+	checkCommand := flag.NewFlagSet("check", flag.ExitOnError)
+	checkCommand.StringVar(&locationsPath, "locations", "", "the locations XML file `path` to check the locations against")
+	checkCommand.IntVar(&maxNoLogin, "max-no-login", -1, "maximum number of logouts without login, no limit if negative")
+	checkCommand.IntVar(&maxConcurrent, "max-concurrent", -1, "maximum number of concurrent sessions of one person, no limit if negative")
+	checkCommand.IntVar(&maxNoLogout, "max-no-logout", -1, "maximum number of sessions which never end, no limit if negative")
+	checkCommand.IntVar(&maxUnknownLocation, "max-unknown-location", -1, "maximum number of sessions at unknown locations, no limit if negative")
+	checkCommand.IntVar(&examples, "examples", 3, "number of examples printed for every kind of anomaly")
+	checkCommand.StringVar(&filePath, "w", "", "filename for a list of all anomalies")
+	checkCommand.StringVar(&format, "format", "csv", "output format, csv or json")
+
+	coursesCommand := flag.NewFlagSet("courses", flag.ExitOnError)
+	coursesCommand.StringVar(&schedulePath, "schedule", "", "the lecture schedule `path`, a CSV or iCalendar file")
+	coursesCommand.BoolVar(&lectures, "lectures", false, "print the attendances of every lecture instead of the percentage per course")
+	coursesCommand.DurationVar(&minOverlap, "min-overlap", 0, "minimum time a person must attend a lecture to be counted, e.g. 30m")
+	coursesCommand.StringVar(&filePath, "w", "", "filename")
+	coursesCommand.StringVar(&format, "format", "csv", "output format, csv or json")
+
+	reconcileCommand := flag.NewFlagSet("reconcile", flag.ExitOnError)
+	reconcileCommand.StringVar(&rosterPath, "roster", "", "the roster `path`, a CSV file with firstName,lastName[,studentID]")
+	reconcileCommand.StringVar(&location, "location", "", "location where the participants are expected")
+	reconcileCommand.StringVar(&fromClock, "from", "00:00", "begin of the time window on each day, hh:mm[:ss]")
+	reconcileCommand.StringVar(&toClock, "to", "23:59:59", "end of the time window on each day, hh:mm[:ss]")
+	reconcileCommand.StringVar(&filePath, "w", "", "filename")
+	reconcileCommand.StringVar(&format, "format", "csv", "output format, csv or json")
+
+	reportCommand := flag.NewFlagSet("report", flag.ExitOnError)
+	reportCommand.StringVar(&person, "person", "", "the index case for whom the report is created")
+	reportCommand.StringVar(&certFile, "cert", "", "the certificate `path` of the key pair which signs the report")
+	reportCommand.StringVar(&keyFile, "key", "", "the private key `path` of the key pair which signs the report")
+	reportCommand.StringVar(&reportPath, "w", "report.zip", "filename")
+	reportCommand.StringVar(&format, "format", "csv", "format of the lists in the report, csv or json")
+
+	anonymizeCommand := flag.NewFlagSet("anonymize", flag.ExitOnError)
+	anonymizeCommand.IntVar(&k, "k", 5, "minimum number of different persons whose visits share the same zip code region, location and time slots")
+	anonymizeCommand.IntVar(&zipDigits, "zip-digits", 2, "number of leading digits of the zip code which are kept")
+	anonymizeCommand.DurationVar(&resolution, "resolution", time.Hour, "length of the time slots the begin and the end of a visit are coarsened to")
+	anonymizeCommand.StringVar(&filePath, "w", "", "filename")
+	anonymizeCommand.StringVar(&format, "format", "csv", "output format, csv or json")
+
+	generateCommand := flag.NewFlagSet("generate", flag.ExitOnError)
+	generateCommand.IntVar(&people, "people", 100, "number of persons")
+	generateCommand.StringVar(&locationsPath, "locations", "", "the locations XML file `path`, -location-count generic locations if empty")
+	generateCommand.IntVar(&locationCount, "location-count", 5, "number of generic locations if no locations file is given")
+	generateCommand.Float64Var(&visitsPerDay, "visits", 1.5, "average number of visits per person and day")
+	generateCommand.StringVar(&arrival, "arrival", "normal:12h,3h", "distribution of the arrival time since midnight, uniform:min,max, normal:mean,stddev or exp:mean")
+	generateCommand.StringVar(&dwell, "dwell", "exp:1h", "distribution of the time of stay, uniform:min,max, normal:mean,stddev or exp:mean")
+	generateCommand.Int64Var(&seed, "seed", 1, "seed of the random generator, the same seed generates the same journals")
+	generateCommand.StringVar(&generateDir, "dir", journalDir, "directory the journal files are written to")
+	generateCommand.StringVar(&contactsPath, "contacts", "", "filename for the known contacts of the generated journals")
+	generateCommand.StringVar(&format, "format", "csv", "output format of the contacts, csv or json")
+
+	chartCommand := flag.NewFlagSet("chart", flag.ExitOnError)
+	chartCommand.StringVar(&chartType, "type", "occupancy", "the chart, occupancy over time, heatmap of the visitors per location and hour or dwell time distribution")
+	chartCommand.StringVar(&location, "location", "", "location for which the chart is drawn, all locations if empty")
+	chartCommand.DurationVar(&bin, "bin", 30*time.Minute, "width of the bars of the dwell time distribution")
+	chartCommand.StringVar(&chartPath, "w", "chart.png", "filename, the image format is png or svg by the extension")
+
+	lettersCommand := flag.NewFlagSet("letters", flag.ExitOnError)
+	lettersCommand.StringVar(&person, "person", "", "person to whose contacts the letters are written")
+	lettersCommand.StringVar(&templatePath, "template", "", "the `path` to the template of the letters, an html/template for .html files, a text/template otherwise")
+	lettersCommand.StringVar(&lettersDir, "w", "letters", "empty directory the letters are written to, one file per contact person")
+	lettersCommand.StringVar(&combinedPath, "combined", "", "filename of one printable HTML file with all letters instead of one file per letter")
+
+	shellCommand := flag.NewFlagSet("shell", flag.ExitOnError)
+
+	summarizeCommand := flag.NewFlagSet("summarize", flag.ExitOnError)
+	summarizeCommand.BoolVar(&newKey, "new-key", false, "create a new secret key at the -key path, which must not exist yet")
+
+	statisticsCommand := flag.NewFlagSet("statistics", flag.ExitOnError)
+	statisticsCommand.StringVar(&filePath, "w", "", "filename")
+	statisticsCommand.StringVar(&format, "format", "csv", "output format, csv or json")
+
+	exportSQLCommand := flag.NewFlagSet("export-sql", flag.ExitOnError)
+	exportSQLCommand.StringVar(&dbPath, "db", "attendance.db", "the SQLite database file `path` the journal files are exported to, only new or changed days are exported")
+
+	for _, command := range []*flag.FlagSet{summarizeCommand, statisticsCommand} {
+		command.StringVar(&summaryKeyPath, "key", "", "the `path` to the secret key of the pseudonyms in the summary files, required, must not be in the "+journalDir+" directory")
+	}
+
+	watchCommand := flag.NewFlagSet("watch", flag.ExitOnError)
+	watchCommand.BoolVar(&showOccupancy, "occupancy", false, "show a continuously updated occupancy table of the locations instead of the events")
+	watchCommand.BoolVar(&history, "history", false, "also print the events written before the command was started")
+	watchCommand.DurationVar(&interval, "interval", time.Second, "how often the journal file is checked for new events")
+
+	verifyReportCommand := flag.NewFlagSet("verify-report", flag.ExitOnError)
+	verifyReportCommand.StringVar(&certFile, "cert", "", "the certificate `path` of the key pair which signed the report")
+
+	serveCommand := flag.NewFlagSet("serve", flag.ExitOnError)
+	serveCommand.IntVar(&port, "port", 8443, "the port the API is running on")
+	serveCommand.StringVar(&certFile, "cert", "", "the `path` to the SSL/TLS certificate file")
+	serveCommand.StringVar(&keyFile, "key", "", "the `path` to the SSL/TLS key file")
+	serveCommand.StringVar(&tokensPath, "tokens", "", "the `path` to the API tokens file with a line name:sha256(token) for every user")
+	serveCommand.StringVar(&logPath, "log", "", "filename of the request log, the log is written to the console if empty")
+
+	// All commands which read journal files can decrypt the contact data.
+	for _, command := range []*flag.FlagSet{locationsCommand, contactsCommand, attendancesCommand, occupancyCommand, identitiesCommand,
+		atCommand, outbreakCommand, timelineCommand, checkCommand, coursesCommand, reconcileCommand, reportCommand, anonymizeCommand, chartCommand, lettersCommand, shellCommand, serveCommand, watchCommand,
+		summarizeCommand, statisticsCommand, exportSQLCommand} {
+		command.StringVar(&privateKeyPath, "private-key", "", "the private key `path` to decrypt encrypted contact data, persons are shown as hash otherwise")
+		command.StringVar(&keySharesPath, "key-shares", "", "comma-separated `paths` of key shares which recover the private key instead of -private-key")
+		command.BoolVar(&idsOnly, "ids-only", false, "show the pseudonymous IDs of the persons instead of the contact data")
+	}
+
+	// The commands which read a date range read the journal files concurrently.
+	for _, command := range []*flag.FlagSet{contactsCommand, outbreakCommand, timelineCommand, checkCommand, coursesCommand,
+		reconcileCommand, reportCommand, anonymizeCommand, chartCommand, lettersCommand, shellCommand, summarizeCommand} {
+		command.IntVar(&workers, "workers", runtime.NumCPU(), "number of journal files of a date range which are read concurrently, also the number of cases traced concurrently by contacts -cases")
+	}
+
+	// Subcommands of the keys command
+	keysSplitCommand := flag.NewFlagSet("keys split", flag.ExitOnError)
+	keysSplitCommand.IntVar(&shareCount, "n", 5, "number of shares")
+	keysSplitCommand.IntVar(&threshold, "k", 2, "number of shares required to recover the key")
+	keysSplitCommand.StringVar(&shareDir, "w", ".", "directory the shares are written to")
+
+	keysCombineCommand := flag.NewFlagSet("keys combine", flag.ExitOnError)
+	keysCombineCommand.StringVar(&filePath, "w", "", "filename of the recovered key")
+
+	// Subcommands of the subject command
+	subjectExportCommand := flag.NewFlagSet("subject export", flag.ExitOnError)
+	subjectExportCommand.StringVar(&filePath, "w", "", "filename")
+	subjectExportCommand.StringVar(&format, "format", "csv", "output format, csv or json")
+
+	subjectEraseCommand := flag.NewFlagSet("subject erase", flag.ExitOnError)
+	subjectEraseCommand.BoolVar(&dryRun, "dry-run", false, "only print how many entries would be erased")
+
+	for _, command := range []*flag.FlagSet{subjectExportCommand, subjectEraseCommand} {
+		command.StringVar(&privateKeyPath, "private-key", "", "the private key `path` to decrypt encrypted contact data")
+		command.StringVar(&keySharesPath, "key-shares", "", "comma-separated `paths` of key shares which recover the private key instead of -private-key")
+	}
+
+	// The watch command takes no date, it follows the journal file of the
+	// current day.
+	if len(os.Args) > 1 && os.Args[1] == watchCommand.Name() {
+		watchCommand.Parse(os.Args[2:])
+		priv, err := loadPrivateKey(privateKeyPath, keySharesPath)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err.Error())
+			os.Exit(1)
+		}
+
+		if err := watch(journalDir, readOptions{priv: priv, idsOnly: idsOnly}, interval, showOccupancy, history, os.Stdout); err != nil {
+			fmt.Fprintln(os.Stderr, err.Error())
+			os.Exit(1)
+		}
+
+		return
+	}
 
 	// Command must contain:
 	// analyzer [command] <date>
@@ -41,20 +245,116 @@ func main() {
 		os.Exit(1)
 	}
 
-	// Parse date
-	lastArg := os.Args[len(os.Args)-1]
-	date, err := timeutil.ParseDate(lastArg)
-	if err != nil {
-		fmt.Fprintln(os.Stderr, usage())
-		os.Exit(1)
+	// The keys command has subcommands and takes a variable number of files.
+	if os.Args[1] == "keys" {
+		var msg string
+		var err error
+		switch os.Args[2] {
+		case "split":
+			keysSplitCommand.Parse(os.Args[3:])
+			if keysSplitCommand.NArg() != 1 {
+				keysSplitCommand.Usage()
+				os.Exit(1)
+			}
+
+			msg, err = splitKey(keysSplitCommand.Arg(0), shareCount, threshold, shareDir)
+		case "combine":
+			keysCombineCommand.Parse(os.Args[3:])
+			if keysCombineCommand.NArg() == 0 {
+				keysCombineCommand.Usage()
+				os.Exit(1)
+			}
+
+			msg, err = combineKey(keysCombineCommand.Args(), filePath)
+		default:
+			fmt.Fprintln(os.Stderr, usage())
+			os.Exit(1)
+		}
+
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err.Error())
+			os.Exit(1)
+		}
+
+		fmt.Print(msg)
+		return
 	}
 
-	// Read journal file
-	j, err := journal.ReadJournal("data", date)
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "cannot read journal file for the specific date: %v\n", err)
+	// The subject command has subcommands and searches all journal files.
+	if os.Args[1] == "subject" {
+		var command *flag.FlagSet
+		switch os.Args[2] {
+		case "export":
+			command = subjectExportCommand
+		case "erase":
+			command = subjectEraseCommand
+		default:
+			fmt.Fprintln(os.Stderr, usage())
+			os.Exit(1)
+		}
+
+		command.Parse(os.Args[3:])
+		if command.NArg() != 1 {
+			command.Usage()
+			os.Exit(1)
+		}
+
+		priv, err := loadPrivateKey(privateKeyPath, keySharesPath)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err.Error())
+			os.Exit(1)
+		}
+
+		var msg string
+		if command == subjectExportCommand {
+			msg, err = exportSubject(journalDir, command.Arg(0), priv, filePath, format)
+		} else {
+			msg, err = eraseSubject(journalDir, command.Arg(0), priv, dryRun)
+		}
+
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err.Error())
+			os.Exit(1)
+		}
+
+		fmt.Print(msg)
+		return
 	}
 
+	// The serve command takes no date, the date is part of every request.
+	if os.Args[1] == serveCommand.Name() {
+		serveCommand.Parse(os.Args[2:])
+		if len(certFile) == 0 || len(keyFile) == 0 || len(tokensPath) == 0 {
+			serveCommand.Usage()
+			os.Exit(1)
+		}
+
+		priv, err := loadPrivateKey(privateKeyPath, keySharesPath)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err.Error())
+			os.Exit(1)
+		}
+
+		out := os.Stderr
+		if len(logPath) > 0 {
+			out, err = os.OpenFile(logPath, os.O_APPEND|os.O_WRONLY|os.O_CREATE, 0600)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "cannot open log file: %v\n", err)
+				os.Exit(1)
+			}
+			defer out.Close()
+		}
+
+		logger := log.New(out, "", log.LstdFlags)
+		if err := serve(journalDir, readOptions{priv: priv, idsOnly: idsOnly}, tokensPath, port, certFile, keyFile, logger); err != nil {
+			fmt.Fprintln(os.Stderr, err.Error())
+			os.Exit(1)
+		}
+
+		return
+	}
+
+	lastArg := os.Args[len(os.Args)-1]
 	args := os.Args[2 : len(os.Args)-1]
 
 	// Decide which command should be executed.
@@ -65,11 +365,297 @@ func main() {
 		contactsCommand.Parse(args)
 	case attendancesCommand.Name():
 		attendancesCommand.Parse(args)
+	case identitiesCommand.Name():
+		identitiesCommand.Parse(args)
+	case occupancyCommand.Name():
+		occupancyCommand.Parse(args)
+	case atCommand.Name():
+		atCommand.Parse(args)
+	case outbreakCommand.Name():
+		outbreakCommand.Parse(args)
+	case timelineCommand.Name():
+		timelineCommand.Parse(args)
+	case checkCommand.Name():
+		checkCommand.Parse(args)
+	case coursesCommand.Name():
+		coursesCommand.Parse(args)
+	case reconcileCommand.Name():
+		reconcileCommand.Parse(args)
+	case reportCommand.Name():
+		reportCommand.Parse(args)
+	case anonymizeCommand.Name():
+		anonymizeCommand.Parse(args)
+	case chartCommand.Name():
+		chartCommand.Parse(args)
+	case lettersCommand.Name():
+		lettersCommand.Parse(args)
+	case shellCommand.Name():
+		shellCommand.Parse(args)
+	case summarizeCommand.Name():
+		summarizeCommand.Parse(args)
+	case statisticsCommand.Name():
+		statisticsCommand.Parse(args)
+	case exportSQLCommand.Name():
+		exportSQLCommand.Parse(args)
+	case generateCommand.Name():
+		generateCommand.Parse(args)
+	case verifyReportCommand.Name():
+		verifyReportCommand.Parse(args)
 	default:
 		fmt.Fprintln(os.Stderr, usage())
 		os.Exit(1)
 	}
 
+	// Load the private key for encrypted journal files.
+	priv, err := loadPrivateKey(privateKeyPath, keySharesPath)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err.Error())
+		os.Exit(1)
+	}
+
+	opts := readOptions{priv: priv, idsOnly: idsOnly, workers: workers}
+
+	// The at command takes a timestamp instead of a date.
+	if atCommand.Parsed() {
+		timestamp, err := timeutil.ParseDateTime(lastArg)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, usage())
+			os.Exit(1)
+		}
+
+		j, err := readJournal(journalDir, timestamp.Date(), opts)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "cannot read journal file for the specific date: %v\n", err)
+		}
+
+		if msg, err := createPresenceList(j, timestamp, location, filePath, format); err != nil {
+			fmt.Fprintln(os.Stderr, err.Error())
+		} else {
+			fmt.Print(msg)
+		}
+
+		return
+	}
+
+	// The verify-report command takes the path of a report.
+	if verifyReportCommand.Parsed() {
+		if len(certFile) == 0 {
+			verifyReportCommand.Usage()
+			os.Exit(1)
+		}
+
+		msg, err := verifyReport(lastArg, certFile)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err.Error())
+			os.Exit(1)
+		}
+
+		fmt.Print(msg)
+		return
+	}
+
+	// The generate command writes journal files instead of reading them.
+	if generateCommand.Parsed() {
+		from, to, err := timeutil.ParseDateRange(lastArg)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, usage())
+			os.Exit(1)
+		}
+
+		locs, err := generateLocations(locationsPath, locationCount)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err.Error())
+			os.Exit(1)
+		}
+
+		days := 0
+		for date := from; !to.Before(date); date = date.AddDays(1) {
+			days++
+		}
+
+		c := synthetic.Config{People: people, Locations: locs, From: from, Days: days, VisitsPerDay: visitsPerDay, Seed: seed}
+		if c.Arrival, err = synthetic.ParseDistribution(arrival); err == nil {
+			c.Dwell, err = synthetic.ParseDistribution(dwell)
+		}
+
+		if err == nil {
+			var msg string
+			if msg, err = generateJournals(c, generateDir, contactsPath, format); err == nil {
+				fmt.Print(msg)
+				return
+			}
+		}
+
+		fmt.Fprintln(os.Stderr, err.Error())
+		os.Exit(1)
+	}
+
+	// The summarize, statistics and export-sql commands take a range of dates,
+	// but read only the journal files they need.
+	if summarizeCommand.Parsed() || statisticsCommand.Parsed() || exportSQLCommand.Parsed() {
+		from, to, err := timeutil.ParseDateRange(lastArg)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, usage())
+			os.Exit(1)
+		}
+
+		var msg string
+		switch {
+		case summarizeCommand.Parsed():
+			msg, err = summarize(journalDir, from, to, opts, summaryKeyPath, newKey)
+		case statisticsCommand.Parsed():
+			msg, err = createStatistics(journalDir, from, to, opts, summaryKeyPath, filePath, format)
+		case exportSQLCommand.Parsed():
+			msg, err = exportSQL(journalDir, from, to, opts, dbPath)
+		}
+
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err.Error())
+			os.Exit(1)
+		}
+
+		fmt.Print(msg)
+		return
+	}
+
+	// The following commands take a range of dates.
+	if outbreakCommand.Parsed() && len(location) == 0 {
+		outbreakCommand.Usage()
+		os.Exit(1)
+	}
+
+	if timelineCommand.Parsed() && len(person) == 0 {
+		timelineCommand.Usage()
+		os.Exit(1)
+	}
+
+	if coursesCommand.Parsed() && len(schedulePath) == 0 {
+		coursesCommand.Usage()
+		os.Exit(1)
+	}
+
+	if reconcileCommand.Parsed() && (len(rosterPath) == 0 || len(location) == 0) {
+		reconcileCommand.Usage()
+		os.Exit(1)
+	}
+
+	if reportCommand.Parsed() && (len(person) == 0 || len(certFile) == 0 || len(keyFile) == 0) {
+		reportCommand.Usage()
+		os.Exit(1)
+	}
+
+	if lettersCommand.Parsed() && (len(person) == 0 || len(templatePath) == 0) {
+		lettersCommand.Usage()
+		os.Exit(1)
+	}
+
+	if outbreakCommand.Parsed() || timelineCommand.Parsed() || checkCommand.Parsed() || coursesCommand.Parsed() ||
+		reconcileCommand.Parsed() || reportCommand.Parsed() || anonymizeCommand.Parsed() || chartCommand.Parsed() || lettersCommand.Parsed() || shellCommand.Parsed() ||
+		(contactsCommand.Parsed() && len(casesPath) > 0) {
+		from, to, err := timeutil.ParseDateRange(lastArg)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, usage())
+			os.Exit(1)
+		}
+
+		// Only the sessions at the location matter for these commands, so the
+		// others are dropped while reading.
+		if outbreakCommand.Parsed() || reconcileCommand.Parsed() || chartCommand.Parsed() {
+			opts.location = journal.Location(location)
+		}
+
+		journals, err := readJournals(journalDir, from, to, opts)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "cannot read journal files for the specific dates: %v\n", err)
+			// Results of the remaining days would silently miss the others,
+			// e.g. a signed report without the contacts of a day.
+			if checkCommand.Parsed() {
+				os.Exit(2)
+			}
+
+			os.Exit(1)
+		}
+
+		var msg string
+		switch {
+		case outbreakCommand.Parsed():
+			msg, err = createOutbreakList(journals, location, fromClock, toClock, filePath, format)
+		case timelineCommand.Parsed():
+			msg, err = createTimeline(journals, person, filePath, format)
+		case checkCommand.Parsed():
+			thresholds := map[journal.AnomalyKind]int{
+				journal.LogoutWithoutLogin:  maxNoLogin,
+				journal.ConcurrentSessions:  maxConcurrent,
+				journal.UnterminatedSession: maxNoLogout,
+				journal.UnknownLocation:     maxUnknownLocation,
+			}
+
+			var exceeded bool
+			msg, exceeded, err = runCheck(journals, locationsPath, thresholds, examples, filePath, format)
+			if exceeded {
+				fmt.Print(msg)
+				os.Exit(1)
+			}
+		case coursesCommand.Parsed():
+			msg, err = createCourseAttendance(journals, schedulePath, from, to, minOverlap, lectures, filePath, format)
+		case reconcileCommand.Parsed():
+			msg, err = reconcileRoster(journals, rosterPath, location, fromClock, toClock, filePath, format)
+		case reportCommand.Parsed():
+			msg, err = createReport(journals, journalDir, person, lastArg, certFile, keyFile, time.Now(), reportPath, format)
+		case anonymizeCommand.Parsed():
+			msg, err = createAnonymizedExport(journals, journal.AnonymizeOptions{K: k, ZipDigits: zipDigits, Resolution: resolution}, filePath, format)
+		case chartCommand.Parsed():
+			msg, err = createChart(journals, chartType, from, to, location, bin, chartPath)
+		case lettersCommand.Parsed():
+			msg, err = createLetters(journals, person, templatePath, lettersDir, combinedPath, timeutil.Now().Date())
+		case contactsCommand.Parsed():
+			msg, err = createCaseContacts(journals, casesPath, daysBefore, daysAfter, opts, filePath, summaryPath, format)
+		case shellCommand.Parsed():
+			s := newShell(journals)
+			err = runShell(s, newLineReader(s, os.Stdin, os.Stdout), os.Stdout)
+		}
+
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err.Error())
+			// The check command tells a failed run from exceeded limits.
+			if checkCommand.Parsed() {
+				os.Exit(2)
+			}
+
+			os.Exit(1)
+		}
+
+		fmt.Print(msg)
+		return
+	}
+
+	// Parse date
+	date, err := timeutil.ParseDate(lastArg)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, usage())
+		os.Exit(1)
+	}
+
+	// The occupancy statistics are read from the summary file if it is up to
+	// date, so the journal file doesn't need to be parsed.
+	if occupancyCommand.Parsed() && !timeline {
+		if s, err := journal.ReadSummary(journalDir, date); err == nil {
+			if msg, err := createOccupancyFromSummary(s, location, filePath, format); err != nil {
+				fmt.Fprintln(os.Stderr, err.Error())
+			} else {
+				fmt.Print(msg)
+			}
+
+			return
+		}
+	}
+
+	// Read journal file
+	j, err := readJournal(journalDir, date, opts)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "cannot read journal file for the specific date: %v\n", err)
+	}
+
 	if locationsCommand.Parsed() {
 		if len(person) == 0 {
 			locationsCommand.Usage()
@@ -91,7 +677,7 @@ func main() {
 			os.Exit(1)
 		}
 
-		if msg, err := printContactsForPerson(j, person, filePath); err != nil {
+		if msg, err := printContactsForPerson(j, person, filePath, format); err != nil {
 			fmt.Fprintln(os.Stderr, err.Error())
 		} else {
 			fmt.Print(msg)
@@ -106,7 +692,27 @@ func main() {
 			os.Exit(1)
 		}
 
-		if msg, err := createAttendanceListForLocation(j, location, filePath); err != nil {
+		if msg, err := createAttendanceListForLocation(j, location, filePath, format); err != nil {
+			fmt.Fprintln(os.Stderr, err.Error())
+		} else {
+			fmt.Print(msg)
+		}
+
+		return
+	}
+
+	if occupancyCommand.Parsed() {
+		if msg, err := createOccupancy(j, location, timeline, filePath, format); err != nil {
+			fmt.Fprintln(os.Stderr, err.Error())
+		} else {
+			fmt.Print(msg)
+		}
+
+		return
+	}
+
+	if identitiesCommand.Parsed() {
+		if msg, err := identifyPersons(j, path.Join(journalDir, journal.MappingFileName), confirm, os.Stdin, os.Stdout); err != nil {
 			fmt.Fprintln(os.Stderr, err.Error())
 		} else {
 			fmt.Print(msg)
@@ -121,37 +727,250 @@ func usage() string {
 
 Usage:
     analyzer [command] <date>
+    analyzer at <timestamp>
+    analyzer contacts -cases <case list> <date range>
+    analyzer outbreak <date range>
+    analyzer timeline <date range>
+    analyzer check <date range>
+    analyzer courses <date range>
+    analyzer reconcile <date range>
+    analyzer report <date range>
+    analyzer anonymize <date range>
+    analyzer chart [-type occupancy|heatmap|dwell] [-w <file>] <date range>
+    analyzer letters -template <file> [-w <dir>|-combined <file>] <date range>
+    analyzer shell <date range>
+    analyzer summarize -key <file> [-new-key] <date range>
+    analyzer statistics -key <file> <date range>
+    analyzer export-sql [-db <database>] <date range>
+    analyzer generate <date range>
+    analyzer verify-report <report>
+    analyzer subject export [-w <file>] [-format csv] <person>
+    analyzer subject erase [-dry-run] <person>
+    analyzer watch [-occupancy] [-history]
+    analyzer serve -cert <cert> -key <key> -tokens <tokens file>
+    analyzer keys split [-n 5] [-k 2] [-w <dir>] <private key>
+    analyzer keys combine [-w <file>] <share> <share>...
 
     <date> is of form YYYY/mm/dd and specifies for which date a
     journal file should be load.
+    <date range> is of form YYYY/mm/dd or YYYY/mm/dd-YYYY/mm/dd and
+    specifies the first and the last date of the journal files to load.
+    <timestamp> is of form "YYYY/mm/dd hh:mm[:ss]" and specifies an
+    instant of a day.
 
 Commands:
     locations    Print locations for a specific person.
-    contacts     Print all contacts for a specific person.
+    contacts     Print all contacts for a specific person. With a case
+                 list, trace all cases of a date range in parallel and
+                 list the persons exposed to several cases.
     attendances  Create an attendance list for a specific location.
+    occupancy    Compute the occupancy statistics for the locations.
+    identities   Find persons which are probably identical and merge them.
+    at           Print all persons present at a specific instant.
+    outbreak     Print all persons present at a location within a time
+                 window on each day of a date range.
+    timeline     Print all visits of a specific person in chronological
+                 order, also as iCalendar file.
+    check        Report anomalies in the journal files. Exits with
+                 status 1 if a configured limit is exceeded and with
+                 status 2 if the journal files or the locations cannot
+                 be read.
+    courses      Compute the attendance percentage of every person per
+                 course of a lecture schedule.
+    reconcile    Compare a roster with the persons present at a location
+                 within a time window and list present, absent and
+                 unexpected persons.
+    report       Create a signed report for the health authority with
+                 the contacts of an index case and the attendance lists
+                 of all locations the index case visited.
+    anonymize    Export the visits without names and streets, with
+                 coarsened zip codes and times, for research. Visits
+                 which share their attributes with less than k visits
+                 are suppressed.
+    chart        Draw the occupancy over time, a heatmap of the visitors
+                 per location and hour or the distribution of the time
+                 of stay as PNG or SVG image.
+    letters      Write a notification letter to every contact person of
+                 a specific person from a text or HTML template, one file
+                 per letter or one printable HTML file with all letters.
+    shell        Load the journal files once and answer the commands find,
+                 locations, contacts, attendances and export
+                 interactively. The selected person is kept between the
+                 commands, with history and tab completion.
+    summarize    Write a summary file next to every journal file with the
+                 occupancy statistics and the pseudonymized visits of the
+                 persons. The occupancy and statistics commands read the
+                 summaries instead of the journal files if they exist.
+    statistics   Print the occupancy statistics of every location on
+                 every day of a date range and the number of different
+                 persons, from the summary files where possible.
+    export-sql   Export the persons, locations, sessions and events of
+                 the journal files into a SQLite database file. Only new
+                 or changed days are added to an existing database.
+    generate     Write synthetic journal files for load tests. The
+                 number of persons, the locations, the arrival and dwell
+                 time distributions and the seed are configurable, the
+                 known contacts can be written for correctness checks.
+    verify-report
+                 Check the signature and the checksums of a report.
+    subject      Export all journal entries of a person across all
+                 journal files or erase them. The person is also removed
+                 from the registry and the mapping file.
+    watch        Print the logins and logouts of the current day as the
+                 service writes them, optionally as continuously updated
+                 occupancy table of the locations.
+    serve        Answer the queries locations, contacts, attendances and
+                 occupancy as JSON HTTP API over HTTPS, e.g.
+                 GET /api/contacts?date=2021/10/15&person=Hans,Müller
+                 with the header "Authorization: Bearer <token>".
+    keys         Split the private key for encrypted journal files into
+                 n shares, so that k of them are required to recover it,
+                 or combine the shares to the private key.
+
+A person is searched by comma-separated attributes in any order, e.g.
+"Hans,Müller". Attributes can be qualified with a field name, e.g.
+"lastName=Müller,zip=74722". Valid field names are firstName, lastName,
+street, number, zipCode (or zip) and city.
+The search ignores case, surrounding whitespace and the spelling of
+umlauts. If no person matches exactly, small typos are tolerated and
+the candidates are ranked by their similarity.
+
+If the contact data in the journal files is encrypted, persons are
+shown as hash unless the private key is given with -private-key or
+recovered from the shares given with -key-shares.
+If the service writes pseudonymized journal files, the persons are joined
+with the registry file data/persons.registry. Use -ids-only to show the
+pseudonymous IDs instead.
+
+Persons merged with the identities command are stored in the file
+data/identities.json and treated as one person by all commands.
 
 To get help for any command type -h after the command name.
 `
 }
 
-func printVisitedLocationsForPerson(j journal.Journal, person string) (string, error) {
-	persons := getMatchingPersonsFromJournal(j, person)
+// readOptions control how the journal files are read and how their persons
+// are resolved.
+type readOptions struct {
+	// priv decrypts encrypted persons if it is not nil.
+	priv crypto.PrivateKey
+	// idsOnly keeps the pseudonymous IDs of the persons, neither the private
+	// key nor the registry file is used.
+	idsOnly bool
+	// workers is the number of journal files of a date range which are read
+	// concurrently, at least one.
+	workers int
+	// location drops all sessions which don't visit this location while
+	// reading a date range if it is not empty.
+	location journal.Location
+}
+
+// readJournal reads the journal file for the date from the dir directory and
+// resolves the persons with resolvePersons.
+func readJournal(dir string, date timeutil.Date, opts readOptions) (journal.Journal, error) {
+	priv := opts.priv
+	if opts.idsOnly {
+		priv = nil
+	}
 
-	if len(persons) < 1 {
-		return "", fmt.Errorf("no person found matches this attributes")
+	j, err := journal.ReadJournalWithKey(dir, date, priv)
+	if err != nil {
+		return j, err
 	}
 
-	if len(persons) > 1 {
-		errMsg := "there are more than one person matching this attributes:\n"
-		for _, p := range persons {
-			errMsg += fmt.Sprintf("  %v\n", p.String())
+	journals, err := resolvePersons([]journal.Journal{j}, dir, opts)
+	return journals[0], err
+}
+
+// readJournals reads the journal files for all dates from the date from to the
+// date to with opts.workers workers concurrently. The persons are resolved like
+// resolvePersons and the sessions are filtered by opts.location in the workers.
+//
+// The result is the same as reading the journal files one after another.
+func readJournals(dir string, from, to timeutil.Date, opts readOptions) ([]journal.Journal, error) {
+	priv := opts.priv
+	if opts.idsOnly {
+		priv = nil
+	}
+
+	resolve, err := personResolver(dir, opts)
+	if err != nil {
+		return []journal.Journal{}, err
+	}
+
+	return journal.ReadJournalsConcurrently(dir, from, to, priv, opts.workers, func(j journal.Journal) journal.Journal {
+		j = resolve(j)
+		if len(opts.location) > 0 {
+			j = keepLocation(j, opts.location)
 		}
-		errMsg += "add more search criterias"
-		return "", fmt.Errorf(errMsg)
+
+		return j
+	})
+}
+
+// resolvePersons joins the pseudonymous persons of the journals with the
+// registry file and merges the persons listed in the mapping file of the dir
+// directory. In ID-only mode the journals are returned unchanged.
+func resolvePersons(journals []journal.Journal, dir string, opts readOptions) ([]journal.Journal, error) {
+	resolve, err := personResolver(dir, opts)
+	if err != nil {
+		return journals, err
+	}
+
+	for i, j := range journals {
+		journals[i] = resolve(j)
+	}
+
+	return journals, nil
+}
+
+// personResolver reads the registry file and the mapping file of the dir
+// directory and returns a function which resolves the persons of a journal
+// like resolvePersons. The function is safe for concurrent use.
+func personResolver(dir string, opts readOptions) (func(j journal.Journal) journal.Journal, error) {
+	if opts.idsOnly {
+		return func(j journal.Journal) journal.Journal { return j }, nil
+	}
+
+	r, err := journal.ReadRegistryIfExists(path.Join(dir, journal.RegistryFileName))
+	if err != nil {
+		return nil, err
+	}
+
+	m, err := journal.ReadPersonMappingIfExists(path.Join(dir, journal.MappingFileName))
+	if err != nil {
+		return nil, err
+	}
+
+	return func(j journal.Journal) journal.Journal {
+		return j.ApplyRegistry(r).ApplyMapping(m)
+	}, nil
+}
+
+// keepLocation returns a copy of the journal j with all entries of the
+// sessions which visit the location l. The entries of a session are kept
+// completely, so the sessions at l are the same as in j.
+func keepLocation(j journal.Journal, l journal.Location) journal.Journal {
+	ids := make(map[string]bool)
+	for _, e := range j.Entries {
+		if e.Location == l {
+			ids[e.SessionID] = true
+		}
+	}
+
+	return j.Filter(func(e journal.JournalEntry) bool {
+		return ids[e.SessionID]
+	})
+}
+
+func printVisitedLocationsForPerson(j journal.Journal, person string) (string, error) {
+	p, err := selectPerson(j, person)
+	if err != nil {
+		return "", err
 	}
 
 	// Get Locations for this peson
-	locs := j.GetVisitedLocationsForPerson(&persons[0])
+	locs := j.GetVisitedLocationsForPerson(&p)
 	msg := ""
 	for _, l := range locs {
 		msg += fmt.Sprintln(l)
@@ -160,79 +979,246 @@ func printVisitedLocationsForPerson(j journal.Journal, person string) (string, e
 	return msg, nil
 }
 
-func printContactsForPerson(j journal.Journal, person string, filePath string) (string, error) {
-	persons := getMatchingPersonsFromJournal(j, person)
+func printContactsForPerson(j journal.Journal, person string, filePath string, format string) (string, error) {
+	p, err := selectPerson(j, person)
+	if err != nil {
+		return "", err
+	}
+
+	// Get Contacts for this peson
+	contacts := j.GetContactsForPerson(&p)
+	return writeOutput(contacts, filePath, format)
+}
+
+func createAttendanceListForLocation(j journal.Journal, location string, filePath string, format string) (string, error) {
+	list := j.GetAttendanceListForLocation(journal.Location(location))
+	return writeOutput(list, filePath, format)
+}
 
-	if len(persons) < 1 {
-		return "", fmt.Errorf("no person found matches this attributes")
+func createPresenceList(j journal.Journal, timestamp timeutil.Timestamp, location string, filePath string, format string) (string, error) {
+	var list journal.SessionList
+	if len(location) > 0 {
+		list = j.GetSessionsAt(timestamp, journal.Location(location))
+	} else {
+		list = j.GetSessionsAt(timestamp)
 	}
 
-	if len(persons) > 1 {
-		errMsg := "there are more than one person matching this attributes:\n"
-		for _, p := range persons {
-			errMsg += fmt.Sprintf("  %v\n", p.String())
-		}
-		errMsg += "add more search criterias"
-		return "", fmt.Errorf(errMsg)
+	return writeOutput(list, filePath, format)
+}
+
+func createOutbreakList(journals []journal.Journal, location string, fromClock string, toClock string, filePath string, format string) (string, error) {
+	list, err := getVisitorsInWindow(journals, location, fromClock, toClock)
+	if err != nil {
+		return "", err
 	}
 
-	// Get Contacts for this peson
-	contacts := j.GetContactsForPerson(&persons[0])
-	return writeToCSV(contacts, filePath)
+	return writeOutput(list, filePath, format)
 }
 
-func createAttendanceListForLocation(j journal.Journal, location string, filePath string) (string, error) {
-	list := j.GetAttendanceListForLocation(journal.Location(location))
-	return writeToCSV(list, filePath)
+// getVisitorsInWindow returns the visitors of the location within the time
+// window from fromClock to toClock on each day of the journals.
+func getVisitorsInWindow(journals []journal.Journal, location string, fromClock string, toClock string) (journal.ContactList, error) {
+	from, err := timeutil.ParseClock(fromClock)
+	if err != nil {
+		return nil, err
+	}
+
+	to, err := timeutil.ParseClock(toClock)
+	if err != nil {
+		return nil, err
+	}
+
+	if to <= from {
+		return nil, fmt.Errorf("the end of the time window must be after the begin")
+	}
+
+	list := journal.ContactList{}
+	for _, j := range journals {
+		list = append(list, j.GetVisitorsForLocation(journal.Location(location), j.Date.At(from), j.Date.At(to))...)
+	}
+
+	return list, nil
 }
 
-func getMatchingPersonsFromJournal(j journal.Journal, person string) []journal.Person {
-	attr := strings.Split(person, ",")
-	persons := make([]journal.Person, 0)
+func reconcileRoster(journals []journal.Journal, rosterPath string, location string, fromClock string, toClock string, filePath string, format string) (string, error) {
+	roster, err := readRoster(rosterPath)
+	if err != nil {
+		return "", err
+	}
 
-loop:
-	for _, e := range j.Entries {
-		p := e.Person
+	list, err := getVisitorsInWindow(journals, location, fromClock, toClock)
+	if err != nil {
+		return "", err
+	}
 
-		// If same person, skip this entry
-		for _, per := range persons {
-			if p == per {
-				continue loop
-			}
+	seen := make(map[journal.Person]bool)
+	visitors := make([]journal.Person, 0)
+	for _, c := range list {
+		if !seen[c.Person] {
+			seen[c.Person] = true
+			visitors = append(visitors, c.Person)
 		}
+	}
 
-		// Attributes can be in a random order.
-		for _, a := range attr {
-			if p.FirstName != a && p.LastName != a && p.Address.Street != a && p.Address.Number != a && p.Address.ZipCode != a && p.Address.City != a {
-				continue loop
-			}
-		}
+	return writeOutput(reconcile(roster, visitors), filePath, format)
+}
+
+func createAnonymizedExport(journals []journal.Journal, opts journal.AnonymizeOptions, filePath string, format string) (string, error) {
+	visits, suppressed, err := journal.Anonymize(journals, opts)
+	if err != nil {
+		return "", err
+	}
 
-		persons = append(persons, p)
+	msg, err := writeOutput(visits, filePath, format)
+	if err != nil {
+		return "", err
+	}
+
+	// Don't mix the statistics with the output on the console.
+	if len(filePath) == 0 {
+		return msg, nil
 	}
 
-	return persons
+	return msg + fmt.Sprintf("%v visits released, %v visits suppressed.\n", len(visits), suppressed), nil
 }
 
-func writeToCSV(c convert.Converter, filePath string) (string, error) {
-	var f io.Writer
+func createTimeline(journals []journal.Journal, person string, filePath string, format string) (string, error) {
+	p, err := selectPersonFromJournals(journals, person)
+	if err != nil {
+		return "", err
+	}
+
+	timeline := journal.GetTimelineForPerson(journals, &p)
+	if format != "ics" {
+		return writeOutput(timeline, filePath, format)
+	}
 
-	// If no file path set, write to console
-	if len(filePath) == 0 {
-		f = os.Stdout
-	} else {
-		file, err := os.Create(filePath)
+	events := make([]convert.Event, 0, len(timeline))
+	for _, e := range timeline {
+		events = append(events, convert.Event{
+			UID:         fmt.Sprintf("%v-%v@attendancelist", e.ID, e.Date),
+			Summary:     string(e.Location),
+			Location:    string(e.Location),
+			Description: strings.Join(e.Flags(), ", "),
+			Start:       e.Start.Time,
+			End:         e.End.Time,
+		})
+	}
+
+	f, err := createOutput(filePath)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	if err := convert.ToICS(f, events, time.Now()); err != nil {
+		return "", fmt.Errorf("cannot convert to ics: %w", err)
+	}
+
+	return fmt.Sprintln("Output successfully written."), nil
+}
+
+func createCourseAttendance(journals []journal.Journal, schedulePath string, from timeutil.Date, to timeutil.Date, minOverlap time.Duration, lectures bool, filePath string, format string) (string, error) {
+	s, err := schedule.ReadSchedule(schedulePath)
+	if err != nil {
+		return "", err
+	}
+
+	s = s.Between(from, to)
+	list := s.MapAttendances(journals, minOverlap)
+	if lectures {
+		return writeOutput(list, filePath, format)
+	}
+
+	return writeOutput(list.Summarize(s), filePath, format)
+}
+
+func runCheck(journals []journal.Journal, locationsPath string, thresholds map[journal.AnomalyKind]int, examples int, filePath string, format string) (string, bool, error) {
+	var known []journal.Location
+	if len(locationsPath) > 0 {
+		locations, err := web.ReadLocationsFromXML(locationsPath)
 		if err != nil {
-			return "", fmt.Errorf("cannot create file: %w", err)
+			return "", false, err
+		}
+
+		known = locations.Slice
+	}
+
+	msg, anomalies, exceeded := checkJournals(journals, known, thresholds, examples)
+	if len(filePath) > 0 {
+		if _, err := writeOutput(anomalies, filePath, format); err != nil {
+			return "", exceeded, err
 		}
+	}
+
+	return msg, exceeded, nil
+}
+
+func createOccupancy(j journal.Journal, location string, timeline bool, filePath string, format string) (string, error) {
+	list := j.GetOccupancy()
+	if len(location) > 0 {
+		list = journal.OccupancyList{j.GetOccupancyForLocation(journal.Location(location))}
+	}
 
-		defer file.Close()
-		f = file
+	if timeline {
+		return writeOutput(list.Timeline(), filePath, format)
 	}
 
-	if err := convert.ToCSV(f, c); err != nil {
-		return "", fmt.Errorf("cannot convert to csv: %w", err)
+	return writeOutput(list, filePath, format)
+}
+
+func writeOutput(c convert.Converter, filePath string, format string) (string, error) {
+	convertTo, err := converterFor(format)
+	if err != nil {
+		return "", err
+	}
+
+	f, err := createOutput(filePath)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	if err := convertTo(f, c); err != nil {
+		return "", fmt.Errorf("cannot convert to %v: %w", format, err)
 	}
 
 	return fmt.Sprintln("Output successfully written."), nil
 }
+
+// converterFor returns the function which converts a convert.Converter to the
+// output format.
+func converterFor(format string) (func(io.Writer, convert.Converter) error, error) {
+	switch format {
+	case "", "csv":
+		return convert.ToCSV, nil
+	case "json":
+		return convert.ToJSON, nil
+	default:
+		return nil, fmt.Errorf("unknown output format \"%v\"", format)
+	}
+}
+
+// createOutput creates the file at filePath. If no file path set, the output
+// is written to the console.
+func createOutput(filePath string) (io.WriteCloser, error) {
+	if len(filePath) == 0 {
+		return nopCloser{os.Stdout}, nil
+	}
+
+	file, err := os.Create(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("cannot create file: %w", err)
+	}
+
+	return file, nil
+}
+
+// A nopCloser wraps an io.Writer which must not be closed, like os.Stdout.
+type nopCloser struct {
+	io.Writer
+}
+
+func (nopCloser) Close() error {
+	return nil
+}
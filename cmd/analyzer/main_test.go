@@ -9,10 +9,15 @@ package main
 
 import (
 	"fmt"
+	"os"
+	"path"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/dateiexplorer/attendancelist/internal/journal"
 	"github.com/dateiexplorer/attendancelist/internal/timeutil"
+	"github.com/dateiexplorer/attendancelist/internal/web"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -57,7 +62,7 @@ func TestPrintContactsForPerson(t *testing.T) {
 	j, err := journal.ReadJournal("testdata", timeutil.NewDate(2021, 10, 15))
 	assert.NoError(t, err)
 
-	msg, err := printContactsForPerson(j, "Max,Mustermann", "")
+	msg, err := printContactsForPerson(j, "Max,Mustermann", "", "csv")
 	assert.NoError(t, err)
 	assert.Equal(t, fmt.Sprintln("Output successfully written."), msg)
 }
@@ -66,50 +71,313 @@ func TestCreateAttendanceListForLocation(t *testing.T) {
 	j, err := journal.ReadJournal("testdata", timeutil.NewDate(2021, 10, 15))
 	assert.NoError(t, err)
 
-	msg, err := createAttendanceListForLocation(j, "DHBW Mosbach", "")
+	msg, err := createAttendanceListForLocation(j, "DHBW Mosbach", "", "csv")
 	assert.NoError(t, err)
 	assert.Equal(t, fmt.Sprintln("Output successfully written."), msg)
 }
 
-func TestGetMatchingPersonsFromJournal(t *testing.T) {
+func TestSelectPersonSingleAttribute(t *testing.T) {
 	j, err := journal.ReadJournal("testdata", timeutil.NewDate(2021, 10, 15))
 	assert.NoError(t, err)
 
-	persons := getMatchingPersonsFromJournal(j, "Max")
-	expected := []journal.Person{
-		journal.NewPerson("Max", "Mustermann", "Musterstraße", "20", "74821", "Mosbach"),
+	person, err := selectPerson(j, "Max")
+	assert.NoError(t, err)
+	assert.Equal(t, journal.NewPerson("Max", "Mustermann", "Musterstraße", "20", "74821", "Mosbach"), person)
+}
+
+func TestMatchPersonsMultiplePersons(t *testing.T) {
+	j, err := journal.ReadJournal("testdata", timeutil.NewDate(2021, 10, 15))
+	assert.NoError(t, err)
+
+	candidates, err := matchPersons(uniquePersons(j), "Müller")
+	expected := []candidate{
+		{journal.NewPerson("Hans", "Müller", "Feldweg", "12", "74722", "Buchen"), 0},
+		{journal.NewPerson("Lieschen", "Müller", "Lindenstraße", "15", "10115", "Berlin"), 0},
 	}
 
-	assert.Equal(t, expected, persons)
+	assert.NoError(t, err)
+	assert.Equal(t, expected, candidates)
 }
 
-func TestGetMatchingPersonsFromJournalMultiplePersons(t *testing.T) {
+func TestSelectPersonMutlipleAttributesRandomOrder(t *testing.T) {
 	j, err := journal.ReadJournal("testdata", timeutil.NewDate(2021, 10, 15))
 	assert.NoError(t, err)
 
-	persons := getMatchingPersonsFromJournal(j, "Müller")
-	expected := []journal.Person{
-		journal.NewPerson("Hans", "Müller", "Feldweg", "12", "74722", "Buchen"),
-		journal.NewPerson("Lieschen", "Müller", "Lindenstraße", "15", "10115", "Berlin"),
+	expected := journal.NewPerson("Hans", "Müller", "Feldweg", "12", "74722", "Buchen")
+	for _, query := range []string{"Hans,Müller", "Müller,Hans", "Müller,Feldweg,12,Buchen"} {
+		person, err := selectPerson(j, query)
+		assert.NoError(t, err)
+		assert.Equal(t, expected, person)
 	}
+}
+
+func TestCreateOccupancy(t *testing.T) {
+	j, err := journal.ReadJournal("testdata", timeutil.NewDate(2021, 10, 15))
+	assert.NoError(t, err)
+
+	filePath := path.Join(t.TempDir(), "occupancy.json")
+	msg, err := createOccupancy(j, "", false, filePath, "json")
+	assert.NoError(t, err)
+	assert.Equal(t, fmt.Sprintln("Output successfully written."), msg)
+
+	content, err := os.ReadFile(filePath)
+	assert.NoError(t, err)
+	assert.Contains(t, string(content), `"Peak": "5"`)
 
-	assert.Equal(t, expected, persons)
+	filePath = path.Join(t.TempDir(), "timeline.csv")
+	_, err = createOccupancy(j, "Alte Mälzerei", true, filePath, "csv")
+	assert.NoError(t, err)
+
+	content, err = os.ReadFile(filePath)
+	assert.NoError(t, err)
+	assert.Equal(t, `Location,Timestamp,Count
+Alte Mälzerei,2021/10/15 13:40:11 UTC,1
+Alte Mälzerei,2021/10/15 17:32:45 UTC,2
+Alte Mälzerei,2021/10/15 19:15:12 UTC,1
+Alte Mälzerei,2021/10/15 23:59:59 UTC,0
+`, string(content))
+}
+
+func TestWriteOutputUnknownFormat(t *testing.T) {
+	msg, err := writeOutput(journal.AttendanceList{}, "", "xml")
+	assert.Equal(t, "", msg)
+	assert.Error(t, err)
 }
 
-func TestGetMatchingPersonsFromJournalMutlipleAttributesRandomOrder(t *testing.T) {
+func TestCreatePresenceList(t *testing.T) {
 	j, err := journal.ReadJournal("testdata", timeutil.NewDate(2021, 10, 15))
 	assert.NoError(t, err)
 
-	persons := getMatchingPersonsFromJournal(j, "Hans,Müller")
-	expected := []journal.Person{
-		journal.NewPerson("Hans", "Müller", "Feldweg", "12", "74722", "Buchen"),
+	filePath := path.Join(t.TempDir(), "present.csv")
+	msg, err := createPresenceList(j, timeutil.NewTimestamp(2021, 10, 15, 18, 0, 0), "Alte Mälzerei", filePath, "csv")
+	assert.NoError(t, err)
+	assert.Equal(t, fmt.Sprintln("Output successfully written."), msg)
+
+	content, err := os.ReadFile(filePath)
+	assert.NoError(t, err)
+	assert.Equal(t, `Location,FirstName,LastName,Street,Number,ZipCode,City,Login,Logout
+Alte Mälzerei,Hans,Müller,Feldweg,12,74722,Buchen,2021/10/15 13:40:11 UTC,
+Alte Mälzerei,Otto,Normalverbraucher,Dieselstraße,52,70376,Stuttgart,2021/10/15 17:32:45 UTC,2021/10/15 19:15:12 UTC
+`, string(content))
+}
+
+func TestCreateOutbreakList(t *testing.T) {
+	journals, err := readJournals("testdata", timeutil.NewDate(2021, 10, 15), timeutil.NewDate(2021, 11, 30), readOptions{})
+	assert.NoError(t, err)
+	assert.Len(t, journals, 2)
+
+	filePath := path.Join(t.TempDir(), "outbreak.csv")
+	msg, err := createOutbreakList(journals, "DHBW Mosbach", "11:00", "12:30", filePath, "csv")
+	assert.NoError(t, err)
+	assert.Equal(t, fmt.Sprintln("Output successfully written."), msg)
+
+	content, err := os.ReadFile(filePath)
+	assert.NoError(t, err)
+	assert.Equal(t, `FirstName,LastName,Street,Number,ZipCode,City,Location,Start,End,Duration
+Hans,Müller,Feldweg,12,74722,Buchen,DHBW Mosbach,2021/10/15 11:00:00 UTC,2021/10/15 12:30:00 UTC,1h30m0s
+Gisela,Musterfrau,Musterstraße,10,74821,Mosbach,DHBW Mosbach,2021/10/15 11:00:00 UTC,2021/10/15 12:30:00 UTC,1h30m0s
+Max,Mustermann,Musterstraße,20,74821,Mosbach,DHBW Mosbach,2021/10/15 12:15:30 UTC,2021/10/15 12:30:00 UTC,14m30s
+Anne,Meier,Hauptstraße,18,74821,Mosbach,DHBW Mosbach,2021/10/15 12:17:20 UTC,2021/10/15 12:30:00 UTC,12m40s
+Torsten,Test,Teststraße,10,74821,Mosbach,DHBW Mosbach,2021/11/30 11:00:00 UTC,2021/11/30 12:30:00 UTC,1h30m0s
+Gisela,Musterfrau,Musterstraße,10,74821,Mosbach,DHBW Mosbach,2021/11/30 11:00:00 UTC,2021/11/30 12:30:00 UTC,1h30m0s
+Max,Mustermann,Musterstraße,20,74821,Mosbach,DHBW Mosbach,2021/11/30 11:00:00 UTC,2021/11/30 12:30:00 UTC,1h30m0s
+Anne,Meier,Hauptstraße,18,74821,Mosbach,DHBW Mosbach,2021/11/30 11:00:00 UTC,2021/11/30 12:00:00 UTC,1h0m0s
+`, string(content))
+}
+
+func TestCreateOutbreakListInvalidWindow(t *testing.T) {
+	msg, err := createOutbreakList(nil, "DHBW Mosbach", "12:30", "09:00", "", "csv")
+	assert.Equal(t, "", msg)
+	assert.Error(t, err)
+
+	_, err = createOutbreakList(nil, "DHBW Mosbach", "9 o'clock", "12:00", "", "csv")
+	assert.Error(t, err)
+}
+
+func TestCreateTimeline(t *testing.T) {
+	journals, err := readJournals("testdata", timeutil.NewDate(2021, 10, 15), timeutil.NewDate(2021, 11, 30), readOptions{})
+	assert.NoError(t, err)
+
+	filePath := path.Join(t.TempDir(), "timeline.csv")
+	msg, err := createTimeline(journals, "Lieschen", filePath, "csv")
+	assert.NoError(t, err)
+	assert.Equal(t, fmt.Sprintln("Output successfully written."), msg)
+
+	content, err := os.ReadFile(filePath)
+	assert.NoError(t, err)
+	assert.Equal(t, `Date,Location,Login,Logout,Duration,Gap,Flags
+2021-10-15,DHBW Mosbach,13:30:00,15:42:23,2h12m23s,0s,
+2021-11-30,DHBW Mosbach,13:00:00,16:00:00,3h0m0s,0s,
+`, string(content))
+}
+
+func TestCreateTimelineICS(t *testing.T) {
+	journals, err := readJournals("testdata", timeutil.NewDate(2021, 10, 15), timeutil.NewDate(2021, 11, 30), readOptions{})
+	assert.NoError(t, err)
+
+	filePath := path.Join(t.TempDir(), "timeline.ics")
+	_, err = createTimeline(journals, "Hans,Müller", filePath, "ics")
+	assert.NoError(t, err)
+
+	content, err := os.ReadFile(filePath)
+	assert.NoError(t, err)
+	assert.Equal(t, 4, strings.Count(string(content), "BEGIN:VEVENT"))
+	assert.Contains(t, string(content), "UID:5faacdf0e6e7b44a-2021-11-30@attendancelist")
+	assert.Contains(t, string(content), "DTSTART:20211130T083000Z")
+	assert.Contains(t, string(content), `DESCRIPTION:no logout\, gap`)
+}
+
+func TestCreateTimelineNoPersonFound(t *testing.T) {
+	msg, err := createTimeline(nil, "Max", "", "csv")
+	assert.Equal(t, "", msg)
+	assert.Error(t, err)
+}
+
+func TestCreateCourseAttendance(t *testing.T) {
+	journals, err := readJournals("testdata", timeutil.NewDate(2021, 10, 15), timeutil.NewDate(2021, 11, 30), readOptions{})
+	assert.NoError(t, err)
+
+	filePath := path.Join(t.TempDir(), "courses.csv")
+	msg, err := createCourseAttendance(journals, "testdata/schedule.csv", timeutil.NewDate(2021, 10, 15), timeutil.NewDate(2021, 11, 30), 2*time.Hour, false, filePath, "csv")
+	assert.NoError(t, err)
+	assert.Equal(t, fmt.Sprintln("Output successfully written."), msg)
+
+	content, err := os.ReadFile(filePath)
+	assert.NoError(t, err)
+	assert.Equal(t, `Course,FirstName,LastName,Street,Number,ZipCode,City,Attended,Lectures,Percentage
+Go,Hans,Müller,Feldweg,12,74722,Buchen,1,3,33.3
+Go,Gisela,Musterfrau,Musterstraße,10,74821,Mosbach,2,3,66.7
+Go,Max,Mustermann,Musterstraße,20,74821,Mosbach,1,3,33.3
+`, string(content))
+}
+
+func TestCreateCourseAttendanceLectures(t *testing.T) {
+	journals, err := readJournals("testdata", timeutil.NewDate(2021, 10, 15), timeutil.NewDate(2021, 10, 15), readOptions{})
+	assert.NoError(t, err)
+
+	filePath := path.Join(t.TempDir(), "lectures.csv")
+	_, err = createCourseAttendance(journals, "testdata/schedule.csv", timeutil.NewDate(2021, 10, 15), timeutil.NewDate(2021, 10, 15), 0, true, filePath, "csv")
+	assert.NoError(t, err)
+
+	content, err := os.ReadFile(filePath)
+	assert.NoError(t, err)
+	assert.Equal(t, `Course,Location,Start,End,FirstName,LastName,Street,Number,ZipCode,City,Login,Logout,Overlap
+Go,DHBW Mosbach,2021/10/15 09:00:00 UTC,2021/10/15 12:00:00 UTC,Hans,Müller,Feldweg,12,74722,Buchen,06:20:13,13:40:10,3h0m0s
+Go,DHBW Mosbach,2021/10/15 09:00:00 UTC,2021/10/15 12:00:00 UTC,Gisela,Musterfrau,Musterstraße,10,74821,Mosbach,09:15:20,16:52:00,2h44m40s
+Datenbanken,Alte Mälzerei,2021/10/15 18:00:00 UTC,2021/10/15 19:00:00 UTC,Hans,Müller,Feldweg,12,74722,Buchen,13:40:11,,1h0m0s
+Datenbanken,Alte Mälzerei,2021/10/15 18:00:00 UTC,2021/10/15 19:00:00 UTC,Otto,Normalverbraucher,Dieselstraße,52,70376,Stuttgart,17:32:45,19:15:12,1h0m0s
+`, string(content))
+}
+
+func TestCreateCourseAttendanceNoSchedule(t *testing.T) {
+	msg, err := createCourseAttendance(nil, "testdata/missing.csv", timeutil.NewDate(2021, 10, 15), timeutil.NewDate(2021, 10, 15), 0, false, "", "csv")
+	assert.Equal(t, "", msg)
+	assert.Error(t, err)
+}
+
+func TestReconcileRoster(t *testing.T) {
+	journals, err := readJournals("testdata", timeutil.NewDate(2021, 10, 15), timeutil.NewDate(2021, 10, 15), readOptions{})
+	assert.NoError(t, err)
+
+	filePath := path.Join(t.TempDir(), "reconcile.csv")
+	msg, err := reconcileRoster(journals, "testdata/roster.csv", "DHBW Mosbach", "09:00", "13:00", filePath, "csv")
+	assert.NoError(t, err)
+	assert.Equal(t, fmt.Sprintln("Output successfully written."), msg)
+
+	content, err := os.ReadFile(filePath)
+	assert.NoError(t, err)
+	assert.Equal(t, `Status,StudentID,FirstName,LastName,Street,Number,ZipCode,City
+present,1001,Hans,Müller,Feldweg,12,74722,Buchen
+present,1002,Gisela,Musterfrau,Musterstraße,10,74821,Mosbach
+present,1003,Anne,Meier,Hauptstraße,18,74821,Mosbach
+absent,1004,Erika,Mustermann,,,,
+unexpected,,Max,Mustermann,Musterstraße,20,74821,Mosbach
+`, string(content))
+}
+
+func TestReconcileRosterFailed(t *testing.T) {
+	_, err := reconcileRoster(nil, "testdata/missing.csv", "DHBW Mosbach", "09:00", "12:00", "", "csv")
+	assert.Error(t, err)
+
+	_, err = reconcileRoster(nil, "testdata/roster.csv", "DHBW Mosbach", "12:00", "09:00", "", "csv")
+	assert.Error(t, err)
+}
+
+func TestReadJournalsEncrypted(t *testing.T) {
+	dir := t.TempDir()
+	date := timeutil.NewDate(2021, 10, 15)
+	source, err := journal.ReadJournal("testdata", date)
+	assert.NoError(t, err)
+
+	pub, err := journal.ReadPublicKey("testdata/cert.pem")
+	assert.NoError(t, err)
+	priv, err := journal.ReadPrivateKey("testdata/key.pem")
+	assert.NoError(t, err)
+
+	enc, err := journal.NewPersonEncrypter(pub, []byte("secret"))
+	assert.NoError(t, err)
+	for _, e := range source.Entries {
+		assert.NoError(t, journal.WriteEncryptedToJournalFile(dir, &e, enc))
+	}
+
+	journals, err := readJournals(dir, date, date, readOptions{priv: priv})
+	assert.NoError(t, err)
+	assert.Equal(t, source.Entries, journals[0].Entries)
+
+	// Without the private key the occupancy is still available.
+	j, err := readJournal(dir, date, readOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, source.GetOccupancy(), j.GetOccupancy())
+	assert.Equal(t, journal.Pseudonym(enc.Hash(source.Entries[0].Person)), j.Entries[0].Person)
+}
+
+func TestReadJournalsPseudonymized(t *testing.T) {
+	dir := t.TempDir()
+	date := timeutil.NewDate(2021, 10, 15)
+	source, err := journal.ReadJournal("testdata", date)
+	assert.NoError(t, err)
+
+	ps, err := journal.NewPseudonymizer(path.Join(dir, journal.RegistryFileName), func(p journal.Person) (string, error) {
+		return web.Hash(p, "secret")
+	})
+	assert.NoError(t, err)
+	for _, e := range source.Entries {
+		assert.NoError(t, ps.WriteToJournalFile(dir, &e))
 	}
 
-	assert.Equal(t, expected, persons)
+	// The journal is joined with the registry.
+	j, err := readJournal(dir, date, readOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, source.Entries, j.Entries)
+
+	// In ID-only mode the registry isn't used.
+	journals, err := readJournals(dir, date, date, readOptions{idsOnly: true})
+	assert.NoError(t, err)
 
-	persons = getMatchingPersonsFromJournal(j, "Müller,Hans")
-	assert.Equal(t, expected, persons)
+	id, err := web.Hash(source.Entries[0].Person, "secret")
+	assert.NoError(t, err)
+	assert.Equal(t, journal.Pseudonym(id), journals[0].Entries[0].Person)
+	assert.Equal(t, source.GetOccupancy(), journals[0].GetOccupancy())
+}
 
-	persons = getMatchingPersonsFromJournal(j, "Müller,Feldweg,12,Buchen")
-	assert.Equal(t, expected, persons)
+func TestCreateAnonymizedExport(t *testing.T) {
+	journals, err := readJournals("testdata", timeutil.NewDate(2021, 10, 15), timeutil.NewDate(2021, 11, 30), readOptions{})
+	assert.NoError(t, err)
+
+	filePath := path.Join(t.TempDir(), "anonymized.csv")
+	msg, err := createAnonymizedExport(journals, journal.AnonymizeOptions{K: 2, ZipDigits: 2, Resolution: 4 * time.Hour}, filePath, "csv")
+	assert.NoError(t, err)
+	assert.Equal(t, fmt.Sprintln("Output successfully written.")+fmt.Sprintln("2 visits released, 14 visits suppressed."), msg)
+
+	content, err := os.ReadFile(filePath)
+	assert.NoError(t, err)
+	assert.Equal(t, `Date,Location,ZipCode,Start,End
+2021-10-15,DHBW Mosbach,74***,12:00,20:00
+2021-10-15,DHBW Mosbach,74***,12:00,20:00
+`, string(content))
+}
+
+func TestCreateAnonymizedExportInvalidOptions(t *testing.T) {
+	_, err := createAnonymizedExport(nil, journal.AnonymizeOptions{K: 0, ZipDigits: 2, Resolution: time.Hour}, "", "csv")
+	assert.Error(t, err)
 }
@@ -0,0 +1,295 @@
+// This source file is part of the attendance list project
+// as a part of the go lecture by H. Neemann.
+// For this reason you have no permission to use, modify or
+// share this code without the agreement of the authors.
+//
+// Matriculation numbers of the authors: 5703004, 5736465
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/dateiexplorer/attendancelist/internal/journal"
+)
+
+// A personField describes an attribute of a journal.Person which can be used
+// in a field-qualified query like "lastName=Müller".
+// If fuzzy is false, the attribute must match exactly after normalization.
+type personField struct {
+	value func(p *journal.Person) string
+	fuzzy bool
+}
+
+// personFields maps the lower case field names of a query to the attributes of
+// a journal.Person.
+var personFields = map[string]personField{
+	"firstname": {func(p *journal.Person) string { return p.FirstName }, true},
+	"lastname":  {func(p *journal.Person) string { return p.LastName }, true},
+	"street":    {func(p *journal.Person) string { return p.Address.Street }, true},
+	"number":    {func(p *journal.Person) string { return p.Address.Number }, false},
+	"zipcode":   {func(p *journal.Person) string { return p.Address.ZipCode }, false},
+	"zip":       {func(p *journal.Person) string { return p.Address.ZipCode }, false},
+	"city":      {func(p *journal.Person) string { return p.Address.City }, true},
+}
+
+// unqualifiedFields are the fields which are compared against an attribute
+// without a field name. The order doesn't matter because the best match wins.
+var unqualifiedFields = []string{"firstname", "lastname", "street", "number", "zipcode", "city"}
+
+// transliterations replaces german umlauts and the sharp s with their common
+// ASCII representation, so that "Müller" and "Mueller" are equal.
+var transliterations = strings.NewReplacer("ä", "ae", "ö", "oe", "ü", "ue", "ß", "ss")
+
+// normalize returns a representation of s which can be used to compare names
+// people typed in differently. It trims and collapses whitespace, folds the
+// case and transliterates umlauts.
+func normalize(s string) string {
+	s = strings.ToLower(strings.Join(strings.Fields(s), " "))
+	return transliterations.Replace(s)
+}
+
+// levenshtein returns the edit distance between a and b, i.e. the minimum
+// number of inserted, deleted or substituted runes to turn a into b.
+func levenshtein(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+
+			curr[j] = prev[j-1] + cost
+			if prev[j]+1 < curr[j] {
+				curr[j] = prev[j] + 1
+			}
+			if curr[j-1]+1 < curr[j] {
+				curr[j] = curr[j-1] + 1
+			}
+		}
+
+		prev, curr = curr, prev
+	}
+
+	return prev[len(rb)]
+}
+
+// tolerance returns the edit distance which is accepted for a normalized value.
+// Short values must match exactly, because otherwise nearly everything matches.
+func tolerance(value string) int {
+	switch n := len([]rune(value)); {
+	case n <= 3:
+		return 0
+	case n <= 6:
+		return 1
+	default:
+		return 2
+	}
+}
+
+// A queryTerm is a single attribute of a person query. If field is empty the
+// term matches any attribute of a journal.Person.
+type queryTerm struct {
+	field string
+	value string
+}
+
+// parseQuery splits a comma-separated person query into its terms.
+// A term is either a plain value like "Müller" or a field-qualified value like
+// "lastName=Müller". All values are normalized.
+//
+// An error is returned if a term uses an unknown field name.
+func parseQuery(query string) ([]queryTerm, error) {
+	attrs := strings.Split(query, ",")
+	terms := make([]queryTerm, 0, len(attrs))
+	for _, a := range attrs {
+		term := queryTerm{value: normalize(a)}
+		if i := strings.Index(a, "="); i >= 0 {
+			term.field = strings.ToLower(strings.TrimSpace(a[:i]))
+			term.value = normalize(a[i+1:])
+			if _, ok := personFields[term.field]; !ok {
+				return nil, fmt.Errorf("unknown field \"%v\" in query", strings.TrimSpace(a[:i]))
+			}
+		}
+
+		terms = append(terms, term)
+	}
+
+	return terms, nil
+}
+
+// distance returns the smallest edit distance between the term and the
+// attributes of the Person p. The returned bool is false if no attribute lies
+// within the tolerance.
+func (t queryTerm) distance(p *journal.Person) (int, bool) {
+	fields := unqualifiedFields
+	if t.field != "" {
+		fields = []string{t.field}
+	}
+
+	best, found := 0, false
+	for _, name := range fields {
+		field := personFields[name]
+		value := normalize(field.value(p))
+
+		d := 0
+		if value != t.value {
+			if !field.fuzzy {
+				continue
+			}
+
+			d = levenshtein(value, t.value)
+			if d > tolerance(t.value) {
+				continue
+			}
+		}
+
+		if !found || d < best {
+			best, found = d, true
+		}
+	}
+
+	return best, found
+}
+
+// A candidate is a journal.Person which matches a query. The distance is the
+// sum of the edit distances of all query terms, so a distance of 0 means that
+// every term matches exactly after normalization.
+type candidate struct {
+	person   journal.Person
+	distance int
+}
+
+// matchPersons returns all persons which match the query.
+//
+// If at least one person matches exactly, only the exact matches are returned
+// in the order of the persons slice. Otherwise the fuzzy matches are returned
+// ranked by their distance, best first.
+func matchPersons(persons []journal.Person, query string) ([]candidate, error) {
+	terms, err := parseQuery(query)
+	if err != nil {
+		return nil, err
+	}
+
+	return bestCandidates(rankPersons(persons, terms)), nil
+}
+
+// bestCandidates returns only the exact matches of the ranked candidates if
+// there are any, otherwise all candidates.
+func bestCandidates(candidates []candidate) []candidate {
+	exact := 0
+	for exact < len(candidates) && candidates[exact].distance == 0 {
+		exact++
+	}
+
+	if exact > 0 {
+		return candidates[:exact]
+	}
+
+	return candidates
+}
+
+// nameTerms returns the terms which match a person by first and last name.
+// Other than a query string, the names may contain any character.
+func nameTerms(firstName, lastName string) []queryTerm {
+	return []queryTerm{{"firstname", normalize(firstName)}, {"lastname", normalize(lastName)}}
+}
+
+// rankPersons returns all persons which match every term, ranked by their
+// distance, best first. Persons with the same distance keep the order of the
+// persons slice.
+func rankPersons(persons []journal.Person, terms []queryTerm) []candidate {
+	candidates := make([]candidate, 0)
+
+loop:
+	for _, p := range persons {
+		total := 0
+		for _, t := range terms {
+			d, ok := t.distance(&p)
+			if !ok {
+				continue loop
+			}
+
+			total += d
+		}
+
+		candidates = append(candidates, candidate{p, total})
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].distance < candidates[j].distance
+	})
+
+	return candidates
+}
+
+// uniquePersons returns every journal.Person of the journals once, in the
+// order of their first appearance.
+func uniquePersons(journals ...journal.Journal) []journal.Person {
+	seen := make(map[journal.Person]bool)
+	persons := make([]journal.Person, 0)
+	for _, j := range journals {
+		for _, e := range j.Entries {
+			if !seen[e.Person] {
+				seen[e.Person] = true
+				persons = append(persons, e.Person)
+			}
+		}
+	}
+
+	return persons
+}
+
+// selectPerson returns the only journal.Person of the Journal j matching the
+// query. An error is returned if no or more than one person matches, in the
+// latter case the error message lists the ranked candidates.
+func selectPerson(j journal.Journal, query string) (journal.Person, error) {
+	return selectPersonFromJournals([]journal.Journal{j}, query)
+}
+
+// selectPersonFromJournals works like selectPerson but searches the persons of
+// all journals.
+func selectPersonFromJournals(journals []journal.Journal, query string) (journal.Person, error) {
+	candidates, err := matchPersons(uniquePersons(journals...), query)
+	if err != nil {
+		return journal.Person{}, err
+	}
+
+	return selectCandidate(candidates)
+}
+
+// selectCandidate returns the person of the only candidate. An error is
+// returned if there is no or more than one candidate, in the latter case the
+// error message lists the candidates.
+func selectCandidate(candidates []candidate) (journal.Person, error) {
+	if len(candidates) < 1 {
+		return journal.Person{}, fmt.Errorf("no person found matches this attributes")
+	}
+
+	if len(candidates) > 1 {
+		errMsg := "there are more than one person matching this attributes:\n"
+		for _, c := range candidates {
+			if c.distance > 0 {
+				errMsg += fmt.Sprintf("  %v (distance %v)\n", c.person.String(), c.distance)
+			} else {
+				errMsg += fmt.Sprintf("  %v\n", c.person.String())
+			}
+		}
+		errMsg += "add more search criterias"
+		return journal.Person{}, errors.New(errMsg)
+	}
+
+	return candidates[0].person, nil
+}
@@ -0,0 +1,134 @@
+// This source file is part of the attendance list project
+// as a part of the go lecture by H. Neemann.
+// For this reason you have no permission to use, modify or
+// share this code without the agreement of the authors.
+//
+// Matriculation numbers of the authors: 5703004, 5736465
+
+package main
+
+import (
+	"testing"
+
+	"github.com/dateiexplorer/attendancelist/internal/journal"
+	"github.com/dateiexplorer/attendancelist/internal/timeutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalize(t *testing.T) {
+	expected := "mueller"
+	for _, value := range []string{"Müller", "mueller", "Müller ", "MÜLLER", " Mueller\t"} {
+		assert.Equal(t, expected, normalize(value))
+	}
+
+	assert.Equal(t, "musterstrasse", normalize("Musterstraße"))
+	assert.Equal(t, "alte maelzerei", normalize("  Alte   Mälzerei "))
+}
+
+func TestLevenshtein(t *testing.T) {
+	assert.Equal(t, 0, levenshtein("meier", "meier"))
+	assert.Equal(t, 1, levenshtein("meier", "meyer"))
+	assert.Equal(t, 2, levenshtein("mustermann", "mustremann"))
+	assert.Equal(t, 3, levenshtein("", "max"))
+	assert.Equal(t, 1, levenshtein("müller", "muller"))
+}
+
+func TestParseQuery(t *testing.T) {
+	expected := []queryTerm{
+		{"lastname", "mueller"},
+		{"zip", "74722"},
+		{"", "hans"},
+	}
+
+	actual, err := parseQuery("lastName=Müller, zip = 74722,HANS")
+	assert.NoError(t, err)
+	assert.Equal(t, expected, actual)
+}
+
+func TestParseQueryUnknownField(t *testing.T) {
+	actual, err := parseQuery("lastName=Müller,birthday=01.01.1970")
+	assert.Error(t, err)
+	assert.Nil(t, actual)
+}
+
+func TestMatchPersonsNormalized(t *testing.T) {
+	j, err := journal.ReadJournal("testdata", timeutil.NewDate(2021, 10, 15))
+	assert.NoError(t, err)
+
+	expected := []candidate{
+		{journal.NewPerson("Hans", "Müller", "Feldweg", "12", "74722", "Buchen"), 0},
+		{journal.NewPerson("Lieschen", "Müller", "Lindenstraße", "15", "10115", "Berlin"), 0},
+	}
+
+	for _, query := range []string{"mueller", "Müller ", "MÜLLER"} {
+		candidates, err := matchPersons(uniquePersons(j), query)
+		assert.NoError(t, err)
+		assert.Equal(t, expected, candidates)
+	}
+}
+
+func TestMatchPersonsFieldQualified(t *testing.T) {
+	j, err := journal.ReadJournal("testdata", timeutil.NewDate(2021, 10, 15))
+	assert.NoError(t, err)
+
+	person, err := selectPerson(j, "lastName=Müller,zip=74722")
+	assert.NoError(t, err)
+	assert.Equal(t, journal.NewPerson("Hans", "Müller", "Feldweg", "12", "74722", "Buchen"), person)
+
+	// A zip code is never matched fuzzy.
+	candidates, err := matchPersons(uniquePersons(j), "lastName=Müller,zip=74723")
+	assert.NoError(t, err)
+	assert.Empty(t, candidates)
+
+	// A field-qualified value must not match other fields.
+	candidates, err = matchPersons(uniquePersons(j), "firstName=Müller")
+	assert.NoError(t, err)
+	assert.Empty(t, candidates)
+}
+
+func TestMatchPersonsFuzzy(t *testing.T) {
+	j, err := journal.ReadJournal("testdata", timeutil.NewDate(2021, 10, 15))
+	assert.NoError(t, err)
+
+	person, err := selectPerson(j, "Mustremann")
+	assert.NoError(t, err)
+	assert.Equal(t, journal.NewPerson("Max", "Mustermann", "Musterstraße", "20", "74821", "Mosbach"), person)
+
+	// Short values must match exactly.
+	candidates, err := matchPersons(uniquePersons(j), "Mex")
+	assert.NoError(t, err)
+	assert.Empty(t, candidates)
+}
+
+func TestMatchPersonsRanked(t *testing.T) {
+	persons := []journal.Person{
+		journal.NewPerson("Anne", "Meier", "Hauptstraße", "18", "74821", "Mosbach"),
+		journal.NewPerson("Anna", "Maier", "Hauptstraße", "18", "74821", "Mosbach"),
+		journal.NewPerson("Hans", "Müller", "Feldweg", "12", "74722", "Buchen"),
+	}
+
+	expected := []candidate{
+		{persons[1], 1},
+		{persons[0], 2},
+	}
+
+	actual, err := matchPersons(persons, "Anni,Maier")
+	assert.NoError(t, err)
+	assert.Equal(t, expected, actual)
+}
+
+func TestSelectPerson(t *testing.T) {
+	j, err := journal.ReadJournal("testdata", timeutil.NewDate(2021, 10, 15))
+	assert.NoError(t, err)
+
+	p, err := selectPerson(j, "lastname=mueller,city=buchen")
+	assert.NoError(t, err)
+	assert.Equal(t, journal.NewPerson("Hans", "Müller", "Feldweg", "12", "74722", "Buchen"), p)
+
+	_, err = selectPerson(j, "Müller")
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "Lieschen,Müller")
+
+	_, err = selectPerson(j, "street=Feldweg,birthday=today")
+	assert.Error(t, err)
+}
@@ -0,0 +1,187 @@
+// This source file is part of the attendance list project
+// as a part of the go lecture by H. Neemann.
+// For this reason you have no permission to use, modify or
+// share this code without the agreement of the authors.
+//
+// Matriculation numbers of the authors: 5703004, 5736465
+
+package main
+
+import (
+	"encoding/csv"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/dateiexplorer/attendancelist/internal/journal"
+)
+
+// A rosterEntry is an expected participant of a roster file.
+type rosterEntry struct {
+	FirstName string
+	LastName  string
+	StudentID string
+}
+
+// readRoster reads a roster from the CSV file at filePath with the columns
+//
+// firstName,lastName[,studentID]
+//
+// A first line which starts with "firstName" is treated as header and skipped.
+//
+// Returns an error if the file cannot be read or a line has not two or three
+// columns.
+func readRoster(filePath string) ([]rosterEntry, error) {
+	f, err := os.Open(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("cannot open roster file: %w", err)
+	}
+	defer f.Close()
+
+	reader := csv.NewReader(f)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("cannot parse roster: %w", err)
+	}
+
+	roster := make([]rosterEntry, 0, len(records))
+	for i, record := range records {
+		if len(record) < 2 || len(record) > 3 {
+			return nil, fmt.Errorf("cannot parse roster: line %v has %v columns, expected 2 or 3", i+1, len(record))
+		}
+
+		if i == 0 && strings.EqualFold(record[0], "firstname") {
+			continue
+		}
+
+		e := rosterEntry{FirstName: record[0], LastName: record[1]}
+		if len(record) == 3 {
+			e.StudentID = record[2]
+		}
+
+		roster = append(roster, e)
+	}
+
+	return roster, nil
+}
+
+// The status of a person in a reconciliation.
+const (
+	statusPresent    = "present"
+	statusAbsent     = "absent"
+	statusUnexpected = "unexpected"
+)
+
+// A reconciliationEntry is a person of a roster or a visitor of a location with
+// its status. Absent persons only have the attributes of the roster.
+type reconciliationEntry struct {
+	Status    string
+	StudentID string
+	Person    journal.Person
+}
+
+// A reconciliation compares a roster with the visitors of a location. It lists
+// all present, then all absent and then all unexpected persons.
+type reconciliation []reconciliationEntry
+
+// reconcile compares the roster with the visitors.
+//
+// Every roster entry is matched against the visitors by first and last name
+// with the normalized person matching, so different spellings and small typos
+// are tolerated. A visitor is assigned to one roster entry at most. The exact
+// matches of the whole roster are assigned first, so a fuzzy match never takes
+// the visitor of another entry which matches exactly. Visitors which aren't
+// assigned to any roster entry are unexpected.
+func reconcile(roster []rosterEntry, visitors []journal.Person) reconciliation {
+	candidates := make([][]candidate, len(roster))
+	for i, e := range roster {
+		candidates[i] = rankPersons(visitors, nameTerms(e.FirstName, e.LastName))
+	}
+
+	assigned := make(map[journal.Person]bool)
+	matches := make([]*journal.Person, len(roster))
+	assign := func(exact bool) {
+		for i := range roster {
+			if matches[i] != nil {
+				continue
+			}
+
+			for _, c := range candidates[i] {
+				if exact && c.distance > 0 {
+					break
+				}
+
+				if !assigned[c.person] {
+					assigned[c.person] = true
+					p := c.person
+					matches[i] = &p
+					break
+				}
+			}
+		}
+	}
+
+	assign(true)
+	assign(false)
+
+	present := reconciliation{}
+	absent := reconciliation{}
+	for i, e := range roster {
+		if matches[i] != nil {
+			present = append(present, reconciliationEntry{statusPresent, e.StudentID, *matches[i]})
+		} else {
+			absent = append(absent, reconciliationEntry{statusAbsent, e.StudentID, journal.Person{FirstName: e.FirstName, LastName: e.LastName}})
+		}
+	}
+
+	unexpected := reconciliation{}
+	for _, p := range visitors {
+		if !assigned[p] {
+			unexpected = append(unexpected, reconciliationEntry{Status: statusUnexpected, Person: p})
+		}
+	}
+
+	return append(append(present, absent...), unexpected...)
+}
+
+// Count returns the number of entries with the given status.
+func (r reconciliation) Count(status string) int {
+	count := 0
+	for _, e := range r {
+		if e.Status == status {
+			count++
+		}
+	}
+
+	return count
+}
+
+// NextEntry returns a read-only channel that loops through the hole
+// reconciliation and returns data of the entry as a string slice.
+//
+// Used to convert a reconciliation to any file format.
+func (r reconciliation) NextEntry() <-chan []string {
+	entries := make(chan []string)
+	go func() {
+		for _, e := range r {
+			p := e.Person
+			entries <- []string{e.Status, e.StudentID, p.FirstName, p.LastName,
+				p.Address.Street, p.Address.Number, p.Address.ZipCode, p.Address.City}
+		}
+
+		close(entries)
+	}()
+
+	return entries
+}
+
+// Header returns a string slice which describes the data given by the NextEntry
+// function.
+//
+// Used to convert a reconciliation to any file format.
+func (r reconciliation) Header() []string {
+	return []string{"Status", "StudentID", "FirstName", "LastName", "Street", "Number", "ZipCode", "City"}
+}
@@ -0,0 +1,118 @@
+// This source file is part of the attendance list project
+// as a part of the go lecture by H. Neemann.
+// For this reason you have no permission to use, modify or
+// share this code without the agreement of the authors.
+//
+// Matriculation numbers of the authors: 5703004, 5736465
+
+package main
+
+import (
+	"os"
+	"path"
+	"testing"
+
+	"github.com/dateiexplorer/attendancelist/internal/journal"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReadRoster(t *testing.T) {
+	expected := []rosterEntry{
+		{"Hans", "Mueller", "1001"},
+		{"Gisela", "Musterfrau", "1002"},
+		{"Anna", "Maier", "1003"},
+		{"Erika", "Mustermann", "1004"},
+	}
+
+	actual, err := readRoster("testdata/roster.csv")
+	assert.NoError(t, err)
+	assert.Equal(t, expected, actual)
+}
+
+func TestReadRosterWithoutHeaderAndStudentID(t *testing.T) {
+	filePath := path.Join(t.TempDir(), "roster.csv")
+	assert.NoError(t, os.WriteFile(filePath, []byte("Hans,Müller\nAnne,Meier,1005\n"), 0600))
+
+	actual, err := readRoster(filePath)
+	assert.NoError(t, err)
+	assert.Equal(t, []rosterEntry{{"Hans", "Müller", ""}, {"Anne", "Meier", "1005"}}, actual)
+}
+
+func TestReadRosterFailed(t *testing.T) {
+	_, err := readRoster("testdata/missing.csv")
+	assert.Error(t, err)
+
+	filePath := path.Join(t.TempDir(), "roster.csv")
+	assert.NoError(t, os.WriteFile(filePath, []byte("Hans\n"), 0600))
+	_, err = readRoster(filePath)
+	assert.Error(t, err)
+}
+
+func TestReconcile(t *testing.T) {
+	hans := journal.NewPerson("Hans", "Müller", "Feldweg", "12", "74722", "Buchen")
+	gisela := journal.NewPerson("Gisela", "Musterfrau", "Musterstraße", "10", "74821", "Mosbach")
+	anne := journal.NewPerson("Anne", "Meier", "Hauptstraße", "18", "74821", "Mosbach")
+	max := journal.NewPerson("Max", "Mustermann", "Musterstraße", "20", "74821", "Mosbach")
+
+	roster := []rosterEntry{
+		{"Hans", "Mueller", "1001"},
+		{"Gisela", "Musterfrau", "1002"},
+		{"Anna", "Maier", "1003"},
+		{"Erika", "Mustermann", "1004"},
+	}
+
+	expected := reconciliation{
+		{statusPresent, "1001", hans},
+		{statusPresent, "1002", gisela},
+		{statusPresent, "1003", anne},
+		{statusAbsent, "1004", journal.Person{FirstName: "Erika", LastName: "Mustermann"}},
+		{statusUnexpected, "", max},
+	}
+
+	actual := reconcile(roster, []journal.Person{hans, gisela, max, anne})
+	assert.Equal(t, expected, actual)
+	assert.Equal(t, 3, actual.Count(statusPresent))
+	assert.Equal(t, 1, actual.Count(statusAbsent))
+	assert.Equal(t, 1, actual.Count(statusUnexpected))
+}
+
+func TestReconcileAssignsVisitorOnce(t *testing.T) {
+	hans := journal.NewPerson("Hans", "Müller", "Feldweg", "12", "74722", "Buchen")
+	roster := []rosterEntry{{"Hans", "Müller", "1001"}, {"Hans", "Mueller", "1002"}}
+
+	actual := reconcile(roster, []journal.Person{hans})
+	assert.Equal(t, 1, actual.Count(statusPresent))
+	assert.Equal(t, 1, actual.Count(statusAbsent))
+	assert.Equal(t, "1002", actual[1].StudentID)
+}
+
+func TestReconcilePrefersExactMatches(t *testing.T) {
+	anna := journal.NewPerson("Anna", "Maier", "Hauptstraße", "18", "74821", "Mosbach")
+	roster := []rosterEntry{{"Anne", "Maier", "1001"}, {"Anna", "Maier", "1002"}}
+
+	// The fuzzy match of the first entry doesn't take the visitor of the exact
+	// match of the second entry.
+	actual := reconcile(roster, []journal.Person{anna})
+	assert.Equal(t, reconciliation{
+		{statusPresent, "1002", anna},
+		{statusAbsent, "1001", journal.Person{FirstName: "Anne", LastName: "Maier"}},
+	}, actual)
+}
+
+func TestReconcileNamesWithSeparators(t *testing.T) {
+	p := journal.NewPerson("Anne", "Meier=Schulz, geb. Braun", "Hauptstraße", "18", "74821", "Mosbach")
+	roster := []rosterEntry{{"Anne", "Meier=Schulz, geb. Braun", "1001"}}
+
+	actual := reconcile(roster, []journal.Person{p})
+	assert.Equal(t, reconciliation{{statusPresent, "1001", p}}, actual)
+}
+
+func TestReconciliationNextEntry(t *testing.T) {
+	r := reconciliation{{statusAbsent, "1004", journal.Person{FirstName: "Erika", LastName: "Mustermann"}}}
+
+	for actual := range r.NextEntry() {
+		assert.Equal(t, []string{"absent", "1004", "Erika", "Mustermann", "", "", "", ""}, actual)
+	}
+
+	assert.Equal(t, 8, len(r.Header()))
+}
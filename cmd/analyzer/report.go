@@ -0,0 +1,164 @@
+// This source file is part of the attendance list project
+// as a part of the go lecture by H. Neemann.
+// For this reason you have no permission to use, modify or
+// share this code without the agreement of the authors.
+//
+// Matriculation numbers of the authors: 5703004, 5736465
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/dateiexplorer/attendancelist/internal/convert"
+	"github.com/dateiexplorer/attendancelist/internal/journal"
+	"github.com/dateiexplorer/attendancelist/internal/report"
+)
+
+// The version of the analyzer which is written to the manifest of a report.
+const version = "1.0.0"
+
+// createReport writes a signed report bundle for the index case matching the
+// person query to filePath. The bundle holds the contacts of the index case,
+// the attendance lists of all locations the index case visited and a manifest
+// with the checksums of the journal files in the dir directory.
+//
+// The manifest is signed with the key pair in certFile and keyFile.
+func createReport(journals []journal.Journal, dir string, person string, dates string, certFile string, keyFile string, generatedAt time.Time, filePath string, format string) (string, error) {
+	convertTo, err := converterFor(format)
+	if err != nil {
+		return "", err
+	}
+
+	signer, err := report.LoadSigner(certFile, keyFile)
+	if err != nil {
+		return "", err
+	}
+
+	p, err := selectPersonFromJournals(journals, person)
+	if err != nil {
+		return "", err
+	}
+
+	ext := "." + format
+	if format == "" {
+		ext = ".csv"
+	}
+
+	encode := func(name string, c convert.Converter) (report.File, error) {
+		buf := new(bytes.Buffer)
+		if err := convertTo(buf, c); err != nil {
+			return report.File{}, fmt.Errorf("cannot convert %v: %w", name, err)
+		}
+
+		return report.File{Name: name + ext, Data: buf.Bytes()}, nil
+	}
+
+	m := report.Manifest{
+		IndexCase:   p.String(),
+		Query:       map[string]string{"person": person, "dates": dates, "format": format},
+		GeneratedAt: generatedAt.UTC(),
+		Software:    "attendancelist analyzer " + version,
+		Journals:    make([]report.Checksum, 0, len(journals)),
+	}
+
+	contacts := journal.ContactList{}
+	attendances := make([]report.File, 0)
+	for _, j := range journals {
+		c, err := report.ChecksumFile(journal.FilePath(dir, j.Date))
+		if err != nil {
+			return "", err
+		}
+
+		m.Journals = append(m.Journals, c)
+		contacts = append(contacts, j.GetContactsForPerson(&p)...)
+
+		locations := j.GetVisitedLocationsForPerson(&p)
+		sort.Slice(locations, func(a, b int) bool {
+			return locations[a] < locations[b]
+		})
+
+		for _, l := range locations {
+			f, err := encode(fmt.Sprintf("attendances/%v_%v", j.Date, l), j.GetAttendanceListForLocation(l))
+			if err != nil {
+				return "", err
+			}
+
+			attendances = append(attendances, f)
+		}
+	}
+
+	f, err := encode("contacts", contacts)
+	if err != nil {
+		return "", err
+	}
+
+	out, err := os.Create(filePath)
+	if err != nil {
+		return "", fmt.Errorf("cannot create file: %w", err)
+	}
+	defer out.Close()
+
+	if err := report.Write(out, m, append([]report.File{f}, attendances...), signer); err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("Report for %v successfully written to %v.\n", p.String(), filePath), nil
+}
+
+// verifyReport checks the integrity of the report bundle at filePath with the
+// certificate in certFile and returns a summary of its manifest.
+func verifyReport(filePath string, certFile string) (string, error) {
+	pub, err := report.LoadPublicKey(certFile)
+	if err != nil {
+		return "", err
+	}
+
+	f, err := os.Open(filePath)
+	if err != nil {
+		return "", fmt.Errorf("cannot open report: %w", err)
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return "", fmt.Errorf("cannot open report: %w", err)
+	}
+
+	m, err := report.Verify(f, info.Size(), pub)
+	if err != nil {
+		return "", fmt.Errorf("report is invalid: %w", err)
+	}
+
+	msg := "Report is valid.\n\n"
+	msg += fmt.Sprintf("Index case:   %v\n", m.IndexCase)
+	msg += fmt.Sprintf("Generated at: %v\n", m.GeneratedAt.Format(time.RFC3339))
+	msg += fmt.Sprintf("Software:     %v\n", m.Software)
+
+	keys := make([]string, 0, len(m.Query))
+	for k := range m.Query {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	msg += "Query:\n"
+	for _, k := range keys {
+		msg += fmt.Sprintf("  %v: %v\n", k, m.Query[k])
+	}
+
+	msg += "Journals:\n"
+	for _, c := range m.Journals {
+		msg += fmt.Sprintf("  %v %v\n", c.SHA256, c.Name)
+	}
+
+	msg += "Files:\n"
+	for _, c := range m.Files {
+		msg += fmt.Sprintf("  %v %v\n", c.SHA256, c.Name)
+	}
+
+	return msg, nil
+}
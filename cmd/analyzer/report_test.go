@@ -0,0 +1,88 @@
+// This source file is part of the attendance list project
+// as a part of the go lecture by H. Neemann.
+// For this reason you have no permission to use, modify or
+// share this code without the agreement of the authors.
+//
+// Matriculation numbers of the authors: 5703004, 5736465
+
+package main
+
+import (
+	"archive/zip"
+	"fmt"
+	"os"
+	"path"
+	"testing"
+	"time"
+
+	"github.com/dateiexplorer/attendancelist/internal/timeutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCreateAndVerifyReport(t *testing.T) {
+	journals, err := readJournals("testdata", timeutil.NewDate(2021, 10, 15), timeutil.NewDate(2021, 11, 30), readOptions{})
+	assert.NoError(t, err)
+
+	filePath := path.Join(t.TempDir(), "report.zip")
+	generatedAt := time.Date(2021, 12, 1, 8, 0, 0, 0, time.UTC)
+	msg, err := createReport(journals, "testdata", "Hans,Müller", "2021/10/15-2021/11/30", "testdata/cert.pem", "testdata/key.pem", generatedAt, filePath, "csv")
+	assert.NoError(t, err)
+	assert.Equal(t, fmt.Sprintf("Report for Hans,Müller,Feldweg,12,74722,Buchen successfully written to %v.\n", filePath), msg)
+
+	r, err := zip.OpenReader(filePath)
+	assert.NoError(t, err)
+	defer r.Close()
+
+	names := make([]string, 0, len(r.File))
+	for _, f := range r.File {
+		names = append(names, f.Name)
+	}
+
+	assert.Equal(t, []string{
+		"contacts.csv",
+		"attendances/2021-10-15_Alte Mälzerei.csv",
+		"attendances/2021-10-15_DHBW Mosbach.csv",
+		"attendances/2021-11-30_Alte Mälzerei.csv",
+		"attendances/2021-11-30_DHBW Mosbach.csv",
+		"manifest.json",
+		"manifest.sig",
+	}, names)
+
+	msg, err = verifyReport(filePath, "testdata/cert.pem")
+	assert.NoError(t, err)
+	assert.Contains(t, msg, "Report is valid.")
+	assert.Contains(t, msg, "Index case:   Hans,Müller,Feldweg,12,74722,Buchen")
+	assert.Contains(t, msg, "Generated at: 2021-12-01T08:00:00Z")
+	assert.Contains(t, msg, "  person: Hans,Müller")
+	assert.Contains(t, msg, " 2021-10-15.journal\n")
+	assert.Contains(t, msg, " 2021-11-30.journal\n")
+}
+
+func TestCreateReportFailed(t *testing.T) {
+	journals, err := readJournals("testdata", timeutil.NewDate(2021, 10, 15), timeutil.NewDate(2021, 10, 15), readOptions{})
+	assert.NoError(t, err)
+
+	filePath := path.Join(t.TempDir(), "report.zip")
+	_, err = createReport(journals, "testdata", "Hans,Müller", "2021/10/15", "testdata/cert.pem", "testdata/missing.pem", time.Now(), filePath, "csv")
+	assert.Error(t, err)
+
+	_, err = createReport(journals, "testdata", "Erika", "2021/10/15", "testdata/cert.pem", "testdata/key.pem", time.Now(), filePath, "csv")
+	assert.Error(t, err)
+
+	_, err = createReport(journals, "testdata", "Hans,Müller", "2021/10/15", "testdata/cert.pem", "testdata/key.pem", time.Now(), filePath, "xml")
+	assert.Error(t, err)
+}
+
+func TestVerifyReportFailed(t *testing.T) {
+	_, err := verifyReport("testdata/missing.zip", "testdata/cert.pem")
+	assert.Error(t, err)
+
+	// A journal file is no zip archive.
+	_, err = verifyReport("testdata/2021-10-15.journal", "testdata/cert.pem")
+	assert.Error(t, err)
+
+	filePath := path.Join(t.TempDir(), "report.zip")
+	assert.NoError(t, os.WriteFile(filePath, nil, 0600))
+	_, err = verifyReport(filePath, "testdata/missing.pem")
+	assert.Error(t, err)
+}
@@ -0,0 +1,324 @@
+// This source file is part of the attendance list project
+// as a part of the go lecture by H. Neemann.
+// For this reason you have no permission to use, modify or
+// share this code without the agreement of the authors.
+//
+// Matriculation numbers of the authors: 5703004, 5736465
+
+package main
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/dateiexplorer/attendancelist/internal/convert"
+	"github.com/dateiexplorer/attendancelist/internal/journal"
+	"github.com/dateiexplorer/attendancelist/internal/timeutil"
+)
+
+// A locationList is a collection of Locations.
+type locationList []journal.Location
+
+// newLocationList returns the Locations as locationList in alphabetical order.
+func newLocationList(locs []journal.Location) locationList {
+	l := locationList(locs)
+	sort.Slice(l, func(i, j int) bool {
+		return l[i] < l[j]
+	})
+
+	return l
+}
+
+// NextEntry returns a read-only channel that loops through the hole
+// locationList and returns the name of the Location as a string slice.
+//
+// Used to convert a locationList to any file format.
+func (l locationList) NextEntry() <-chan []string {
+	entries := make(chan []string)
+	go func() {
+		for _, loc := range l {
+			entries <- []string{string(loc)}
+		}
+
+		close(entries)
+	}()
+
+	return entries
+}
+
+// Header returns a string slice which describes the data given by the NextEntry
+// function.
+//
+// Used to convert a locationList to any file format.
+func (l locationList) Header() []string {
+	return []string{"Location"}
+}
+
+// A server answers the queries of the analyzer as JSON HTTP API over the
+// journal files in the dir directory.
+type server struct {
+	dir  string
+	opts readOptions
+	// tokens maps the SHA-256 hash of an API token to the name of its user.
+	tokens map[string]string
+	logger *log.Logger
+}
+
+// readTokens reads the API tokens from a file with a line "name:hash" for
+// every user, where hash is the hexadecimal SHA-256 hash of the token. Empty
+// lines and lines starting with "#" are ignored.
+func readTokens(path string) (map[string]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("cannot open tokens file: %w", err)
+	}
+	defer f.Close()
+
+	tokens := make(map[string]string)
+	scanner := bufio.NewScanner(f)
+	for i := 0; scanner.Scan(); i++ {
+		line := strings.TrimSpace(scanner.Text())
+		if len(line) == 0 || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		values := strings.Split(line, ":")
+		if len(values) != 2 || len(values[0]) == 0 {
+			return nil, fmt.Errorf("cannot parse tokens file on line %v: expected name:hash", i)
+		}
+
+		hash, err := hex.DecodeString(values[1])
+		if err != nil || len(hash) != sha256.Size {
+			return nil, fmt.Errorf("cannot parse tokens file on line %v: expected a hexadecimal SHA-256 hash", i)
+		}
+
+		tokens[hex.EncodeToString(hash)] = values[0]
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("cannot read tokens file: %w", err)
+	}
+
+	if len(tokens) == 0 {
+		return nil, errors.New("tokens file contains no tokens")
+	}
+
+	return tokens, nil
+}
+
+// handler returns the http.Handler of the API with authentication and request
+// logging.
+func (s *server) handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/locations", s.query(func(j journal.Journal, r *http.Request) (convert.Converter, error) {
+		p, err := selectPerson(j, r.URL.Query().Get("person"))
+		if err != nil {
+			return nil, err
+		}
+
+		return newLocationList(j.GetVisitedLocationsForPerson(&p)), nil
+	}))
+
+	mux.HandleFunc("/api/contacts", s.query(func(j journal.Journal, r *http.Request) (convert.Converter, error) {
+		p, err := selectPerson(j, r.URL.Query().Get("person"))
+		if err != nil {
+			return nil, err
+		}
+
+		return j.GetContactsForPerson(&p), nil
+	}))
+
+	mux.HandleFunc("/api/attendances", s.query(func(j journal.Journal, r *http.Request) (convert.Converter, error) {
+		location := r.URL.Query().Get("location")
+		if len(location) == 0 {
+			return nil, errors.New("the location must be set")
+		}
+
+		return j.GetAttendanceListForLocation(journal.Location(location)), nil
+	}))
+
+	mux.HandleFunc("/api/occupancy", s.query(func(j journal.Journal, r *http.Request) (convert.Converter, error) {
+		list := j.GetOccupancy()
+		if location := r.URL.Query().Get("location"); len(location) > 0 {
+			list = journal.OccupancyList{j.GetOccupancyForLocation(journal.Location(location))}
+		}
+
+		if r.URL.Query().Get("timeline") == "true" {
+			return list.Timeline(), nil
+		}
+
+		return list, nil
+	}))
+
+	return s.logRequests(s.authenticate(mux))
+}
+
+// query returns a http.HandlerFunc which reads the journal file for the "date"
+// parameter of the request, runs the query on it and writes the result in the
+// format of the "format" parameter, JSON by default.
+//
+// Errors of the query are caused by the parameters of the request.
+func (s *server) query(run func(j journal.Journal, r *http.Request) (convert.Converter, error)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			writeError(w, http.StatusMethodNotAllowed, errors.New("only GET requests are allowed"))
+			return
+		}
+
+		format := r.URL.Query().Get("format")
+		if len(format) == 0 {
+			format = "json"
+		}
+
+		write, err := converterFor(format)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+
+		date, err := timeutil.ParseDate(r.URL.Query().Get("date"))
+		if err != nil {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("the date must be set as YYYY/mm/dd: %w", err))
+			return
+		}
+
+		j, err := readJournal(s.dir, date, s.opts)
+		if err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				writeError(w, http.StatusNotFound, fmt.Errorf("no journal file for %v", date))
+				return
+			}
+
+			s.logger.Printf("cannot read journal file for %v: %v", date, err)
+			writeError(w, http.StatusInternalServerError, errors.New("cannot read journal file"))
+			return
+		}
+
+		c, err := run(j, r)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+
+		if format == "csv" {
+			w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+		} else {
+			w.Header().Set("Content-Type", "application/json")
+		}
+
+		if err := write(w, c); err != nil {
+			s.logger.Printf("cannot write response: %v", err)
+		}
+	}
+}
+
+// authenticate only passes requests with a valid API token in the
+// "Authorization: Bearer <token>" header to the next handler.
+func (s *server) authenticate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header := r.Header.Get("Authorization")
+		token := strings.TrimPrefix(header, "Bearer ")
+		sum := sha256.Sum256([]byte(token))
+		hash := hex.EncodeToString(sum[:])
+
+		// Compare every hash to not leak which hashes exist by timing.
+		var user string
+		for h, name := range s.tokens {
+			if subtle.ConstantTimeCompare([]byte(h), []byte(hash)) == 1 {
+				user = name
+			}
+		}
+
+		if !strings.HasPrefix(header, "Bearer ") || len(token) == 0 || len(user) == 0 {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			writeError(w, http.StatusUnauthorized, errors.New("a valid API token is required"))
+			return
+		}
+
+		// The user is only logged if the request passed logRequests before.
+		if rec, ok := w.(*statusRecorder); ok {
+			rec.user = user
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// A statusRecorder records the status code written to a http.ResponseWriter
+// and the name of the authenticated user of the request.
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+	user   string
+}
+
+// WriteHeader records the status code and writes it to the underlying
+// http.ResponseWriter.
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+// logRequests logs the remote address, the user, the request, the status code
+// and the duration of every request.
+func (s *server) logRequests(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		rec := &statusRecorder{w, http.StatusOK, "-"}
+		next.ServeHTTP(rec, r)
+		s.logger.Printf("%v %v %v %v %v %v", r.RemoteAddr, rec.user, r.Method, redactedURI(r.URL), rec.status, time.Since(start))
+	})
+}
+
+// redactedURI returns the request URI of u with the value of the person
+// parameter replaced by "-", so no name or address is written to the log.
+func redactedURI(u *url.URL) string {
+	if len(u.RawQuery) == 0 {
+		return u.EscapedPath()
+	}
+
+	params := strings.Split(u.RawQuery, "&")
+	for i, param := range params {
+		key := strings.SplitN(param, "=", 2)[0]
+		if name, err := url.QueryUnescape(key); err == nil && name == "person" {
+			params[i] = key + "=-"
+		}
+	}
+
+	return u.EscapedPath() + "?" + strings.Join(params, "&")
+}
+
+// writeError writes the error err as JSON object with the status code.
+func writeError(w http.ResponseWriter, status int, err error) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(struct {
+		Error string `json:"error"`
+	}{err.Error()})
+}
+
+// serve runs the API with the journal files of the dir directory on the port
+// with HTTPS. Every request must be authenticated with a token of the tokens
+// file.
+func serve(dir string, opts readOptions, tokensPath string, port int, certPath string, keyPath string, logger *log.Logger) error {
+	tokens, err := readTokens(tokensPath)
+	if err != nil {
+		return err
+	}
+
+	s := &server{dir, opts, tokens, logger}
+	logger.Printf("serving the journal files of %v on port %v", dir, port)
+	return http.ListenAndServeTLS(fmt.Sprintf(":%v", port), certPath, keyPath, s.handler())
+}
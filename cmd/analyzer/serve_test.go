@@ -0,0 +1,148 @@
+// This source file is part of the attendance list project
+// as a part of the go lecture by H. Neemann.
+// For this reason you have no permission to use, modify or
+// share this code without the agreement of the authors.
+//
+// Matriculation numbers of the authors: 5703004, 5736465
+
+package main
+
+import (
+	"bytes"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// The SHA-256 hash of the token "secret"
+const secretHash = "2bb80d537b1da3e38bd30361aa855686bde0eacd7162fef6a25fe97bf527a25b"
+
+// testServer returns a server for the testdata directory and the buffer of its
+// request log.
+func testServer() (*server, *bytes.Buffer) {
+	var buf bytes.Buffer
+	return &server{"testdata", readOptions{}, map[string]string{secretHash: "alice"}, log.New(&buf, "", 0)}, &buf
+}
+
+// get performs a GET request with the token on the handler of the server.
+func get(s *server, path string, token string) *httptest.ResponseRecorder {
+	r := httptest.NewRequest("GET", path, nil)
+	if len(token) > 0 {
+		r.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	w := httptest.NewRecorder()
+	s.handler().ServeHTTP(w, r)
+	return w
+}
+
+func TestReadTokens(t *testing.T) {
+	dir := t.TempDir()
+	filePath := path.Join(dir, "tokens")
+	assert.NoError(t, os.WriteFile(filePath, []byte("# users\n\nalice:"+strings.ToUpper(secretHash)+"\n"), 0600))
+
+	tokens, err := readTokens(filePath)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{secretHash: "alice"}, tokens)
+
+	for _, content := range []string{"", "alice\n", "alice:1234\n", ":" + secretHash + "\n"} {
+		assert.NoError(t, os.WriteFile(filePath, []byte(content), 0600))
+		_, err := readTokens(filePath)
+		assert.Error(t, err, content)
+	}
+
+	_, err = readTokens(path.Join(dir, "missing"))
+	assert.Error(t, err)
+}
+
+func TestServeAuthentication(t *testing.T) {
+	s, logs := testServer()
+
+	w := get(s, "/api/occupancy?date=2021/10/15", "")
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.Equal(t, "Bearer", w.Header().Get("WWW-Authenticate"))
+
+	w = get(s, "/api/occupancy?date=2021/10/15", "wrong")
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+
+	w = get(s, "/api/occupancy?date=2021/10/15", "secret")
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	lines := strings.Split(strings.TrimSpace(logs.String()), "\n")
+	assert.Equal(t, 3, len(lines))
+	assert.Contains(t, lines[0], " - GET /api/occupancy?date=2021/10/15 401 ")
+	assert.Contains(t, lines[2], " alice GET /api/occupancy?date=2021/10/15 200 ")
+}
+
+func TestServeAuthenticationWithoutLog(t *testing.T) {
+	s, _ := testServer()
+	handler := s.authenticate(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+
+	r := httptest.NewRequest("GET", "/api/occupancy?date=2021/10/15", nil)
+	r.Header.Set("Authorization", "Bearer secret")
+	w := httptest.NewRecorder()
+	assert.NotPanics(t, func() { handler.ServeHTTP(w, r) })
+	assert.Equal(t, http.StatusNoContent, w.Code)
+}
+
+func TestServeQueries(t *testing.T) {
+	s, logs := testServer()
+
+	w := get(s, "/api/locations?date=2021/10/15&person="+url.QueryEscape("Hans,Müller"), "secret")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+	assert.JSONEq(t, `[{"Location":"Alte Mälzerei"},{"Location":"DHBW Mosbach"}]`, w.Body.String())
+
+	w = get(s, "/api/locations?date=2021/10/15&format=csv&person="+url.QueryEscape("Hans,Müller"), "secret")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "text/csv; charset=utf-8", w.Header().Get("Content-Type"))
+	assert.Equal(t, "Location\nAlte Mälzerei\nDHBW Mosbach\n", w.Body.String())
+
+	w = get(s, "/api/contacts?date=2021/10/15&format=csv&person="+url.QueryEscape("Hans,Müller"), "secret")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.True(t, strings.HasPrefix(w.Body.String(), "FirstName,LastName,Street,Number,ZipCode,City,Location,Start,End,Duration\n"))
+
+	// The queried person isn't written to the log.
+	assert.NotContains(t, logs.String(), "Hans")
+	assert.Contains(t, logs.String(), " alice GET /api/contacts?date=2021/10/15&format=csv&person=- 200 ")
+
+	w = get(s, "/api/attendances?date=2021/10/15&format=csv&location="+url.QueryEscape("Alte Mälzerei"), "secret")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "Otto,Normalverbraucher")
+
+	w = get(s, "/api/occupancy?date=2021/10/15&timeline=true&location="+url.QueryEscape("DHBW Mosbach"), "secret")
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestServeQueryErrors(t *testing.T) {
+	s, _ := testServer()
+
+	for path, status := range map[string]int{
+		"/api/occupancy": http.StatusBadRequest,
+		"/api/occupancy?date=2021/10/15&format=xml":          http.StatusBadRequest,
+		"/api/occupancy?date=2021/10/16":                     http.StatusNotFound,
+		"/api/attendances?date=2021/10/15":                   http.StatusBadRequest,
+		"/api/contacts?date=2021/10/15&person=Nobody":        http.StatusBadRequest,
+		"/api/locations?date=2021/10/15&person=unknown=Hans": http.StatusBadRequest,
+		"/api/unknown?date=2021/10/15":                       http.StatusNotFound,
+	} {
+		w := get(s, path, "secret")
+		assert.Equal(t, status, w.Code, path)
+	}
+
+	r := httptest.NewRequest("POST", "/api/occupancy?date=2021/10/15", nil)
+	r.Header.Set("Authorization", "Bearer secret")
+	w := httptest.NewRecorder()
+	s.handler().ServeHTTP(w, r)
+	assert.Equal(t, http.StatusMethodNotAllowed, w.Code)
+	assert.JSONEq(t, `{"error":"only GET requests are allowed"}`, w.Body.String())
+}
@@ -0,0 +1,348 @@
+// This source file is part of the attendance list project
+// as a part of the go lecture by H. Neemann.
+// For this reason you have no permission to use, modify or
+// share this code without the agreement of the authors.
+//
+// Matriculation numbers of the authors: 5703004, 5736465
+
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/dateiexplorer/attendancelist/internal/convert"
+	"github.com/dateiexplorer/attendancelist/internal/journal"
+	"github.com/dateiexplorer/attendancelist/internal/timeutil"
+	"golang.org/x/term"
+)
+
+// The commands of the shell with their arguments and descriptions.
+var shellCommands = []struct{ name, args, description string }{
+	{"find", "<person>", "search a person, selects the person if only one matches"},
+	{"select", "<number>", "select a person of the last search"},
+	{"date", "[YYYY/mm/dd]", "print or select the date for attendances"},
+	{"locations", "", "print the locations the selected person visited"},
+	{"contacts", "", "print the contacts of the selected person"},
+	{"attendances", "<location>", "print the attendance list of a location on the selected date"},
+	{"export", "<file>", "write the last result to a file, JSON if it ends with .json, otherwise CSV"},
+	{"history", "", "print the command history"},
+	{"help", "", "print this help"},
+	{"exit", "", "leave the shell"},
+}
+
+// errExit is returned by execute if the shell should be left.
+var errExit = errors.New("exit")
+
+// A shell answers commands on journals which are loaded once. It remembers
+// the selected person and date between the commands.
+type shell struct {
+	journals []journal.Journal
+	// date is the index of the selected journal.
+	date       int
+	person     *journal.Person
+	candidates []journal.Person
+	// last is the result of the last query for the export command.
+	last    convert.Converter
+	history []string
+}
+
+// newShell returns a shell on the journals, the last journal is selected.
+func newShell(journals []journal.Journal) *shell {
+	return &shell{journals: journals, date: len(journals) - 1}
+}
+
+// prompt returns the prompt which shows the selected person and date.
+func (s *shell) prompt() string {
+	p := "analyzer"
+	if s.person != nil {
+		p += fmt.Sprintf(" [%v %v]", s.person.FirstName, s.person.LastName)
+	}
+
+	if s.date >= 0 {
+		p += " " + s.journals[s.date].Date.String()
+	}
+
+	return p + "> "
+}
+
+// execute runs the command line and writes the output to out. Returns errExit
+// if the shell should be left.
+func (s *shell) execute(line string, out io.Writer) error {
+	line = strings.TrimSpace(line)
+	if len(line) == 0 {
+		return nil
+	}
+
+	s.history = append(s.history, line)
+	command, arg := line, ""
+	if i := strings.Index(line, " "); i >= 0 {
+		command, arg = line[:i], strings.TrimSpace(line[i+1:])
+	}
+
+	switch command {
+	case "find":
+		return s.find(arg, out)
+	case "select":
+		n, err := strconv.Atoi(arg)
+		if err != nil || n < 1 || n > len(s.candidates) {
+			return fmt.Errorf("select a number between 1 and %v", len(s.candidates))
+		}
+
+		s.person = &s.candidates[n-1]
+		return nil
+	case "date":
+		return s.selectDate(arg, out)
+	case "locations":
+		if s.person == nil {
+			return errors.New("no person selected, use find first")
+		}
+
+		return s.show(s.locations(), out)
+	case "contacts":
+		if s.person == nil {
+			return errors.New("no person selected, use find first")
+		}
+
+		contacts := journal.ContactList{}
+		for _, j := range s.journals {
+			contacts = append(contacts, j.GetContactsForPerson(s.person)...)
+		}
+
+		return s.show(contacts, out)
+	case "attendances":
+		if len(arg) == 0 {
+			return errors.New("the location must be set")
+		}
+
+		if s.date < 0 {
+			return errors.New("no journal loaded")
+		}
+
+		return s.show(s.journals[s.date].GetAttendanceListForLocation(journal.Location(arg)), out)
+	case "export":
+		return s.export(arg, out)
+	case "history":
+		for i, h := range s.history {
+			fmt.Fprintf(out, "%4d  %v\n", i+1, h)
+		}
+
+		return nil
+	case "help":
+		for _, c := range shellCommands {
+			fmt.Fprintf(out, "  %-24v %v\n", c.name+" "+c.args, c.description)
+		}
+
+		return nil
+	case "exit", "quit":
+		return errExit
+	default:
+		return fmt.Errorf("unknown command \"%v\", type help for a list of commands", command)
+	}
+}
+
+// find searches the persons of all journals. A single match is selected,
+// multiple matches are listed for the select command.
+func (s *shell) find(query string, out io.Writer) error {
+	candidates, err := matchPersons(uniquePersons(s.journals...), query)
+	if err != nil {
+		return err
+	}
+
+	s.candidates = make([]journal.Person, 0, len(candidates))
+	for _, c := range candidates {
+		s.candidates = append(s.candidates, c.person)
+	}
+
+	switch len(s.candidates) {
+	case 0:
+		return errors.New("no person found matches this attributes")
+	case 1:
+		s.person = &s.candidates[0]
+		fmt.Fprintf(out, "Selected %v\n", s.person.String())
+	default:
+		for i, p := range s.candidates {
+			fmt.Fprintf(out, "%4d  %v\n", i+1, p.String())
+		}
+		fmt.Fprintln(out, "Use select <number> to choose a person.")
+	}
+
+	return nil
+}
+
+// selectDate prints the loaded dates or selects the journal of the date.
+func (s *shell) selectDate(arg string, out io.Writer) error {
+	if len(arg) == 0 {
+		for i, j := range s.journals {
+			mark := " "
+			if i == s.date {
+				mark = "*"
+			}
+
+			fmt.Fprintf(out, "%v %v\n", mark, j.Date)
+		}
+
+		return nil
+	}
+
+	date, err := timeutil.ParseDate(arg)
+	if err != nil {
+		return err
+	}
+
+	for i, j := range s.journals {
+		if j.Date == date {
+			s.date = i
+			return nil
+		}
+	}
+
+	return fmt.Errorf("no journal loaded for %v", date)
+}
+
+// locations returns the locations the selected person visited on all dates in
+// alphabetical order.
+func (s *shell) locations() locationList {
+	seen := make(map[journal.Location]bool)
+	locs := []journal.Location{}
+	for _, j := range s.journals {
+		for _, l := range j.GetVisitedLocationsForPerson(s.person) {
+			if !seen[l] {
+				seen[l] = true
+				locs = append(locs, l)
+			}
+		}
+	}
+
+	return newLocationList(locs)
+}
+
+// show writes the result c as CSV to out and remembers it for the export
+// command.
+func (s *shell) show(c convert.Converter, out io.Writer) error {
+	s.last = c
+	return convert.ToCSV(out, c)
+}
+
+// export writes the last result to the file at filePath.
+func (s *shell) export(filePath string, out io.Writer) error {
+	if s.last == nil {
+		return errors.New("nothing to export, run a query first")
+	}
+
+	if len(filePath) == 0 {
+		return errors.New("the file must be set")
+	}
+
+	format := "csv"
+	if path.Ext(filePath) == ".json" {
+		format = "json"
+	}
+
+	msg, err := writeOutput(s.last, filePath, format)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprint(out, msg)
+	return nil
+}
+
+// complete returns the possible completions of the line: command names for the
+// first word, person names for find and location names for attendances.
+func (s *shell) complete(line string) []string {
+	var prefix string
+	var words []string
+	switch {
+	case strings.HasPrefix(line, "find "):
+		prefix = "find "
+		seen := make(map[string]bool)
+		for _, p := range uniquePersons(s.journals...) {
+			name := p.FirstName + "," + p.LastName
+			if !seen[name] {
+				seen[name] = true
+				words = append(words, name)
+			}
+		}
+	case strings.HasPrefix(line, "attendances "):
+		prefix = "attendances "
+		seen := make(map[journal.Location]bool)
+		for _, j := range s.journals {
+			for _, e := range j.Entries {
+				if !seen[e.Location] {
+					seen[e.Location] = true
+					words = append(words, string(e.Location))
+				}
+			}
+		}
+	case !strings.Contains(line, " "):
+		for _, c := range shellCommands {
+			// Commands with arguments are completed with a space, so the
+			// arguments can be completed right away.
+			if len(c.args) > 0 {
+				words = append(words, c.name+" ")
+			} else {
+				words = append(words, c.name)
+			}
+		}
+	}
+
+	arg := strings.TrimPrefix(line, prefix)
+	completions := make([]string, 0)
+	for _, w := range words {
+		if strings.HasPrefix(strings.ToLower(w), strings.ToLower(arg)) {
+			completions = append(completions, prefix+w)
+		}
+	}
+
+	sort.Strings(completions)
+	return completions
+}
+
+// A lineReader reads the command lines of the shell.
+type lineReader interface {
+	ReadLine(prompt string) (string, error)
+}
+
+// newLineReader returns a rawReader with history and completion by the shell s
+// if in is a terminal, otherwise a plainReader.
+func newLineReader(s *shell, in *os.File, out io.Writer) lineReader {
+	fd := int(in.Fd())
+	if !term.IsTerminal(fd) {
+		return plainReader{bufio.NewScanner(in), out}
+	}
+
+	return rawReader{fd, newLineEditor(in, out, s.complete)}
+}
+
+// runShell runs the shell s until the input ends or the exit command is
+// executed.
+func runShell(s *shell, in lineReader, out io.Writer) error {
+	fmt.Fprintf(out, "%v journal files loaded, type help for a list of commands.\n", len(s.journals))
+	for {
+		line, err := in.ReadLine(s.prompt())
+		if err == io.EOF {
+			fmt.Fprintln(out)
+			return nil
+		}
+
+		if err != nil {
+			return err
+		}
+
+		if err := s.execute(line, out); err != nil {
+			if err == errExit {
+				return nil
+			}
+
+			fmt.Fprintln(out, err.Error())
+		}
+	}
+}
@@ -0,0 +1,176 @@
+// This source file is part of the attendance list project
+// as a part of the go lecture by H. Neemann.
+// For this reason you have no permission to use, modify or
+// share this code without the agreement of the authors.
+//
+// Matriculation numbers of the authors: 5703004, 5736465
+
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"os"
+	"path"
+	"strings"
+	"testing"
+
+	"github.com/dateiexplorer/attendancelist/internal/timeutil"
+	"github.com/stretchr/testify/assert"
+)
+
+// testShell returns a shell on the journals of the testdata directory.
+func testShell(t *testing.T) *shell {
+	journals, err := readJournals("testdata", timeutil.NewDate(2021, 10, 15), timeutil.NewDate(2021, 11, 30), readOptions{})
+	assert.NoError(t, err)
+	return newShell(journals)
+}
+
+func TestShellFindKeepsPerson(t *testing.T) {
+	s := testShell(t)
+	var out bytes.Buffer
+
+	assert.NoError(t, s.execute("find Hans,Müller", &out))
+	assert.Equal(t, "Selected Hans,Müller,Feldweg,12,74722,Buchen\n", out.String())
+	assert.Equal(t, "analyzer [Hans Müller] 2021-11-30> ", s.prompt())
+
+	out.Reset()
+	assert.NoError(t, s.execute("locations", &out))
+	assert.Equal(t, "Location\nAlte Mälzerei\nDHBW Mosbach\n", out.String())
+
+	out.Reset()
+	assert.NoError(t, s.execute("contacts", &out))
+	assert.True(t, strings.HasPrefix(out.String(), "FirstName,"))
+	assert.Contains(t, out.String(), "Gisela,Musterfrau")
+}
+
+func TestShellFindMultiplePersons(t *testing.T) {
+	s := testShell(t)
+	var out bytes.Buffer
+
+	assert.NoError(t, s.execute("find Müller", &out))
+	assert.Nil(t, s.person)
+	assert.Equal(t, "   1  Hans,Müller,Feldweg,12,74722,Buchen\n"+
+		"   2  Lieschen,Müller,Lindenstraße,15,10115,Berlin\n"+
+		"Use select <number> to choose a person.\n", out.String())
+
+	assert.Error(t, s.execute("select 3", &out))
+	assert.NoError(t, s.execute("select 2", &out))
+	assert.Equal(t, "Lieschen", s.person.FirstName)
+}
+
+func TestShellCommandsWithoutPerson(t *testing.T) {
+	s := testShell(t)
+	var out bytes.Buffer
+
+	assert.Error(t, s.execute("contacts", &out))
+	assert.Error(t, s.execute("locations", &out))
+	assert.Error(t, s.execute("export out.csv", &out))
+	assert.Error(t, s.execute("unknown", &out))
+	assert.Equal(t, errExit, s.execute("exit", &out))
+}
+
+func TestShellAttendancesAndExport(t *testing.T) {
+	s := testShell(t)
+	var out bytes.Buffer
+
+	assert.Error(t, s.execute("date 2021/10/16", &out))
+	assert.NoError(t, s.execute("date 2021/10/15", &out))
+	assert.NoError(t, s.execute("attendances Alte Mälzerei", &out))
+	assert.Equal(t, "FirstName,LastName,Street,Number,ZipCode,City,Login,Logout\n"+
+		"Hans,Müller,Feldweg,12,74722,Buchen,13:40:11,\n"+
+		"Otto,Normalverbraucher,Dieselstraße,52,70376,Stuttgart,17:32:45,19:15:12\n", out.String())
+
+	filePath := path.Join(t.TempDir(), "attendances.json")
+	assert.NoError(t, s.execute("export "+filePath, &out))
+	content, err := os.ReadFile(filePath)
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(string(content), "["))
+	assert.Contains(t, string(content), "Normalverbraucher")
+
+	out.Reset()
+	assert.NoError(t, s.execute("history", &out))
+	assert.Equal(t, "   1  date 2021/10/16\n   2  date 2021/10/15\n   3  attendances Alte Mälzerei\n"+
+		"   4  export "+filePath+"\n   5  history\n", out.String())
+}
+
+func TestShellComplete(t *testing.T) {
+	s := testShell(t)
+
+	assert.Equal(t, []string{"contacts"}, s.complete("con"))
+	assert.Equal(t, []string{"attendances "}, s.complete("att"))
+	assert.Equal(t, []string{"attendances Alte Mälzerei"}, s.complete("attendances al"))
+	assert.Equal(t, []string{"find Hans,Müller"}, s.complete("find h"))
+	assert.Equal(t, []string{}, s.complete("contacts x"))
+}
+
+// plainInput returns a plainReader on the lines.
+func plainInput(lines ...string) plainReader {
+	return plainReader{bufio.NewScanner(strings.NewReader(strings.Join(lines, "\n"))), io.Discard}
+}
+
+func TestRunShell(t *testing.T) {
+	var out bytes.Buffer
+	assert.NoError(t, runShell(testShell(t), plainInput("find Otto", "unknown", "exit", "find Hans"), &out))
+	assert.Equal(t, "2 journal files loaded, type help for a list of commands.\n"+
+		"Selected Otto,Normalverbraucher,Dieselstraße,52,70376,Stuttgart\n"+
+		"unknown command \"unknown\", type help for a list of commands\n", out.String())
+
+	out.Reset()
+	assert.NoError(t, runShell(testShell(t), plainInput(), &out))
+	assert.Equal(t, "2 journal files loaded, type help for a list of commands.\n\n", out.String())
+}
+
+func TestLineEditorEditing(t *testing.T) {
+	e := newLineEditor(strings.NewReader("fund\x1b[D\x1b[D\x7fi\x05 Otto\r"), io.Discard, nil)
+	line, err := e.ReadLine("> ")
+	assert.NoError(t, err)
+	assert.Equal(t, "find Otto", line)
+
+	_, err = e.ReadLine("> ")
+	assert.Equal(t, io.EOF, err)
+}
+
+func TestLineEditorHistory(t *testing.T) {
+	e := newLineEditor(strings.NewReader("first\rsecond\rthi\x1b[A\x1b[A\x1b[B\rfor\x1b[A\x1b[B\x1b[B\r"), io.Discard, nil)
+	for _, expected := range []string{"first", "second", "second", "for"} {
+		line, err := e.ReadLine("> ")
+		assert.NoError(t, err)
+		assert.Equal(t, expected, line)
+	}
+}
+
+func TestLineEditorInterruptAndEOF(t *testing.T) {
+	e := newLineEditor(strings.NewReader("abc\x03def\r\x04"), io.Discard, nil)
+	line, err := e.ReadLine("> ")
+	assert.NoError(t, err)
+	assert.Equal(t, "def", line)
+
+	_, err = e.ReadLine("> ")
+	assert.Equal(t, io.EOF, err)
+}
+
+func TestLineEditorComplete(t *testing.T) {
+	complete := func(line string) []string {
+		all := []string{"attendances", "at", "contacts"}
+		completions := []string{}
+		for _, c := range all {
+			if strings.HasPrefix(c, line) {
+				completions = append(completions, c)
+			}
+		}
+
+		return completions
+	}
+
+	var out bytes.Buffer
+	e := newLineEditor(strings.NewReader("co\t\rat\t\ratt\t\r"), &out, complete)
+	for _, expected := range []string{"contacts", "at", "attendances"} {
+		line, err := e.ReadLine("> ")
+		assert.NoError(t, err)
+		assert.Equal(t, expected, line)
+	}
+
+	assert.Contains(t, out.String(), "\r\nattendances  at\r\n")
+}
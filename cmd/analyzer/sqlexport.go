@@ -0,0 +1,370 @@
+// This source file is part of the attendance list project
+// as a part of the go lecture by H. Neemann.
+// For this reason you have no permission to use, modify or
+// share this code without the agreement of the authors.
+//
+// Matriculation numbers of the authors: 5703004, 5736465
+
+package main
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/dateiexplorer/attendancelist/internal/journal"
+	"github.com/dateiexplorer/attendancelist/internal/timeutil"
+
+	// The pure Go SQLite driver registers itself as "sqlite".
+	_ "modernc.org/sqlite"
+)
+
+// sqlSchema creates the tables of the SQL export if they don't exist yet.
+//
+// The days table holds the modification time of every exported journal file
+// and the journal.Sources it was read with, so that only new or changed days
+// are exported again. Persons and locations are shared by all days, sessions
+// and events belong to one day.
+const sqlSchema = `CREATE TABLE IF NOT EXISTS days (
+    date TEXT PRIMARY KEY,
+    journal_modified TEXT NOT NULL,
+    mapping_modified TEXT NOT NULL,
+    registry_size INTEGER NOT NULL,
+    registry_hash TEXT NOT NULL
+);
+CREATE TABLE IF NOT EXISTS persons (
+    id INTEGER PRIMARY KEY,
+    first_name TEXT NOT NULL,
+    last_name TEXT NOT NULL,
+    street TEXT NOT NULL,
+    number TEXT NOT NULL,
+    zip_code TEXT NOT NULL,
+    city TEXT NOT NULL,
+    UNIQUE (first_name, last_name, street, number, zip_code, city)
+);
+CREATE TABLE IF NOT EXISTS locations (
+    id INTEGER PRIMARY KEY,
+    name TEXT NOT NULL UNIQUE
+);
+CREATE TABLE IF NOT EXISTS sessions (
+    id INTEGER PRIMARY KEY,
+    date TEXT NOT NULL REFERENCES days (date),
+    session_id TEXT NOT NULL,
+    person_id INTEGER NOT NULL REFERENCES persons (id),
+    location_id INTEGER NOT NULL REFERENCES locations (id),
+    login TEXT,
+    logout TEXT
+);
+CREATE TABLE IF NOT EXISTS events (
+    id INTEGER PRIMARY KEY,
+    date TEXT NOT NULL REFERENCES days (date),
+    timestamp TEXT NOT NULL,
+    session_id TEXT NOT NULL,
+    event TEXT NOT NULL CHECK (event IN ('login', 'logout')),
+    person_id INTEGER NOT NULL REFERENCES persons (id),
+    location_id INTEGER NOT NULL REFERENCES locations (id)
+);
+CREATE INDEX IF NOT EXISTS sessions_date ON sessions (date);
+CREATE INDEX IF NOT EXISTS events_date ON events (date);
+`
+
+// The layout of timestamps in the SQL export, understood by the date and time
+// functions of SQLite.
+const sqlTimestampFormat = "2006-01-02 15:04:05"
+
+// sqlTimestamp returns the Timestamp t in the layout of the SQL export or nil,
+// which is stored as NULL, if t is the InvalidTimestamp.
+func sqlTimestamp(t timeutil.Timestamp) interface{} {
+	if t == timeutil.InvalidTimestamp {
+		return nil
+	}
+
+	return t.Format(sqlTimestampFormat)
+}
+
+// sqlStatements are the prepared statements which export a day.
+type sqlStatements struct {
+	deleteEvents    *sql.Stmt
+	deleteSessions  *sql.Stmt
+	replaceDay      *sql.Stmt
+	insertLocation  *sql.Stmt
+	selectLocation  *sql.Stmt
+	insertPerson    *sql.Stmt
+	selectPerson    *sql.Stmt
+	insertSession   *sql.Stmt
+	insertEvent     *sql.Stmt
+	deletePersons   *sql.Stmt
+	deleteLocations *sql.Stmt
+}
+
+// prepareSQL prepares the statements which export a day on the database db.
+// The statements must be closed after use, also if an error is returned.
+func prepareSQL(db *sql.DB) (*sqlStatements, error) {
+	s := &sqlStatements{}
+	queries := []struct {
+		stmt  **sql.Stmt
+		query string
+	}{
+		{&s.deleteEvents, "DELETE FROM events WHERE date = ?"},
+		{&s.deleteSessions, "DELETE FROM sessions WHERE date = ?"},
+		{&s.replaceDay, "INSERT OR REPLACE INTO days (date, journal_modified, mapping_modified, registry_size, registry_hash) VALUES (?, ?, ?, ?, ?)"},
+		{&s.insertLocation, "INSERT OR IGNORE INTO locations (name) VALUES (?)"},
+		{&s.selectLocation, "SELECT id FROM locations WHERE name = ?"},
+		{&s.insertPerson, "INSERT OR IGNORE INTO persons (first_name, last_name, street, number, zip_code, city) VALUES (?, ?, ?, ?, ?, ?)"},
+		{&s.selectPerson, "SELECT id FROM persons WHERE first_name = ? AND last_name = ? AND street = ? AND number = ? AND zip_code = ? AND city = ?"},
+		{&s.insertSession, "INSERT INTO sessions (date, session_id, person_id, location_id, login, logout) VALUES (?, ?, ?, ?, ?, ?)"},
+		{&s.insertEvent, "INSERT INTO events (date, timestamp, session_id, event, person_id, location_id) VALUES (?, ?, ?, ?, ?, ?)"},
+		{&s.deletePersons, "DELETE FROM persons WHERE id NOT IN (SELECT person_id FROM sessions) AND id NOT IN (SELECT person_id FROM events)"},
+		{&s.deleteLocations, "DELETE FROM locations WHERE id NOT IN (SELECT location_id FROM sessions) AND id NOT IN (SELECT location_id FROM events)"},
+	}
+
+	for _, q := range queries {
+		stmt, err := db.Prepare(q.query)
+		if err != nil {
+			return s, fmt.Errorf("cannot prepare SQL statement: %w", err)
+		}
+
+		*q.stmt = stmt
+	}
+
+	return s, nil
+}
+
+// Close closes all prepared statements.
+func (s *sqlStatements) Close() {
+	for _, stmt := range []*sql.Stmt{s.deleteEvents, s.deleteSessions, s.replaceDay, s.insertLocation, s.selectLocation,
+		s.insertPerson, s.selectPerson, s.insertSession, s.insertEvent, s.deletePersons, s.deleteLocations} {
+		if stmt != nil {
+			stmt.Close()
+		}
+	}
+}
+
+// writeDay replaces the day of the Journal j in the database db with the
+// persons, locations, sessions and events of j in one transaction, so the day
+// is either exported completely or not at all. modified is the modification
+// time of the journal file of the day, sources the journal.Sources it was read
+// with.
+//
+// Persons and locations which aren't part of any day anymore are deleted, so
+// an erased person doesn't stay in the database.
+func writeDay(db *sql.DB, stmts *sqlStatements, j journal.Journal, modified time.Time, sources journal.Sources) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return fmt.Errorf("cannot begin transaction: %w", err)
+	}
+
+	if err := writeDayTx(tx, stmts, j, modified, sources); err != nil {
+		tx.Rollback()
+		return fmt.Errorf("cannot export %v: %w", j.Date, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("cannot export %v: %w", j.Date, err)
+	}
+
+	return nil
+}
+
+// writeDayTx executes the statements of writeDay in the transaction tx.
+func writeDayTx(tx *sql.Tx, stmts *sqlStatements, j journal.Journal, modified time.Time, sources journal.Sources) error {
+	date := j.Date.String()
+	for _, stmt := range []*sql.Stmt{stmts.deleteEvents, stmts.deleteSessions} {
+		if _, err := tx.Stmt(stmt).Exec(date); err != nil {
+			return err
+		}
+	}
+
+	if _, err := tx.Stmt(stmts.replaceDay).Exec(date, modified.UTC().Format(time.RFC3339Nano),
+		sources.MappingModified.UTC().Format(time.RFC3339Nano), sources.RegistrySize, sources.RegistryHash); err != nil {
+		return err
+	}
+
+	locations := make(map[journal.Location]int64)
+	persons := make(map[journal.Person]int64)
+	for _, e := range j.Entries {
+		if _, ok := locations[e.Location]; !ok {
+			if _, err := tx.Stmt(stmts.insertLocation).Exec(string(e.Location)); err != nil {
+				return err
+			}
+
+			var id int64
+			if err := tx.Stmt(stmts.selectLocation).QueryRow(string(e.Location)).Scan(&id); err != nil {
+				return err
+			}
+
+			locations[e.Location] = id
+		}
+
+		if _, ok := persons[e.Person]; !ok {
+			p := e.Person
+			args := []interface{}{p.FirstName, p.LastName, p.Address.Street, p.Address.Number, p.Address.ZipCode, p.Address.City}
+			if _, err := tx.Stmt(stmts.insertPerson).Exec(args...); err != nil {
+				return err
+			}
+
+			var id int64
+			if err := tx.Stmt(stmts.selectPerson).QueryRow(args...).Scan(&id); err != nil {
+				return err
+			}
+
+			persons[p] = id
+		}
+	}
+
+	insertSession := tx.Stmt(stmts.insertSession)
+	for _, s := range j.GetSessions() {
+		if _, err := insertSession.Exec(date, s.ID, persons[s.Person], locations[s.Location], sqlTimestamp(s.Login), sqlTimestamp(s.Logout)); err != nil {
+			return err
+		}
+	}
+
+	insertEvent := tx.Stmt(stmts.insertEvent)
+	for _, e := range j.Entries {
+		event := "login"
+		if e.Event == journal.Logout {
+			event = "logout"
+		}
+
+		if _, err := insertEvent.Exec(date, sqlTimestamp(e.Timestamp), e.SessionID, event, persons[e.Person], locations[e.Location]); err != nil {
+			return err
+		}
+	}
+
+	for _, stmt := range []*sql.Stmt{stmts.deletePersons, stmts.deleteLocations} {
+		if _, err := tx.Stmt(stmt).Exec(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// An exportedDay is the modification time of the journal file of an exported
+// day and the journal.Sources it was read with.
+type exportedDay struct {
+	journalModified string
+	sources         journal.Sources
+}
+
+// exportedDays returns the exported days of the database db by their date.
+func exportedDays(db *sql.DB) (map[string]exportedDay, error) {
+	rows, err := db.Query("SELECT date, journal_modified, mapping_modified, registry_size, registry_hash FROM days")
+	if err != nil {
+		return nil, fmt.Errorf("cannot read exported days: %w", err)
+	}
+	defer rows.Close()
+
+	days := make(map[string]exportedDay)
+	for rows.Next() {
+		var date, mappingModified string
+		var day exportedDay
+		if err := rows.Scan(&date, &day.journalModified, &mappingModified, &day.sources.RegistrySize, &day.sources.RegistryHash); err != nil {
+			return nil, fmt.Errorf("cannot read exported days: %w", err)
+		}
+
+		if day.sources.MappingModified, err = time.Parse(time.RFC3339Nano, mappingModified); err != nil {
+			return nil, fmt.Errorf("cannot read exported days: %w", err)
+		}
+
+		days[date] = day
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("cannot read exported days: %w", err)
+	}
+
+	return days, nil
+}
+
+// exportSQL exports the journal files of the date range in the dir directory
+// with persons, locations, sessions and events into the SQLite database file at
+// dbPath. The file and its tables are created if they don't exist.
+//
+// Every day is exported in its own transaction. Days whose journal file didn't
+// change since their last export are skipped, so new days can be added to an
+// existing database. A day is exported again as well if the identities or the
+// registry file changed like for a journal.Summary, because its persons are
+// read differently then.
+func exportSQL(dir string, from, to timeutil.Date, opts readOptions, dbPath string) (string, error) {
+	if len(dbPath) == 0 {
+		return "", errors.New("no database file given")
+	}
+
+	db, err := sql.Open("sqlite", dbPath)
+	if err != nil {
+		return "", fmt.Errorf("cannot open database: %w", err)
+	}
+	defer db.Close()
+
+	if _, err := db.Exec(sqlSchema); err != nil {
+		return "", fmt.Errorf("cannot create tables: %w", err)
+	}
+
+	exported, err := exportedDays(db)
+	if err != nil {
+		return "", err
+	}
+
+	stmts, err := prepareSQL(db)
+	defer stmts.Close()
+	if err != nil {
+		return "", err
+	}
+
+	sources, err := journal.ReadSources(dir)
+	if err != nil {
+		return "", err
+	}
+
+	// The sources of most days are the same, so each is only checked once.
+	checked := make(map[journal.Sources]bool)
+	valid := func(s journal.Sources) (bool, error) {
+		if unchanged, ok := checked[s]; ok {
+			return unchanged, nil
+		}
+
+		unchanged, err := s.Unchanged(dir)
+		checked[s] = unchanged
+		return unchanged, err
+	}
+
+	written, unchanged := 0, 0
+	for date := from; !to.Before(date); date = date.AddDays(1) {
+		info, err := os.Stat(journal.FilePath(dir, date))
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+
+		if err != nil {
+			return "", fmt.Errorf("cannot read journal for %v: %w", date, err)
+		}
+
+		if day, ok := exported[date.String()]; ok && day.journalModified == info.ModTime().UTC().Format(time.RFC3339Nano) {
+			ok, err := valid(day.sources)
+			if err != nil {
+				return "", err
+			}
+
+			if ok {
+				unchanged++
+				continue
+			}
+		}
+
+		j, err := readJournal(dir, date, opts)
+		if err != nil {
+			return "", fmt.Errorf("cannot read journal for %v: %w", date, err)
+		}
+
+		if err := writeDay(db, stmts, j, info.ModTime(), sources); err != nil {
+			return "", err
+		}
+
+		written++
+	}
+
+	return fmt.Sprintf("%v days exported to %v, %v days unchanged.\n", written, dbPath, unchanged), nil
+}
@@ -0,0 +1,184 @@
+// This source file is part of the attendance list project
+// as a part of the go lecture by H. Neemann.
+// For this reason you have no permission to use, modify or
+// share this code without the agreement of the authors.
+//
+// Matriculation numbers of the authors: 5703004, 5736465
+
+package main
+
+import (
+	"database/sql"
+	"fmt"
+	"os"
+	"path"
+	"testing"
+	"time"
+
+	"github.com/dateiexplorer/attendancelist/internal/journal"
+	"github.com/dateiexplorer/attendancelist/internal/timeutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSQLTimestamp(t *testing.T) {
+	assert.Nil(t, sqlTimestamp(timeutil.InvalidTimestamp))
+	assert.Equal(t, "2021-10-15 09:30:00", sqlTimestamp(timeutil.NewTimestamp(2021, 10, 15, 9, 30, 0)))
+}
+
+// openSQL returns the database at dbPath with the tables of the SQL export.
+func openSQL(t *testing.T, dbPath string) *sql.DB {
+	db, err := sql.Open("sqlite", dbPath)
+	assert.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+
+	_, err = db.Exec(sqlSchema)
+	assert.NoError(t, err)
+	return db
+}
+
+func TestWriteDay(t *testing.T) {
+	db := openSQL(t, path.Join(t.TempDir(), "attendance.db"))
+	stmts, err := prepareSQL(db)
+	defer stmts.Close()
+	assert.NoError(t, err)
+
+	date := timeutil.NewDate(2021, 10, 15)
+	brien := journal.NewPerson("Liam", "O'Brien", "Feldweg", "12", "74722", "Buchen")
+	j := journal.Journal{Date: date, Entries: []journal.JournalEntry{
+		journal.NewJournalEntry(timeutil.NewTimestamp(2021, 10, 15, 9, 0, 0), "a", journal.Login, "DHBW Mosbach", brien),
+	}}
+
+	modified := time.Date(2021, 10, 15, 23, 59, 59, 0, time.UTC)
+	sources := journal.Sources{MappingModified: modified, RegistrySize: 42, RegistryHash: "abc"}
+	assert.NoError(t, writeDay(db, stmts, j, modified, sources))
+	// Writing the day again replaces it.
+	assert.NoError(t, writeDay(db, stmts, j, modified, sources))
+
+	var lastName, login, event string
+	var logout sql.NullString
+	assert.NoError(t, db.QueryRow(`SELECT p.last_name, s.login, s.logout FROM sessions s
+		JOIN persons p ON p.id = s.person_id WHERE s.date = ?`, "2021-10-15").Scan(&lastName, &login, &logout))
+	assert.Equal(t, "O'Brien", lastName)
+	assert.Equal(t, "2021-10-15 09:00:00", login)
+	assert.False(t, logout.Valid)
+
+	assert.NoError(t, db.QueryRow("SELECT event FROM events").Scan(&event))
+	assert.Equal(t, "login", event)
+
+	days, err := exportedDays(db)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]exportedDay{"2021-10-15": {"2021-10-15T23:59:59Z", sources}}, days)
+
+	var count int
+	assert.NoError(t, db.QueryRow("SELECT (SELECT count(*) FROM sessions) + (SELECT count(*) FROM events)").Scan(&count))
+	assert.Equal(t, 2, count)
+
+	// The person and the location of the replaced day are deleted, because no
+	// day refers to them anymore.
+	anne := journal.NewPerson("Anne", "Meier", "Hauptstraße", "18", "74821", "Mosbach")
+	j.Entries = []journal.JournalEntry{
+		journal.NewJournalEntry(timeutil.NewTimestamp(2021, 10, 15, 9, 0, 0), "b", journal.Login, "Alte Mälzerei", anne),
+	}
+	assert.NoError(t, writeDay(db, stmts, j, modified, sources))
+
+	var persons, locations string
+	assert.NoError(t, db.QueryRow("SELECT group_concat(last_name), (SELECT group_concat(name) FROM locations) FROM persons").Scan(&persons, &locations))
+	assert.Equal(t, "Meier", persons)
+	assert.Equal(t, "Alte Mälzerei", locations)
+}
+
+func TestExportSQL(t *testing.T) {
+	dir := summaryDir(t)
+	dbPath := path.Join(t.TempDir(), "attendance.db")
+	from, to := timeutil.NewDate(2021, 10, 1), timeutil.NewDate(2021, 12, 31)
+
+	counts := func() string {
+		db := openSQL(t, dbPath)
+		var days, persons, locations, sessions, events int
+		assert.NoError(t, db.QueryRow("SELECT (SELECT count(*) FROM days), (SELECT count(*) FROM persons), "+
+			"(SELECT count(*) FROM locations), (SELECT count(*) FROM sessions), (SELECT count(*) FROM events)").
+			Scan(&days, &persons, &locations, &sessions, &events))
+		return fmt.Sprintf("%v|%v|%v|%v|%v", days, persons, locations, sessions, events)
+	}
+
+	msg, err := exportSQL(dir, from, to, readOptions{}, dbPath)
+	assert.NoError(t, err)
+	assert.Equal(t, fmt.Sprintf("2 days exported to %v, 0 days unchanged.\n", dbPath), msg)
+
+	entries := 0
+	for _, date := range []timeutil.Date{timeutil.NewDate(2021, 10, 15), timeutil.NewDate(2021, 11, 30)} {
+		j, err := readJournal(dir, date, readOptions{})
+		assert.NoError(t, err)
+		entries += len(j.Entries)
+	}
+
+	expected := counts()
+	assert.Regexp(t, fmt.Sprintf("^2\\|.*\\|%v$", entries), expected)
+
+	// Unchanged days are skipped, a changed day replaces its sessions and
+	// events.
+	msg, err = exportSQL(dir, from, to, readOptions{}, dbPath)
+	assert.NoError(t, err)
+	assert.Equal(t, fmt.Sprintf("0 days exported to %v, 2 days unchanged.\n", dbPath), msg)
+
+	later := time.Now().Add(time.Minute)
+	assert.NoError(t, os.Chtimes(journal.FilePath(dir, timeutil.NewDate(2021, 11, 30)), later, later))
+	msg, err = exportSQL(dir, from, to, readOptions{}, dbPath)
+	assert.NoError(t, err)
+	assert.Equal(t, fmt.Sprintf("1 days exported to %v, 1 days unchanged.\n", dbPath), msg)
+	assert.Equal(t, expected, counts())
+
+	// A mapping file changes how the persons of every day are read.
+	mapping := journal.PersonMapping{}
+	mapping.Merge(journal.NewPerson("Max", "Mustermann", "Musterstraße", "20", "74821", "Mosbach"),
+		journal.NewPerson("Torsten", "Test", "Teststraße", "10", "74821", "Mosbach"))
+	assert.NoError(t, mapping.WriteToFile(path.Join(dir, journal.MappingFileName)))
+	msg, err = exportSQL(dir, from, to, readOptions{}, dbPath)
+	assert.NoError(t, err)
+	assert.Equal(t, fmt.Sprintf("2 days exported to %v, 0 days unchanged.\n", dbPath), msg)
+
+	var tests int
+	assert.NoError(t, openSQL(t, dbPath).QueryRow("SELECT count(*) FROM persons WHERE last_name = 'Test'").Scan(&tests))
+	assert.Equal(t, 0, tests)
+
+	_, err = exportSQL(dir, from, to, readOptions{}, "")
+	assert.Error(t, err)
+
+	_, err = exportSQL(dir, from, to, readOptions{}, path.Join(t.TempDir(), "missing", "attendance.db"))
+	assert.Error(t, err)
+}
+
+func TestExportSQLAfterErasure(t *testing.T) {
+	dir := summaryDir(t)
+	dbPath := path.Join(t.TempDir(), "attendance.db")
+	from, to := timeutil.NewDate(2021, 10, 1), timeutil.NewDate(2021, 12, 31)
+
+	_, err := exportSQL(dir, from, to, readOptions{}, dbPath)
+	assert.NoError(t, err)
+
+	count := func(lastName string) int {
+		var n int
+		assert.NoError(t, openSQL(t, dbPath).QueryRow("SELECT count(*) FROM persons WHERE last_name = ?", lastName).Scan(&n))
+		return n
+	}
+
+	assert.Equal(t, 1, count("Normalverbraucher"))
+	for _, date := range []timeutil.Date{timeutil.NewDate(2021, 10, 15), timeutil.NewDate(2021, 11, 30)} {
+		_, err := journal.RemoveEntries(dir, date, nil, func(e journal.JournalEntry) bool {
+			return e.Person.LastName == "Normalverbraucher"
+		})
+		assert.NoError(t, err)
+	}
+
+	// The erased person is removed from the database with the changed days.
+	later := time.Now().Add(time.Minute)
+	for _, date := range []timeutil.Date{timeutil.NewDate(2021, 10, 15), timeutil.NewDate(2021, 11, 30)} {
+		assert.NoError(t, os.Chtimes(journal.FilePath(dir, date), later, later))
+	}
+
+	msg, err := exportSQL(dir, from, to, readOptions{}, dbPath)
+	assert.NoError(t, err)
+	assert.Equal(t, fmt.Sprintf("2 days exported to %v, 0 days unchanged.\n", dbPath), msg)
+	assert.Equal(t, 0, count("Normalverbraucher"))
+	assert.Equal(t, 1, count("Meier"))
+}
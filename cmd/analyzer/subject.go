@@ -0,0 +1,254 @@
+// This source file is part of the attendance list project
+// as a part of the go lecture by H. Neemann.
+// For this reason you have no permission to use, modify or
+// share this code without the agreement of the authors.
+//
+// Matriculation numbers of the authors: 5703004, 5736465
+
+package main
+
+import (
+	"crypto"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+
+	"github.com/dateiexplorer/attendancelist/internal/journal"
+)
+
+// A subjectReport holds all journal entries of a data subject as they are
+// stored, i.e. with the registered instead of the canonical Person.
+type subjectReport []journal.JournalEntry
+
+// NextEntry returns a read-only channel that loops through the hole
+// subjectReport and returns data of the JournalEntry as a string slice.
+//
+// Used to convert a subjectReport to any file format.
+func (r subjectReport) NextEntry() <-chan []string {
+	entries := make(chan []string)
+	go func() {
+		for _, e := range r {
+			event := "login"
+			if e.Event == journal.Logout {
+				event = "logout"
+			}
+
+			p := e.Person
+			entries <- []string{e.Timestamp.String(), e.SessionID, event, string(e.Location),
+				p.FirstName, p.LastName, p.Address.Street, p.Address.Number, p.Address.ZipCode, p.Address.City}
+		}
+
+		close(entries)
+	}()
+
+	return entries
+}
+
+// Header returns a string slice which describes the data given by the NextEntry
+// function.
+//
+// Used to convert a subjectReport to any file format.
+func (r subjectReport) Header() []string {
+	return []string{"Timestamp", "SessionID", "Event", "Location", "FirstName", "LastName", "Street", "Number", "ZipCode", "City"}
+}
+
+// A subject is a person who requested access to or erasure of their data.
+type subject struct {
+	dir      string
+	priv     crypto.PrivateKey
+	registry journal.Registry
+	mapping  journal.PersonMapping
+	// person is the canonical Person of the subject.
+	person journal.Person
+}
+
+// findSubject selects the person matching the query in all journal files of
+// the dir directory. The registry and mapping file are applied, so the subject
+// is found under every pseudonym and alias.
+func findSubject(dir string, query string, priv crypto.PrivateKey) (subject, error) {
+	s := subject{dir: dir, priv: priv}
+
+	var err error
+	s.registry, err = journal.ReadRegistryIfExists(path.Join(dir, journal.RegistryFileName))
+	if err != nil {
+		return s, err
+	}
+
+	s.mapping, err = journal.ReadPersonMappingIfExists(path.Join(dir, journal.MappingFileName))
+	if err != nil {
+		return s, err
+	}
+
+	journals, err := s.journals()
+	if err != nil {
+		return s, err
+	}
+
+	for i, j := range journals {
+		journals[i] = j.ApplyRegistry(s.registry).ApplyMapping(s.mapping)
+	}
+
+	s.person, err = selectPersonFromJournals(journals, query)
+	return s, err
+}
+
+// journals reads all journal files of the subject's directory without
+// resolving the persons.
+func (s subject) journals() ([]journal.Journal, error) {
+	dates, err := journal.ListJournals(s.dir)
+	if err != nil {
+		return nil, err
+	}
+
+	journals := make([]journal.Journal, 0, len(dates))
+	for _, date := range dates {
+		j, err := journal.ReadJournalWithKey(s.dir, date, s.priv)
+		if err != nil {
+			return nil, fmt.Errorf("cannot read journal for %v: %w", date, err)
+		}
+
+		journals = append(journals, j)
+	}
+
+	return journals, nil
+}
+
+// owns reports whether the JournalEntry e as stored belongs to the subject.
+func (s subject) owns(e journal.JournalEntry) bool {
+	return s.mapping.Resolve(s.registry.Resolve(e.Person)) == s.person
+}
+
+// entries returns all JournalEntries of the subject with the registered Person.
+func (s subject) entries() (subjectReport, error) {
+	journals, err := s.journals()
+	if err != nil {
+		return nil, err
+	}
+
+	report := subjectReport{}
+	for _, j := range journals {
+		for _, e := range j.Entries {
+			if s.owns(e) {
+				e.Person = s.registry.Resolve(e.Person)
+				report = append(report, e)
+			}
+		}
+	}
+
+	return report, nil
+}
+
+// exportSubject writes all journal entries of the person matching the query
+// in the journal files of the dir directory to filePath.
+func exportSubject(dir string, query string, priv crypto.PrivateKey, filePath string, format string) (string, error) {
+	s, err := findSubject(dir, query, priv)
+	if err != nil {
+		return "", err
+	}
+
+	report, err := s.entries()
+	if err != nil {
+		return "", err
+	}
+
+	return writeOutput(report, filePath, format)
+}
+
+// eraseSubject removes all journal entries of the person matching the query
+// from the journal files of the dir directory. The person is also removed from
+// the registry and the mapping file, the other lines of all files stay
+// unchanged. In dry run mode nothing is changed, only the numbers of affected
+// entries are reported.
+//
+// The journal directory is locked from reading to replacing the files, so the
+// service can keep running and the entries it appends meanwhile aren't lost.
+func eraseSubject(dir string, query string, priv crypto.PrivateKey, dryRun bool) (string, error) {
+	if !dryRun {
+		unlock, err := journal.LockDir(dir)
+		if err != nil {
+			return "", err
+		}
+		defer unlock()
+	}
+
+	s, err := findSubject(dir, query, priv)
+	if err != nil {
+		return "", err
+	}
+
+	ids := make([]string, 0)
+	for id, p := range s.registry {
+		if s.mapping.Resolve(p) == s.person {
+			ids = append(ids, id)
+		}
+	}
+
+	aliases := 0
+	for alias, canonical := range s.mapping {
+		if canonical == s.person || alias == s.person {
+			aliases++
+		}
+	}
+
+	if dryRun {
+		report, err := s.entries()
+		if err != nil {
+			return "", err
+		}
+
+		days := make(map[string]bool)
+		for _, e := range report {
+			days[e.Timestamp.Date().String()] = true
+		}
+
+		return fmt.Sprintf("Would erase %v entries of %v from %v journal files, %v registry entries and %v mappings.\n",
+			len(report), s.person.String(), len(days), len(ids), aliases), nil
+	}
+
+	dates, err := journal.ListJournals(dir)
+	if err != nil {
+		return "", err
+	}
+
+	var removed, files int
+	for _, date := range dates {
+		n, err := journal.RemoveEntries(dir, date, priv, s.owns)
+		if err != nil {
+			return "", fmt.Errorf("cannot erase subject from journal for %v: %w", date, err)
+		}
+
+		if n > 0 {
+			removed += n
+			files++
+
+			// The summary still contains the visits of the subject under its
+			// pseudonym, so it's removed and must be written again.
+			if err := os.Remove(journal.SummaryFilePath(dir, date)); err != nil && !errors.Is(err, os.ErrNotExist) {
+				return "", fmt.Errorf("cannot remove summary for %v: %w", date, err)
+			}
+		}
+	}
+
+	// The registry and the mapping are changed last, otherwise an aborted
+	// erasure leaves entries which cannot be assigned to the subject anymore.
+	if len(ids) > 0 {
+		for _, id := range ids {
+			delete(s.registry, id)
+		}
+
+		if err := s.registry.WriteToFile(path.Join(dir, journal.RegistryFileName)); err != nil {
+			return "", err
+		}
+	}
+
+	if aliases > 0 {
+		s.mapping.Remove(s.person)
+		if err := s.mapping.WriteToFile(path.Join(dir, journal.MappingFileName)); err != nil {
+			return "", err
+		}
+	}
+
+	return fmt.Sprintf("Erased %v entries of %v from %v journal files, %v registry entries and %v mappings.\n",
+		removed, s.person.String(), files, len(ids), aliases), nil
+}
@@ -0,0 +1,98 @@
+// This source file is part of the attendance list project
+// as a part of the go lecture by H. Neemann.
+// For this reason you have no permission to use, modify or
+// share this code without the agreement of the authors.
+//
+// Matriculation numbers of the authors: 5703004, 5736465
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/dateiexplorer/attendancelist/internal/journal"
+	"github.com/dateiexplorer/attendancelist/internal/timeutil"
+	"github.com/stretchr/testify/assert"
+)
+
+// subjectDir returns a journal directory in pseudonymized mode with the
+// typoJournal, where both spellings of Hans Müller are merged.
+func subjectDir(t *testing.T) string {
+	dir := t.TempDir()
+	ps, err := journal.NewPseudonymizer(path.Join(dir, journal.RegistryFileName), func(p journal.Person) (string, error) {
+		return "id-" + p.FirstName, nil
+	})
+	assert.NoError(t, err)
+
+	for _, e := range typoJournal().Entries {
+		assert.NoError(t, ps.WriteToJournalFile(dir, &e))
+	}
+
+	m := journal.PersonMapping{}
+	m.Merge(hans, hansTypo)
+	assert.NoError(t, m.WriteToFile(path.Join(dir, journal.MappingFileName)))
+
+	return dir
+}
+
+func TestExportSubject(t *testing.T) {
+	dir := subjectDir(t)
+
+	filePath := path.Join(t.TempDir(), "subject.csv")
+	msg, err := exportSubject(dir, "Hans,Müller", nil, filePath, "csv")
+	assert.NoError(t, err)
+	assert.Equal(t, fmt.Sprintln("Output successfully written."), msg)
+
+	// All entries under every spelling as they are stored
+	content, err := os.ReadFile(filePath)
+	assert.NoError(t, err)
+	assert.Equal(t, `Timestamp,SessionID,Event,Location,FirstName,LastName,Street,Number,ZipCode,City
+2021/10/15 06:20:13 UTC,d61ec70b78628e15,login,DHBW Mosbach,hans ,Mueler,Feldweg,12,74722,Buchen
+2021/10/15 13:40:10 UTC,d61ec70b78628e15,logout,DHBW Mosbach,hans ,Mueler,Feldweg,12,74722,Buchen
+2021/10/15 13:40:11 UTC,5faacdf0e6e7b44a,login,Alte Mälzerei,Hans,Müller,Feldweg,12,74722,Buchen
+2021/10/15 15:00:00 UTC,5faacdf0e6e7b44a,logout,Alte Mälzerei,Hans,Müller,Feldweg,12,74722,Buchen
+2021/10/15 16:00:00 UTC,aaaaaaaaaaaaaaaa,login,DHBW Mosbach,Hans,Müller,Feldweg,12,74722,Buchen
+`, string(content))
+}
+
+func TestEraseSubject(t *testing.T) {
+	dir := subjectDir(t)
+	date := timeutil.NewDate(2021, 10, 15)
+
+	msg, err := eraseSubject(dir, "Hans,Müller", nil, true)
+	assert.NoError(t, err)
+	assert.Equal(t, "Would erase 5 entries of Hans,Müller,Feldweg,12,74722,Buchen from 1 journal files, 2 registry entries and 1 mappings.\n", msg)
+
+	// Nothing is changed in dry run mode.
+	j, err := journal.ReadJournal(dir, date)
+	assert.NoError(t, err)
+	assert.Equal(t, 7, len(j.Entries))
+	assert.NoError(t, journal.WriteSummary(dir, journal.Summarize(j, summaryPseudonym([]byte("key")))))
+
+	msg, err = eraseSubject(dir, "Hans,Müller", nil, false)
+	assert.NoError(t, err)
+
+	// The summary with the visits of the subject is removed.
+	_, err = os.Stat(journal.SummaryFilePath(dir, date))
+	assert.True(t, os.IsNotExist(err))
+	assert.Equal(t, "Erased 5 entries of Hans,Müller,Feldweg,12,74722,Buchen from 1 journal files, 2 registry entries and 1 mappings.\n", msg)
+
+	j, err = readJournal(dir, date, readOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, []journal.JournalEntry{typoJournal().Entries[1], typoJournal().Entries[5]}, j.Entries)
+
+	r, err := journal.ReadRegistry(path.Join(dir, journal.RegistryFileName))
+	assert.NoError(t, err)
+	assert.Equal(t, journal.Registry{"id-Lieschen": lieschen}, r)
+
+	m, err := journal.ReadPersonMapping(path.Join(dir, journal.MappingFileName))
+	assert.NoError(t, err)
+	assert.Equal(t, 0, len(m))
+
+	// The subject cannot be found anymore.
+	_, err = exportSubject(dir, "Hans,Müller", nil, "", "csv")
+	assert.Error(t, err)
+}
@@ -0,0 +1,204 @@
+// This source file is part of the attendance list project
+// as a part of the go lecture by H. Neemann.
+// For this reason you have no permission to use, modify or
+// share this code without the agreement of the authors.
+//
+// Matriculation numbers of the authors: 5703004, 5736465
+
+package main
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/dateiexplorer/attendancelist/internal/journal"
+	"github.com/dateiexplorer/attendancelist/internal/timeutil"
+)
+
+// summaryPseudonym returns a function which derives the pseudonym of a person
+// in the summary files with the secret key. The same person gets the same
+// pseudonym in all summary files, but the person cannot be determined from the
+// pseudonym without the key.
+func summaryPseudonym(key []byte) func(p journal.Person) string {
+	return func(p journal.Person) string {
+		mac := hmac.New(sha256.New, key)
+		mac.Write([]byte(p.String()))
+		return hex.EncodeToString(mac.Sum(nil)[:16])
+	}
+}
+
+// checkSummaryKeyPath returns an error if keyPath is empty or inside the dir
+// directory. Whoever can read the summary files must not have the key,
+// otherwise the pseudonyms of known persons can be calculated.
+func checkSummaryKeyPath(dir string, keyPath string) error {
+	if len(keyPath) == 0 {
+		return errors.New("the path to the secret key of the pseudonyms must be set with -key")
+	}
+
+	if journal.IsInDir(keyPath, dir) {
+		return fmt.Errorf("the secret key of the pseudonyms must not be stored with the journal files in %v", dir)
+	}
+
+	return nil
+}
+
+// readSummaryKey reads the secret key of the pseudonyms from the file at
+// keyPath. Returns an error with a hint how to create the key if it doesn't
+// exist.
+func readSummaryKey(keyPath string) ([]byte, error) {
+	key, err := journal.ReadSecretKey(keyPath)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("%w, create it with summarize -new-key", err)
+	}
+
+	return key, err
+}
+
+// summarize writes a summary file for every journal file of the date range in
+// the dir directory. The persons in the summaries are pseudonymized with the
+// key from the file at keyPath, which must be outside the dir directory. If
+// newKey is set, the key is created first and the file must not exist yet.
+func summarize(dir string, from, to timeutil.Date, opts readOptions, keyPath string, newKey bool) (string, error) {
+	if err := checkSummaryKeyPath(dir, keyPath); err != nil {
+		return "", err
+	}
+
+	msg := ""
+	var key []byte
+	var err error
+	if newKey {
+		key, err = journal.CreateSecretKey(keyPath)
+		msg = fmt.Sprintf("Secret key of the pseudonyms created in %v.\n", keyPath)
+	} else {
+		key, err = readSummaryKey(keyPath)
+	}
+
+	if err != nil {
+		return "", err
+	}
+
+	// The modification times are taken before the journal files are read, so
+	// that a change while reading outdates the summary.
+	sources, err := journal.ReadSources(dir)
+	if err != nil {
+		return "", err
+	}
+
+	modified := make(map[timeutil.Date]time.Time)
+	for date := from; !to.Before(date); date = date.AddDays(1) {
+		if info, err := os.Stat(journal.FilePath(dir, date)); err == nil {
+			modified[date] = info.ModTime()
+		}
+	}
+
+	journals, err := readJournals(dir, from, to, opts)
+	if err != nil {
+		return "", err
+	}
+
+	pseudonym := summaryPseudonym(key)
+	for _, j := range journals {
+		s := journal.Summarize(j, pseudonym)
+		s.JournalModified = modified[j.Date]
+		s.Sources = sources
+		if err := journal.WriteSummary(dir, s); err != nil {
+			return "", fmt.Errorf("cannot write summary for %v: %w", j.Date, err)
+		}
+	}
+
+	return msg + fmt.Sprintf("%v summary files written.\n", len(journals)), nil
+}
+
+// readSummaries returns the Summaries of every day of the date range in the dir
+// directory. Days without an up-to-date summary file are summarized from their
+// journal file with the key from the file at keyPath, days without both are
+// skipped. The number of days read from summary files is returned as well.
+//
+// Returns an error if keyPath is empty or inside the dir directory.
+func readSummaries(dir string, from, to timeutil.Date, opts readOptions, keyPath string) (journal.SummaryList, int, error) {
+	if err := checkSummaryKeyPath(dir, keyPath); err != nil {
+		return nil, 0, err
+	}
+
+	summaries := journal.SummaryList{}
+	var pseudonym func(p journal.Person) string
+	read := 0
+	for date := from; !to.Before(date); date = date.AddDays(1) {
+		s, err := journal.ReadSummary(dir, date)
+		if err == nil {
+			summaries = append(summaries, s)
+			read++
+			continue
+		}
+
+		if !errors.Is(err, os.ErrNotExist) && !errors.Is(err, journal.ErrOutdatedSummary) {
+			return nil, 0, fmt.Errorf("cannot read summary for %v: %w", date, err)
+		}
+
+		j, err := readJournal(dir, date, opts)
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+
+		if err != nil {
+			return nil, 0, fmt.Errorf("cannot read journal for %v: %w", date, err)
+		}
+
+		// The key is only needed if a journal file must be summarized.
+		if pseudonym == nil {
+			key, err := readSummaryKey(keyPath)
+			if err != nil {
+				return nil, 0, err
+			}
+
+			pseudonym = summaryPseudonym(key)
+		}
+
+		summaries = append(summaries, journal.Summarize(j, pseudonym))
+	}
+
+	return summaries, read, nil
+}
+
+// createStatistics writes the occupancy statistics of every location on every
+// day of the date range to filePath. The statistics are taken from the summary
+// files where possible. The returned message contains the number of different
+// persons who visited the locations during the date range.
+func createStatistics(dir string, from, to timeutil.Date, opts readOptions, keyPath string, filePath string, format string) (string, error) {
+	summaries, read, err := readSummaries(dir, from, to, opts, keyPath)
+	if err != nil {
+		return "", err
+	}
+
+	msg, err := writeOutput(summaries, filePath, format)
+	if err != nil {
+		return "", err
+	}
+
+	persons := make(map[string]bool)
+	for _, s := range summaries {
+		for _, p := range s.Persons {
+			persons[p.ID] = true
+		}
+	}
+
+	msg += fmt.Sprintf("Statistics of %v days, %v of them read from summary files. %v different persons visited the locations.\n",
+		len(summaries), read, len(persons))
+	return msg, nil
+}
+
+// createOccupancyFromSummary writes the occupancy statistics of the Summary s
+// like createOccupancy without a timeline.
+func createOccupancyFromSummary(s journal.Summary, location string, filePath string, format string) (string, error) {
+	list := s.Occupancy()
+	if len(location) > 0 {
+		list = journal.OccupancyList{s.OccupancyForLocation(journal.Location(location))}
+	}
+
+	return writeOutput(list, filePath, format)
+}
@@ -0,0 +1,170 @@
+// This source file is part of the attendance list project
+// as a part of the go lecture by H. Neemann.
+// For this reason you have no permission to use, modify or
+// share this code without the agreement of the authors.
+//
+// Matriculation numbers of the authors: 5703004, 5736465
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dateiexplorer/attendancelist/internal/journal"
+	"github.com/dateiexplorer/attendancelist/internal/timeutil"
+	"github.com/stretchr/testify/assert"
+)
+
+// summaryDir returns a temporary directory with the journal files of the
+// testdata directory.
+func summaryDir(t *testing.T) string {
+	dir := t.TempDir()
+	for _, date := range []timeutil.Date{timeutil.NewDate(2021, 10, 15), timeutil.NewDate(2021, 11, 30)} {
+		data, err := os.ReadFile(journal.FilePath("testdata", date))
+		assert.NoError(t, err)
+		assert.NoError(t, os.WriteFile(journal.FilePath(dir, date), data, 0600))
+	}
+
+	return dir
+}
+
+func TestSummaryPseudonym(t *testing.T) {
+	hans := journal.NewPerson("Hans", "Müller", "Feldweg", "12", "74722", "Buchen")
+	anne := journal.NewPerson("Anne", "Meier", "Hauptstraße", "18", "74821", "Mosbach")
+
+	pseudonym := summaryPseudonym([]byte("key"))
+	assert.Equal(t, pseudonym(hans), pseudonym(hans))
+	assert.NotEqual(t, pseudonym(hans), pseudonym(anne))
+	assert.NotEqual(t, pseudonym(hans), summaryPseudonym([]byte("other key"))(hans))
+	assert.Len(t, pseudonym(hans), 32)
+}
+
+// summaryKey returns the path of a new secret key outside of the journal
+// directory.
+func summaryKey(t *testing.T) string {
+	keyPath := path.Join(t.TempDir(), "summary.key")
+	_, err := journal.CreateSecretKey(keyPath)
+	assert.NoError(t, err)
+	return keyPath
+}
+
+func TestSummarize(t *testing.T) {
+	dir := summaryDir(t)
+	keyPath := summaryKey(t)
+	from, to := timeutil.NewDate(2021, 10, 1), timeutil.NewDate(2021, 12, 31)
+
+	msg, err := summarize(dir, from, to, readOptions{}, keyPath, false)
+	assert.NoError(t, err)
+	assert.Equal(t, "2 summary files written.\n", msg)
+
+	for _, date := range []timeutil.Date{timeutil.NewDate(2021, 10, 15), timeutil.NewDate(2021, 11, 30)} {
+		s, err := journal.ReadSummary(dir, date)
+		assert.NoError(t, err)
+
+		j, err := readJournal(dir, date, readOptions{})
+		assert.NoError(t, err)
+		assert.Len(t, s.Occupancy(), len(j.GetOccupancy()))
+
+		// The summary contains no names.
+		for _, p := range s.Persons {
+			for _, person := range uniquePersons(j) {
+				assert.NotEqual(t, person.FirstName, p.ID)
+			}
+		}
+	}
+}
+
+func TestSummarizeKey(t *testing.T) {
+	dir := summaryDir(t)
+	from, to := timeutil.NewDate(2021, 10, 1), timeutil.NewDate(2021, 12, 31)
+
+	// The key is required and must not be stored with the journal files.
+	_, err := summarize(dir, from, to, readOptions{}, "", false)
+	assert.Error(t, err)
+	_, err = summarize(dir, from, to, readOptions{}, path.Join(dir, "summary.key"), true)
+	assert.Error(t, err)
+	_, err = summarize(dir, from, to, readOptions{}, path.Join(dir, "keys", "summary.key"), true)
+	assert.Error(t, err)
+	_, _, err = readSummaries(dir, from, to, readOptions{}, path.Join(dir, "summary.key"))
+	assert.Error(t, err)
+	_, err = os.Stat(path.Join(dir, "summary.key"))
+	assert.True(t, os.IsNotExist(err))
+
+	// A missing key isn't created without -new-key.
+	keyPath := path.Join(t.TempDir(), "summary.key")
+	_, err = summarize(dir, from, to, readOptions{}, keyPath, false)
+	assert.Error(t, err)
+	_, err = os.Stat(keyPath)
+	assert.True(t, os.IsNotExist(err))
+
+	msg, err := summarize(dir, from, to, readOptions{}, keyPath, true)
+	assert.NoError(t, err)
+	assert.Equal(t, fmt.Sprintf("Secret key of the pseudonyms created in %v.\n2 summary files written.\n", keyPath), msg)
+
+	// An existing key is never replaced.
+	_, err = summarize(dir, from, to, readOptions{}, keyPath, true)
+	assert.Error(t, err)
+}
+
+func TestCreateStatistics(t *testing.T) {
+	dir := summaryDir(t)
+	keyPath := summaryKey(t)
+	from, to := timeutil.NewDate(2021, 10, 15), timeutil.NewDate(2021, 11, 30)
+
+	// The statistics are the same with and without summary files.
+	expected := path.Join(dir, "expected.csv")
+	msg, err := createStatistics(dir, from, to, readOptions{}, keyPath, expected, "csv")
+	assert.NoError(t, err)
+	assert.Regexp(t, `^Output successfully written.\nStatistics of 2 days, 0 of them read from summary files. \d+ different persons visited the locations.\n$`, msg)
+
+	_, err = summarize(dir, timeutil.NewDate(2021, 10, 15), timeutil.NewDate(2021, 10, 15), readOptions{}, keyPath, false)
+	assert.NoError(t, err)
+
+	actual := path.Join(dir, "actual.csv")
+	msg2, err := createStatistics(dir, from, to, readOptions{}, keyPath, actual, "csv")
+	assert.NoError(t, err)
+	assert.Equal(t, strings.Replace(msg, "0 of them", "1 of them", 1), msg2)
+
+	expectedContent, err := os.ReadFile(expected)
+	assert.NoError(t, err)
+	actualContent, err := os.ReadFile(actual)
+	assert.NoError(t, err)
+	assert.Equal(t, string(expectedContent), string(actualContent))
+
+	// An outdated summary is ignored.
+	later := time.Now().Add(time.Minute)
+	assert.NoError(t, os.Chtimes(journal.FilePath(dir, timeutil.NewDate(2021, 10, 15)), later, later))
+	msg, err = createStatistics(dir, from, to, readOptions{}, keyPath, actual, "csv")
+	assert.NoError(t, err)
+	assert.Contains(t, msg, "Statistics of 2 days, 0 of them read from summary files.")
+}
+
+func TestCreateOccupancyFromSummary(t *testing.T) {
+	j, err := readJournal("testdata", timeutil.NewDate(2021, 10, 15), readOptions{})
+	assert.NoError(t, err)
+	s := journal.Summarize(j, nil)
+
+	dir := t.TempDir()
+	for _, location := range []string{"", "Alte Mälzerei", "Night Club"} {
+		for _, format := range []string{"csv", "json"} {
+			expected, actual := path.Join(dir, "expected"), path.Join(dir, "actual")
+			_, err := createOccupancy(j, location, false, expected, format)
+			assert.NoError(t, err)
+
+			msg, err := createOccupancyFromSummary(s, location, actual, format)
+			assert.NoError(t, err)
+			assert.Equal(t, fmt.Sprintln("Output successfully written."), msg)
+
+			expectedContent, err := os.ReadFile(expected)
+			assert.NoError(t, err)
+			actualContent, err := os.ReadFile(actual)
+			assert.NoError(t, err)
+			assert.Equal(t, string(expectedContent), string(actualContent), location+" "+format)
+		}
+	}
+}
@@ -0,0 +1,259 @@
+// This source file is part of the attendance list project
+// as a part of the go lecture by H. Neemann.
+// For this reason you have no permission to use, modify or
+// share this code without the agreement of the authors.
+//
+// Matriculation numbers of the authors: 5703004, 5736465
+
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+
+	"golang.org/x/term"
+)
+
+// Control characters of a terminal in raw mode.
+const (
+	keyCtrlA     = 1
+	keyCtrlC     = 3
+	keyCtrlD     = 4
+	keyCtrlE     = 5
+	keyBackspace = 8
+	keyTab       = 9
+	keyEnter     = 13
+	keyLineFeed  = 10
+	keyEscape    = 27
+	keyDelete    = 127
+)
+
+// A plainReader reads lines from an input which isn't a terminal, e.g. a pipe,
+// without history and completion.
+type plainReader struct {
+	scanner *bufio.Scanner
+	out     io.Writer
+}
+
+// ReadLine writes the prompt and returns the next line of the input. Returns
+// io.EOF at the end of the input.
+func (r plainReader) ReadLine(prompt string) (string, error) {
+	fmt.Fprint(r.out, prompt)
+	if !r.scanner.Scan() {
+		if err := r.scanner.Err(); err != nil {
+			return "", err
+		}
+
+		return "", io.EOF
+	}
+
+	return r.scanner.Text(), nil
+}
+
+// A lineEditor reads lines from a terminal in raw mode. It supports moving the
+// cursor, browsing the history of previous lines with the arrow keys and
+// completing the line with the tab key.
+type lineEditor struct {
+	in      *bufio.Reader
+	out     io.Writer
+	history []string
+	// complete returns the possible completions of a line.
+	complete func(line string) []string
+}
+
+// newLineEditor returns a lineEditor which reads from in, echoes to out and
+// completes with the complete function.
+func newLineEditor(in io.Reader, out io.Writer, complete func(line string) []string) *lineEditor {
+	return &lineEditor{in: bufio.NewReader(in), out: out, complete: complete}
+}
+
+// A rawReader reads lines with a lineEditor from the terminal of the file
+// descriptor fd. The terminal is only in raw mode while a line is read, so the
+// output of the commands is written in the normal mode.
+type rawReader struct {
+	fd     int
+	editor *lineEditor
+}
+
+// ReadLine puts the terminal into raw mode, reads a line with the editor and
+// restores the terminal afterwards.
+func (r rawReader) ReadLine(prompt string) (string, error) {
+	state, err := term.MakeRaw(r.fd)
+	if err != nil {
+		return "", fmt.Errorf("cannot put terminal into raw mode: %w", err)
+	}
+	defer term.Restore(r.fd, state)
+
+	return r.editor.ReadLine(prompt)
+}
+
+// ReadLine writes the prompt and returns the line the user entered. Ctrl-C
+// discards the current line, Ctrl-D on an empty line returns io.EOF.
+func (e *lineEditor) ReadLine(prompt string) (string, error) {
+	buf := []rune{}
+	pos := 0
+	// The history entry shown, len(e.history) is the line being edited.
+	hist := len(e.history)
+	edited := ""
+
+	redraw := func() {
+		fmt.Fprintf(e.out, "\r%v%v\x1b[K", prompt, string(buf))
+		if back := len(buf) - pos; back > 0 {
+			fmt.Fprintf(e.out, "\x1b[%vD", back)
+		}
+	}
+
+	showHistory := func(i int) {
+		if i < 0 || i > len(e.history) || i == hist {
+			return
+		}
+
+		if hist == len(e.history) {
+			edited = string(buf)
+		}
+
+		hist = i
+		if i == len(e.history) {
+			buf = []rune(edited)
+		} else {
+			buf = []rune(e.history[i])
+		}
+
+		pos = len(buf)
+		redraw()
+	}
+
+	fmt.Fprint(e.out, prompt)
+	for {
+		r, _, err := e.in.ReadRune()
+		if err != nil {
+			return "", err
+		}
+
+		switch r {
+		case keyEnter, keyLineFeed:
+			fmt.Fprint(e.out, "\r\n")
+			line := string(buf)
+			if len(strings.TrimSpace(line)) > 0 {
+				e.history = append(e.history, line)
+			}
+
+			return line, nil
+		case keyCtrlC:
+			fmt.Fprint(e.out, "^C\r\n")
+			buf, pos = []rune{}, 0
+			hist = len(e.history)
+			fmt.Fprint(e.out, prompt)
+		case keyCtrlD:
+			if len(buf) == 0 {
+				return "", io.EOF
+			}
+		case keyBackspace, keyDelete:
+			if pos > 0 {
+				buf = append(buf[:pos-1], buf[pos:]...)
+				pos--
+				redraw()
+			}
+		case keyCtrlA:
+			pos = 0
+			redraw()
+		case keyCtrlE:
+			pos = len(buf)
+			redraw()
+		case keyTab:
+			buf = e.completeLine(prompt, buf)
+			pos = len(buf)
+			redraw()
+		case keyEscape:
+			seq, err := e.readEscapeSequence()
+			if err != nil {
+				return "", err
+			}
+
+			switch seq {
+			case "[A":
+				showHistory(hist - 1)
+			case "[B":
+				showHistory(hist + 1)
+			case "[C":
+				if pos < len(buf) {
+					pos++
+					redraw()
+				}
+			case "[D":
+				if pos > 0 {
+					pos--
+					redraw()
+				}
+			}
+		default:
+			if r < 32 {
+				continue
+			}
+
+			buf = append(buf[:pos], append([]rune{r}, buf[pos:]...)...)
+			pos++
+			redraw()
+		}
+	}
+}
+
+// readEscapeSequence reads the rest of an escape sequence like "[A" after the
+// escape character.
+func (e *lineEditor) readEscapeSequence() (string, error) {
+	seq := ""
+	for {
+		r, _, err := e.in.ReadRune()
+		if err != nil {
+			return "", err
+		}
+
+		seq += string(r)
+		// The sequence ends with a letter or a tilde, but the bracket after
+		// the escape character belongs to it.
+		if seq != "[" && seq != "O" && (r == '~' || r >= 'A' && r <= 'Z' || r >= 'a' && r <= 'z') {
+			return seq, nil
+		}
+	}
+}
+
+// completeLine returns the line buf completed as far as all completions agree.
+// If the line can't be completed further and there are several completions,
+// they are listed below the line.
+func (e *lineEditor) completeLine(prompt string, buf []rune) []rune {
+	if e.complete == nil {
+		return buf
+	}
+
+	completions := e.complete(string(buf))
+	switch len(completions) {
+	case 0:
+		return buf
+	case 1:
+		return []rune(completions[0])
+	}
+
+	prefix := []rune(completions[0])
+	for _, c := range completions[1:] {
+		prefix = commonPrefix(prefix, []rune(c))
+	}
+
+	if len(prefix) > len(buf) {
+		return prefix
+	}
+
+	fmt.Fprintf(e.out, "\r\n%v\r\n%v", strings.Join(completions, "  "), prompt)
+	return buf
+}
+
+// commonPrefix returns the longest common prefix of a and b.
+func commonPrefix(a, b []rune) []rune {
+	n := 0
+	for n < len(a) && n < len(b) && a[n] == b[n] {
+		n++
+	}
+
+	return a[:n]
+}
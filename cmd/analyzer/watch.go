@@ -0,0 +1,165 @@
+// This source file is part of the attendance list project
+// as a part of the go lecture by H. Neemann.
+// For this reason you have no permission to use, modify or
+// share this code without the agreement of the authors.
+//
+// Matriculation numbers of the authors: 5703004, 5736465
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/dateiexplorer/attendancelist/internal/journal"
+	"github.com/dateiexplorer/attendancelist/internal/timeutil"
+)
+
+// The number of events shown below the occupancy table.
+const recentEvents = 10
+
+// A watcher follows the journal file of the current day and keeps track of the
+// persons present at every location.
+type watcher struct {
+	follower *journal.Follower
+	dir      string
+	opts     readOptions
+	// present maps every location to the sessions logged in there.
+	present map[journal.Location]map[string]bool
+	// recent holds the last formatted events for the occupancy table.
+	recent []string
+}
+
+// newWatcher returns a watcher for the journal files in the dir directory,
+// starting with the journal file of the date today.
+func newWatcher(dir string, today timeutil.Date, opts readOptions) *watcher {
+	priv := opts.priv
+	if opts.idsOnly {
+		priv = nil
+	}
+
+	return &watcher{
+		follower: journal.NewFollower(dir, today, priv),
+		dir:      dir,
+		opts:     opts,
+		present:  make(map[journal.Location]map[string]bool),
+	}
+}
+
+// poll reads the events appended to the journal file since the last call,
+// updates the occupancy and returns the formatted events.
+//
+// Sessions stay present if the journal file changes at midnight, so visits
+// which span midnight are counted until their logout.
+func (w *watcher) poll(today timeutil.Date) ([]string, error) {
+	entries, err := w.follower.Poll(today)
+	if err != nil {
+		return nil, err
+	}
+
+	// Read the registry and the mapping on every poll, because the service
+	// registers new persons while it's running.
+	journals, err := resolvePersons([]journal.Journal{{Date: w.follower.Date, Entries: entries}}, w.dir, w.opts)
+	if err != nil {
+		return nil, err
+	}
+
+	events := make([]string, 0, len(entries))
+	for _, e := range journals[0].Entries {
+		sessions, ok := w.present[e.Location]
+		if !ok {
+			sessions = make(map[string]bool)
+			w.present[e.Location] = sessions
+		}
+
+		if e.Event == journal.Login {
+			sessions[e.SessionID] = true
+		} else {
+			delete(sessions, e.SessionID)
+		}
+
+		events = append(events, formatEvent(e))
+	}
+
+	w.recent = append(w.recent, events...)
+	if len(w.recent) > recentEvents {
+		w.recent = w.recent[len(w.recent)-recentEvents:]
+	}
+
+	return events, nil
+}
+
+// formatEvent returns the time, the event, the location and the name of the
+// person of the JournalEntry e as line.
+func formatEvent(e journal.JournalEntry) string {
+	event := "login"
+	if e.Event == journal.Logout {
+		event = "logout"
+	}
+
+	name := strings.TrimSpace(e.Person.FirstName + " " + e.Person.LastName)
+	return fmt.Sprintf("%v  %-6v  %v  %v", e.Timestamp.Format("15:04:05"), event, e.Location, name)
+}
+
+// occupancyTable returns the number of persons present at every location in
+// alphabetical order of the locations, followed by the recent events.
+func (w *watcher) occupancyTable(now time.Time) string {
+	locs := make([]journal.Location, 0, len(w.present))
+	width := len("Location")
+	for l := range w.present {
+		locs = append(locs, l)
+		if n := len([]rune(string(l))); n > width {
+			width = n
+		}
+	}
+
+	sort.Slice(locs, func(i, j int) bool {
+		return locs[i] < locs[j]
+	})
+
+	table := fmt.Sprintf("Occupancy on %v, updated %v\n\n", w.follower.Date, now.Format("15:04:05"))
+	table += fmt.Sprintf("%-*v  %v\n", width, "Location", "Present")
+	total := 0
+	for _, l := range locs {
+		table += fmt.Sprintf("%-*v  %7v\n", width, l, len(w.present[l]))
+		total += len(w.present[l])
+	}
+
+	table += fmt.Sprintf("%-*v  %7v\n", width, "Total", total)
+	if len(w.recent) > 0 {
+		table += "\nLast events:\n" + strings.Join(w.recent, "\n") + "\n"
+	}
+
+	return table
+}
+
+// watch polls the journal files of the dir directory every interval and
+// writes the new events to out. The events already in the journal file when
+// starting are only printed with history. With occupancy, the screen shows the
+// occupancy table and the last events instead, redrawn on every change.
+//
+// watch runs until the journal file cannot be read or parsed.
+func watch(dir string, opts readOptions, interval time.Duration, occupancy bool, history bool, out io.Writer) error {
+	w := newWatcher(dir, timeutil.Now().Date(), opts)
+	for first := true; ; first = false {
+		events, err := w.poll(timeutil.Now().Date())
+		if err != nil {
+			return err
+		}
+
+		switch {
+		case occupancy && (first || len(events) > 0):
+			// Clear the screen before drawing the table.
+			fmt.Fprint(out, "\x1b[H\x1b[2J"+w.occupancyTable(time.Now()))
+		case !occupancy && (!first || history):
+			for _, e := range events {
+				fmt.Fprintln(out, e)
+			}
+		}
+
+		time.Sleep(interval)
+	}
+}
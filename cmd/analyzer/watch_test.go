@@ -0,0 +1,86 @@
+// This source file is part of the attendance list project
+// as a part of the go lecture by H. Neemann.
+// For this reason you have no permission to use, modify or
+// share this code without the agreement of the authors.
+//
+// Matriculation numbers of the authors: 5703004, 5736465
+
+package main
+
+import (
+	"path"
+	"testing"
+	"time"
+
+	"github.com/dateiexplorer/attendancelist/internal/journal"
+	"github.com/dateiexplorer/attendancelist/internal/timeutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWatcherPoll(t *testing.T) {
+	dir := t.TempDir()
+	date := timeutil.NewDate(2021, 10, 15)
+	w := newWatcher(dir, date, readOptions{})
+
+	hans := journal.NewPerson("Hans", "Müller", "Feldweg", "12", "74722", "Buchen")
+	otto := journal.NewPerson("Otto", "Normalverbraucher", "Dieselstraße", "52", "70376", "Stuttgart")
+	entries := []journal.JournalEntry{
+		journal.NewJournalEntry(timeutil.NewTimestamp(2021, 10, 15, 23, 0, 0), "a", journal.Login, "DHBW Mosbach", hans),
+		journal.NewJournalEntry(timeutil.NewTimestamp(2021, 10, 15, 23, 30, 0), "b", journal.Login, "Alte Mälzerei", otto),
+		journal.NewJournalEntry(timeutil.NewTimestamp(2021, 10, 16, 0, 30, 0), "a", journal.Logout, "DHBW Mosbach", hans),
+	}
+
+	for _, e := range entries[:2] {
+		assert.NoError(t, journal.WriteToJournalFile(dir, &e))
+	}
+
+	events, err := w.poll(date)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{
+		"23:00:00  login   DHBW Mosbach  Hans Müller",
+		"23:30:00  login   Alte Mälzerei  Otto Normalverbraucher",
+	}, events)
+
+	// The visit of Hans spans midnight, he is present until his logout in the
+	// journal file of the next day.
+	events, err = w.poll(date.AddDays(1))
+	assert.NoError(t, err)
+	assert.Empty(t, events)
+
+	assert.NoError(t, journal.WriteToJournalFile(dir, &entries[2]))
+	events, err = w.poll(date.AddDays(1))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"00:30:00  logout  DHBW Mosbach  Hans Müller"}, events)
+
+	now := time.Date(2021, 10, 16, 0, 30, 5, 0, time.UTC)
+	assert.Equal(t, "Occupancy on 2021-10-16, updated 00:30:05\n\n"+
+		"Location       Present\n"+
+		"Alte Mälzerei        1\n"+
+		"DHBW Mosbach         0\n"+
+		"Total                1\n\n"+
+		"Last events:\n"+
+		"23:00:00  login   DHBW Mosbach  Hans Müller\n"+
+		"23:30:00  login   Alte Mälzerei  Otto Normalverbraucher\n"+
+		"00:30:00  logout  DHBW Mosbach  Hans Müller\n", w.occupancyTable(now))
+}
+
+func TestWatcherPollPseudonymized(t *testing.T) {
+	dir := t.TempDir()
+	date := timeutil.NewDate(2021, 10, 15)
+	hans := journal.NewPerson("Hans", "Müller", "Feldweg", "12", "74722", "Buchen")
+	ps, err := journal.NewPseudonymizer(path.Join(dir, journal.RegistryFileName), func(p journal.Person) (string, error) {
+		return "p1", nil
+	})
+	assert.NoError(t, err)
+
+	e := journal.NewJournalEntry(timeutil.NewTimestamp(2021, 10, 15, 9, 0, 0), "a", journal.Login, "DHBW Mosbach", hans)
+	assert.NoError(t, ps.WriteToJournalFile(dir, &e))
+
+	events, err := newWatcher(dir, date, readOptions{}).poll(date)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"09:00:00  login   DHBW Mosbach  Hans Müller"}, events)
+
+	events, err = newWatcher(dir, date, readOptions{idsOnly: true}).poll(date)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"09:00:00  login   DHBW Mosbach  p1"}, events)
+}
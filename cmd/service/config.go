@@ -10,6 +10,8 @@ package main
 import (
 	"fmt"
 	"net/url"
+
+	"github.com/dateiexplorer/attendancelist/internal/journal"
 )
 
 type URLValue struct {
@@ -36,6 +38,8 @@ type config struct {
 	qrPort, loginPort, expireDuration int
 	loginURL                          *url.URL
 	locationsPath, certPath, keyPath  string
+	journalKeyPath, hashKeyPath       string
+	pseudonymize                      bool
 }
 
 func (c *config) validate() (bool, []error) {
@@ -55,5 +59,19 @@ func (c *config) validate() (bool, []error) {
 		errs = append(errs, fmt.Errorf("the path to the SSL/TLS key file must be set, e.g. -key key.pem"))
 	}
 
+	if c.journalKeyPath != "" && c.pseudonymize {
+		errs = append(errs, fmt.Errorf("the contact data can either be encrypted or pseudonymized"))
+	}
+
+	if (c.journalKeyPath != "" || c.pseudonymize) && c.hashKeyPath == "" {
+		errs = append(errs, fmt.Errorf("the path to the secret key of the person hash must be set for encrypted or pseudonymized journal files, e.g. -hash-key hash.key"))
+	}
+
+	// Whoever has the journal files must not have the key, otherwise a guessed
+	// person can be confirmed by its hash.
+	if c.hashKeyPath != "" && journal.IsInDir(c.hashKeyPath, journalStorePath) {
+		errs = append(errs, fmt.Errorf("the secret key of the person hash must not be stored with the journal files in %v", journalStorePath))
+	}
+
 	return len(errs) == 0, errs
 }
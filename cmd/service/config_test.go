@@ -69,3 +69,54 @@ func TestConfigValidateExpireDurationIsNegative(t *testing.T) {
 	assert.Equal(t, 1, len(errs))
 	assert.False(t, valid)
 }
+
+func TestConfigValidateEncryptedAndPseudonymized(t *testing.T) {
+	url, err := url.Parse("https://login")
+	assert.NoError(t, err)
+
+	config := config{
+		qrPort: 4443, loginPort: 4444, expireDuration: 30,
+		loginURL:      url,
+		locationsPath: "locations.xml", certPath: "cert.pem", keyPath: "key.pem",
+		journalKeyPath: "journal.pem", hashKeyPath: "hash.key", pseudonymize: true,
+	}
+
+	valid, errs := config.validate()
+	assert.Equal(t, 1, len(errs))
+	assert.False(t, valid)
+}
+
+func TestConfigValidateHashKey(t *testing.T) {
+	url, err := url.Parse("https://login")
+	assert.NoError(t, err)
+
+	config := config{
+		qrPort: 4443, loginPort: 4444, expireDuration: 30,
+		loginURL:      url,
+		locationsPath: "locations.xml", certPath: "cert.pem", keyPath: "key.pem",
+		journalKeyPath: "journal.pem",
+	}
+
+	valid, errs := config.validate()
+	assert.Equal(t, 1, len(errs))
+	assert.False(t, valid)
+
+	config.journalKeyPath, config.pseudonymize = "", true
+	valid, errs = config.validate()
+	assert.Equal(t, 1, len(errs))
+	assert.False(t, valid)
+
+	for _, hashKeyPath := range []string{"data/hash.key", "./data/keys/hash.key"} {
+		config.hashKeyPath = hashKeyPath
+		valid, errs = config.validate()
+		assert.Equal(t, 1, len(errs), hashKeyPath)
+		assert.False(t, valid)
+	}
+
+	for _, hashKeyPath := range []string{"hash.key", "data.key", "../data/hash.key"} {
+		config.hashKeyPath = hashKeyPath
+		valid, errs = config.validate()
+		assert.Equal(t, 0, len(errs), hashKeyPath)
+		assert.True(t, valid)
+	}
+}
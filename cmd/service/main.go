@@ -9,10 +9,12 @@ package main
 
 import (
 	"embed"
+	"encoding/hex"
 	"flag"
 	"fmt"
 	"net/url"
 	"os"
+	"path"
 	"time"
 
 	"github.com/dateiexplorer/attendancelist/internal/journal"
@@ -31,8 +33,9 @@ var content embed.FS
 func main() {
 	// Configuration
 	var loginURL, _ = url.Parse("https://localhost:4444/access")
-	var locationsPath, certPath, keyPath string
+	var locationsPath, certPath, keyPath, journalKeyPath, hashKeyPath string
 	var loginPort, qrPort, expireDuration int
+	var pseudonymize bool
 
 	flag.IntVar(&expireDuration, "expire", 60, "The expire duration for an access token in seconds")
 	flag.IntVar(&qrPort, "qr-port", 4443, "The port the QR code service should running on")
@@ -41,6 +44,9 @@ func main() {
 	flag.StringVar(&locationsPath, "locations", "", "The locations XML file `path` in the file system")
 	flag.StringVar(&certPath, "cert", "", "The `path` to the SSL/TLS certificate file")
 	flag.StringVar(&keyPath, "key", "", "The `path` to the SSL/TLS key file")
+	flag.StringVar(&journalKeyPath, "journal-key", "", "The `path` to a PEM encoded RSA or EC public key, if set the contact data in the journal files is encrypted with it")
+	flag.StringVar(&hashKeyPath, "hash-key", "", "The `path` to the secret key of the person hash in encrypted or pseudonymized journal files, created if it doesn't exist, must not be stored with the journal files")
+	flag.BoolVar(&pseudonymize, "pseudonymize", false, "Write only a pseudonymous person ID to the journal files and the contact data once to a separate registry file")
 	flag.Parse()
 
 	config := config{
@@ -51,6 +57,9 @@ func main() {
 		locationsPath:  locationsPath,
 		certPath:       certPath,
 		keyPath:        keyPath,
+		journalKeyPath: journalKeyPath,
+		hashKeyPath:    hashKeyPath,
+		pseudonymize:   pseudonymize,
 	}
 
 	// Validate configuration
@@ -68,9 +77,50 @@ func main() {
 		panic(fmt.Errorf("locations not loaded: %w", err))
 	}
 
+	// Decide how the contact data is written to the journal files
+	write := journal.WriteToJournalFile
+	if journalKeyPath != "" {
+		pub, err := journal.ReadPublicKey(journalKeyPath)
+		if err != nil {
+			panic(fmt.Errorf("journal key not loaded: %w", err))
+		}
+
+		hashKey, err := journal.LoadSecretKey(hashKeyPath)
+		if err != nil {
+			panic(fmt.Errorf("hash key not loaded: %w", err))
+		}
+
+		enc, err := journal.NewPersonEncrypter(pub, hashKey)
+		if err != nil {
+			panic(fmt.Errorf("journal key not loaded: %w", err))
+		}
+
+		write = func(dir string, e *journal.JournalEntry) error {
+			return journal.WriteEncryptedToJournalFile(dir, e, enc)
+		}
+	}
+
+	if pseudonymize {
+		hashKey, err := journal.LoadSecretKey(hashKeyPath)
+		if err != nil {
+			panic(fmt.Errorf("hash key not loaded: %w", err))
+		}
+
+		// The key is hex encoded, because the hash marshals it as JSON string.
+		secret := hex.EncodeToString(hashKey)
+		ps, err := journal.NewPseudonymizer(path.Join(journalStorePath, journal.RegistryFileName), func(p journal.Person) (string, error) {
+			return web.Hash(p, secret)
+		})
+		if err != nil {
+			panic(fmt.Errorf("registry not loaded: %w", err))
+		}
+
+		write = ps.WriteToJournalFile
+	}
+
 	// Init journal writer
 	// Journals written automatically
-	journalWriter := runJournalWriter(maxConcurrentRequests, journalStorePath)
+	journalWriter := runJournalWriter(maxConcurrentRequests, journalStorePath, write)
 
 	// Init session manager
 	openSessions, sessionQueue, sessionIDs := web.RunSessionManager(journalWriter, tokenLength)
@@ -87,7 +137,10 @@ func main() {
 	block <- true
 }
 
-func runJournalWriter(maxConcurrentRequests int, path string) chan<- journal.JournalEntry {
+// runJournalWriter writes every JournalEntry sent to the returned channel with
+// the write function to the journal files in the path directory. The directory
+// is locked while writing, so the analyzer can replace the files meanwhile.
+func runJournalWriter(maxConcurrentRequests int, path string, write func(dir string, e *journal.JournalEntry) error) chan<- journal.JournalEntry {
 	journalWriter := make(chan journal.JournalEntry, maxConcurrentRequests)
 
 	go func() {
@@ -99,9 +152,20 @@ func runJournalWriter(maxConcurrentRequests int, path string) chan<- journal.Jou
 				}
 			}
 
-			if err := journal.WriteToJournalFile(path, &entry); err != nil {
+			// The entry is written even without the lock, it's better than
+			// losing it.
+			unlock, err := journal.LockDir(path)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "error while locking journal directory: %v\n", err)
+			}
+
+			if err := write(path, &entry); err != nil {
 				fmt.Fprintln(os.Stderr, "error while write journal file: %w", err)
 			}
+
+			if unlock != nil {
+				unlock()
+			}
 		}
 	}()
 
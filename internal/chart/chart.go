@@ -0,0 +1,314 @@
+// This source file is part of the attendance list project
+// as a part of the go lecture by H. Neemann.
+// For this reason you have no permission to use, modify or
+// share this code without the agreement of the authors.
+//
+// Matriculation numbers of the authors: 5703004, 5736465
+
+// Package chart provides functionality for rendering occupancy charts as SVG
+// or PNG images.
+package chart
+
+import (
+	"fmt"
+	"image/color"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/dateiexplorer/attendancelist/internal/journal"
+	"github.com/dateiexplorer/attendancelist/internal/timeutil"
+)
+
+// The size of the charts and the space around the plot area in pixels.
+const (
+	width       = 960
+	height      = 540
+	margin      = 20
+	titleHeight = 50
+	axisHeight  = 50
+	axisWidth   = 60
+	// The height of a row of the heatmap.
+	rowHeight = 30
+	// The maximum number of bars of the dwell time distribution.
+	maxBins = 24
+)
+
+var (
+	black = color.RGBA{0x00, 0x00, 0x00, 0xff}
+	white = color.RGBA{0xff, 0xff, 0xff, 0xff}
+	gray  = color.RGBA{0xdd, 0xdd, 0xdd, 0xff}
+	// The color of the highest value of the heatmap.
+	hot = color.RGBA{0xb2, 0x18, 0x2b, 0xff}
+	// The colors of the locations in the occupancy chart.
+	palette = []color.RGBA{
+		{0x1f, 0x77, 0xb4, 0xff},
+		{0xff, 0x7f, 0x0e, 0xff},
+		{0x2c, 0xa0, 0x2c, 0xff},
+		{0xd6, 0x27, 0x28, 0xff},
+		{0x94, 0x67, 0xbd, 0xff},
+		{0x8c, 0x56, 0x4b, 0xff},
+		{0xe3, 0x77, 0xc2, 0xff},
+		{0x7f, 0x7f, 0x7f, 0xff},
+	}
+)
+
+// A plot is the area of a Drawing between the axes.
+type plot struct {
+	d                        *Drawing
+	left, top, right, bottom float64
+}
+
+// newPlot returns a Drawing with the title and its plot area.
+func newPlot(w, h int, title string, left, right float64) plot {
+	d := NewDrawing(w, h)
+	d.Text(float64(w)/2, titleHeight/2, title, Middle, black)
+	return plot{d, left, titleHeight, float64(w) - right, float64(h) - axisHeight}
+}
+
+// yAxis draws the y axis with grid lines for the values from 0 to max and
+// returns the function which maps a value to its y coordinate.
+func (p plot) yAxis(max int, label string) func(v float64) float64 {
+	step := niceStep(float64(max), 5)
+	top := math.Max(step, math.Ceil(float64(max)/step)*step)
+	y := func(v float64) float64 {
+		return p.bottom - v/top*(p.bottom-p.top)
+	}
+
+	for v := 0.0; v <= top; v += step {
+		p.d.Line(p.left, y(v), p.right, y(v), gray, 1)
+		p.d.Text(p.left-8, y(v), fmt.Sprint(v), End, black)
+	}
+
+	p.d.Line(p.left, p.top, p.left, p.bottom, black, 1)
+	p.d.Text(p.left, p.top-charHeight-2, label, Middle, black)
+	return y
+}
+
+// niceStep returns a step of 1, 2 or 5 times a power of ten, so that the range
+// from 0 to max is divided into about n steps. The step is at least 1.
+func niceStep(max float64, n int) float64 {
+	raw := max / float64(n)
+	if raw <= 1 {
+		return 1
+	}
+
+	magnitude := math.Pow(10, math.Floor(math.Log10(raw)))
+	for _, f := range []float64{1, 2, 5} {
+		if raw <= f*magnitude {
+			return f * magnitude
+		}
+	}
+
+	return 10 * magnitude
+}
+
+// Occupancy returns a chart of the number of visitors over time from from to
+// to with a line for every Location of the OccupancyTimeline t.
+func Occupancy(title string, t journal.OccupancyTimeline, from, to timeutil.Timestamp) *Drawing {
+	steps := make(map[journal.Location][]journal.OccupancyStep)
+	locations := make([]journal.Location, 0)
+	legendWidth, max := 0.0, 0
+	for _, s := range t {
+		if _, ok := steps[s.Location]; !ok {
+			locations = append(locations, s.Location)
+			legendWidth = math.Max(legendWidth, textWidth(string(s.Location)))
+		}
+
+		steps[s.Location] = append(steps[s.Location], s)
+		if s.Count > max {
+			max = s.Count
+		}
+	}
+
+	sort.Slice(locations, func(i, j int) bool {
+		return locations[i] < locations[j]
+	})
+
+	p := newPlot(width, height, title, axisWidth, legendWidth+3*margin+charWidth)
+	y := p.yAxis(max, "Visitors")
+	span := to.Sub(from.Time)
+	x := func(ts timeutil.Timestamp) float64 {
+		return p.left + float64(ts.Sub(from.Time))/float64(span)*(p.right-p.left)
+	}
+
+	// Up to two days the ticks show the time, otherwise the date. The
+	// shortest interval whose labels don't overlap is used.
+	layout := "15:04"
+	intervals := []time.Duration{time.Hour, 2 * time.Hour, 3 * time.Hour, 4 * time.Hour, 6 * time.Hour, 12 * time.Hour}
+	if span > 48*time.Hour {
+		layout = "01-02"
+		intervals = []time.Duration{24 * time.Hour, 48 * time.Hour, 7 * 24 * time.Hour, 14 * 24 * time.Hour, 28 * 24 * time.Hour}
+	}
+
+	tick := intervals[len(intervals)-1]
+	for _, i := range intervals {
+		if float64(i)/float64(span)*(p.right-p.left) >= textWidth(layout)+charWidth {
+			tick = i
+			break
+		}
+	}
+
+	for ts := from; !ts.After(to.Time); ts = (timeutil.Timestamp{Time: ts.Add(tick)}) {
+		p.d.Line(x(ts), p.bottom, x(ts), p.bottom+5, black, 1)
+		p.d.Text(x(ts), p.bottom+5+charHeight, ts.Format(layout), Middle, black)
+	}
+
+	p.d.Line(p.left, p.bottom, p.right, p.bottom, black, 1)
+
+	for i, l := range locations {
+		c := palette[i%len(palette)]
+		lastX, lastY := p.left, y(0)
+		for _, s := range steps[l] {
+			sx := x(s.Timestamp)
+			p.d.Line(lastX, lastY, sx, lastY, c, 2)
+			p.d.Line(sx, lastY, sx, y(float64(s.Count)), c, 2)
+			lastX, lastY = sx, y(float64(s.Count))
+		}
+
+		p.d.Line(lastX, lastY, p.right, lastY, c, 2)
+
+		legendY := p.top + float64(i)*1.5*charHeight
+		p.d.Rect(p.right+margin, legendY-charHeight/2, charWidth, charHeight, c)
+		p.d.Text(p.right+2*margin+charWidth, legendY, string(l), Start, black)
+	}
+
+	return p.d
+}
+
+// Heatmap returns a chart with a row for every Location of the OccupancyList l
+// and a column for every hour of the day. Every cell shows the number of
+// visitors of the Location in this hour, summed up over all Occupancies of
+// the Location.
+func Heatmap(title string, l journal.OccupancyList) *Drawing {
+	hourly := make(map[journal.Location]*[24]int)
+	locations := make([]journal.Location, 0)
+	labelWidth, max := 0.0, 0
+	for _, o := range l {
+		sums, ok := hourly[o.Location]
+		if !ok {
+			sums = &[24]int{}
+			hourly[o.Location] = sums
+			locations = append(locations, o.Location)
+			labelWidth = math.Max(labelWidth, textWidth(string(o.Location)))
+		}
+
+		for h, n := range o.Hourly {
+			sums[h] += n
+			if sums[h] > max {
+				max = sums[h]
+			}
+		}
+	}
+
+	sort.Slice(locations, func(i, j int) bool {
+		return locations[i] < locations[j]
+	})
+
+	p := newPlot(width, titleHeight+len(locations)*rowHeight+axisHeight, title, labelWidth+2*margin, margin)
+	cellWidth := (p.right - p.left) / 24
+	for h := 0; h < 24; h++ {
+		p.d.Text(p.left+(float64(h)+0.5)*cellWidth, p.bottom+axisHeight/2, fmt.Sprintf("%02d", h), Middle, black)
+	}
+
+	for i, l := range locations {
+		top := p.top + float64(i)*rowHeight
+		p.d.Text(p.left-margin, top+rowHeight/2, string(l), End, black)
+		for h, n := range hourly[l] {
+			intensity := 0.0
+			if max > 0 {
+				intensity = float64(n) / float64(max)
+			}
+
+			left := p.left + float64(h)*cellWidth
+			p.d.Rect(left, top, cellWidth-1, rowHeight-1, blend(white, hot, intensity))
+
+			label := black
+			if intensity > 0.6 {
+				label = white
+			}
+
+			if n > 0 && textWidth(fmt.Sprint(n)) < cellWidth {
+				p.d.Text(left+cellWidth/2, top+rowHeight/2, fmt.Sprint(n), Middle, label)
+			}
+		}
+	}
+
+	return p.d
+}
+
+// blend returns the color between a and b, at 0 a and at 1 b.
+func blend(a, b color.RGBA, f float64) color.RGBA {
+	mix := func(x, y uint8) uint8 {
+		return uint8(math.Round(float64(x) + (float64(y)-float64(x))*f))
+	}
+
+	return color.RGBA{mix(a.R, b.R), mix(a.G, b.G), mix(a.B, b.B), 0xff}
+}
+
+// Dwell returns a histogram of the durations with bars of the width bin. The
+// number of bars is limited, the last bar holds all longer durations.
+func Dwell(title string, durations []time.Duration, bin time.Duration) *Drawing {
+	var longest time.Duration
+	for _, d := range durations {
+		if d > longest {
+			longest = d
+		}
+	}
+
+	bins := int(longest/bin) + 1
+	if bins > maxBins {
+		bins = maxBins
+	}
+
+	counts := make([]int, bins)
+	max := 0
+	for _, d := range durations {
+		i := int(d / bin)
+		if i >= bins {
+			i = bins - 1
+		}
+
+		counts[i]++
+		if counts[i] > max {
+			max = counts[i]
+		}
+	}
+
+	p := newPlot(width, height, title, axisWidth, margin)
+	y := p.yAxis(max, "Visits")
+	barWidth := (p.right - p.left) / float64(bins)
+
+	// Only every n-th bar is labeled if the labels don't fit below the bars.
+	labels := make([]string, bins)
+	every := 1
+	for i := range labels {
+		labels[i] = formatDuration(time.Duration(i) * bin)
+		if n := int(math.Ceil((textWidth(labels[i]) + charWidth) / barWidth)); n > every {
+			every = n
+		}
+	}
+
+	for i, n := range counts {
+		left := p.left + float64(i)*barWidth
+		p.d.Rect(left+1, y(float64(n)), barWidth-2, p.bottom-y(float64(n)), palette[0])
+		if i%every == 0 {
+			p.d.Line(left, p.bottom, left, p.bottom+5, black, 1)
+			p.d.Text(left, p.bottom+5+charHeight, labels[i], Middle, black)
+		}
+	}
+
+	label := "Time of stay (h:mm)"
+	if int(longest/bin) >= bins {
+		label += ", the last bar includes all longer stays"
+	}
+
+	p.d.Line(p.left, p.bottom, p.right, p.bottom, black, 1)
+	p.d.Text((p.left+p.right)/2, p.bottom+axisHeight-charHeight/2, label, Middle, black)
+	return p.d
+}
+
+// formatDuration returns the duration d in the form h:mm.
+func formatDuration(d time.Duration) string {
+	return fmt.Sprintf("%d:%02d", int(d.Hours()), int(d.Minutes())%60)
+}
@@ -0,0 +1,98 @@
+// This source file is part of the attendance list project
+// as a part of the go lecture by H. Neemann.
+// For this reason you have no permission to use, modify or
+// share this code without the agreement of the authors.
+//
+// Matriculation numbers of the authors: 5703004, 5736465
+
+// Package chart provides functionality for rendering occupancy charts as SVG
+// or PNG images.
+package chart
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dateiexplorer/attendancelist/internal/journal"
+	"github.com/dateiexplorer/attendancelist/internal/timeutil"
+	"github.com/stretchr/testify/assert"
+)
+
+// svg returns the Drawing d as SVG image.
+func svg(t *testing.T, d *Drawing) string {
+	var b bytes.Buffer
+	assert.NoError(t, d.WriteSVG(&b))
+	return b.String()
+}
+
+func TestNiceStep(t *testing.T) {
+	assert.Equal(t, 1.0, niceStep(0, 5))
+	assert.Equal(t, 1.0, niceStep(5, 5))
+	assert.Equal(t, 2.0, niceStep(7, 5))
+	assert.Equal(t, 5.0, niceStep(23, 5))
+	assert.Equal(t, 10.0, niceStep(42, 5))
+	assert.Equal(t, 200.0, niceStep(1000, 5))
+}
+
+func TestOccupancy(t *testing.T) {
+	timeline := journal.OccupancyTimeline{
+		{Location: "DHBW Mosbach", Timestamp: timeutil.NewTimestamp(2021, 10, 15, 6, 0, 0), Count: 1},
+		{Location: "DHBW Mosbach", Timestamp: timeutil.NewTimestamp(2021, 10, 15, 12, 0, 0), Count: 0},
+		{Location: "Alte Mälzerei", Timestamp: timeutil.NewTimestamp(2021, 10, 15, 18, 0, 0), Count: 3},
+	}
+
+	from := timeutil.NewTimestamp(2021, 10, 15, 0, 0, 0)
+	s := svg(t, Occupancy("Occupancy 2021-10-15", timeline, from, timeutil.NewTimestamp(2021, 10, 16, 0, 0, 0)))
+	assert.Contains(t, s, ">Occupancy 2021-10-15</text>")
+	assert.Contains(t, s, ">03:00</text>")
+	assert.Contains(t, s, ">Visitors</text>")
+
+	// The legend is ordered by the name of the location.
+	assert.Less(t, strings.Index(s, ">Alte Mälzerei</text>"), strings.Index(s, ">DHBW Mosbach</text>"))
+
+	// Over more than two days the ticks show the date.
+	s = svg(t, Occupancy("", timeline, from, timeutil.NewTimestamp(2021, 10, 25, 0, 0, 0)))
+	assert.Contains(t, s, ">10-17</text>")
+	assert.NotContains(t, s, ">03:00</text>")
+}
+
+func TestHeatmap(t *testing.T) {
+	list := journal.OccupancyList{
+		{Location: "DHBW Mosbach", Hourly: [24]int{8: 2, 9: 4}},
+		{Location: "Alte Mälzerei", Hourly: [24]int{20: 1}},
+		{Location: "DHBW Mosbach", Hourly: [24]int{9: 4}},
+	}
+
+	d := Heatmap("Visitors per hour", list)
+	assert.Equal(t, titleHeight+2*rowHeight+axisHeight, d.Height)
+
+	s := svg(t, d)
+	assert.Contains(t, s, ">23</text>")
+	// The hours of both days are summed up, the maximum has the hot color.
+	assert.Contains(t, s, `fill="`+hex(hot)+`"`)
+	assert.Contains(t, s, `fill="#ffffff">8</text>`)
+	assert.Contains(t, s, `fill="#000000">2</text>`)
+}
+
+func TestDwell(t *testing.T) {
+	durations := []time.Duration{10 * time.Minute, 20 * time.Minute, 40 * time.Minute, 30 * time.Hour}
+
+	s := svg(t, Dwell("Time of stay", durations, 30*time.Minute))
+	assert.Contains(t, s, ">0:00</text>")
+	assert.Contains(t, s, ">Visits</text>")
+	// There are at most maxBins bars, the last one holds the longer visits.
+	assert.Contains(t, s, ">11:00</text>")
+	assert.Contains(t, s, "the last bar includes all longer stays")
+
+	s = svg(t, Dwell("Time of stay", durations[:3], time.Hour))
+	assert.Contains(t, s, ">0:00</text>")
+	assert.NotContains(t, s, "the last bar")
+}
+
+func TestFormatDuration(t *testing.T) {
+	assert.Equal(t, "0:00", formatDuration(0))
+	assert.Equal(t, "1:30", formatDuration(90*time.Minute))
+	assert.Equal(t, "25:05", formatDuration(25*time.Hour+5*time.Minute))
+}
@@ -0,0 +1,200 @@
+// This source file is part of the attendance list project
+// as a part of the go lecture by H. Neemann.
+// For this reason you have no permission to use, modify or
+// share this code without the agreement of the authors.
+//
+// Matriculation numbers of the authors: 5703004, 5736465
+
+// Package chart provides functionality for rendering occupancy charts as SVG
+// or PNG images.
+package chart
+
+import (
+	"bufio"
+	"encoding/xml"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"io"
+	"math"
+	"strings"
+)
+
+// An Anchor describes which point of a text is placed at its position.
+type Anchor int
+
+const (
+	Start Anchor = iota
+	Middle
+	End
+)
+
+// A Drawing is a list of shapes on a white canvas of Width x Height pixels
+// which can be written as SVG or PNG image.
+type Drawing struct {
+	Width, Height int
+	elements      []element
+}
+
+// An element is a shape of a Drawing which can be written in both formats.
+type element interface {
+	writeSVG(w io.Writer)
+	draw(img *image.RGBA)
+}
+
+// NewDrawing returns an empty Drawing of the given size.
+func NewDrawing(width, height int) *Drawing {
+	return &Drawing{Width: width, Height: height}
+}
+
+// Rect adds a rectangle with the upper left corner at x, y filled with the
+// color fill.
+func (d *Drawing) Rect(x, y, width, height float64, fill color.RGBA) {
+	d.elements = append(d.elements, rect{x, y, width, height, fill})
+}
+
+// Line adds a line from x1, y1 to x2, y2.
+func (d *Drawing) Line(x1, y1, x2, y2 float64, stroke color.RGBA, width float64) {
+	d.elements = append(d.elements, line{x1, y1, x2, y2, stroke, width})
+}
+
+// Text adds the string s, vertically centered at y. The anchor determines
+// whether x is the start, the middle or the end of the text.
+func (d *Drawing) Text(x, y float64, s string, anchor Anchor, fill color.RGBA) {
+	d.elements = append(d.elements, text{x, y, s, anchor, fill})
+}
+
+// WriteSVG writes the Drawing as SVG image to w.
+func (d *Drawing) WriteSVG(w io.Writer) error {
+	b := bufio.NewWriter(w)
+	fmt.Fprintf(b, `<svg xmlns="http://www.w3.org/2000/svg" width="%v" height="%v" viewBox="0 0 %v %v">`+"\n",
+		d.Width, d.Height, d.Width, d.Height)
+	fmt.Fprintf(b, `<rect width="%v" height="%v" fill="#ffffff"/>`+"\n", d.Width, d.Height)
+	for _, e := range d.elements {
+		e.writeSVG(b)
+	}
+
+	fmt.Fprintln(b, "</svg>")
+	if err := b.Flush(); err != nil {
+		return fmt.Errorf("cannot write SVG image: %w", err)
+	}
+
+	return nil
+}
+
+// WritePNG writes the Drawing as PNG image to w.
+func (d *Drawing) WritePNG(w io.Writer) error {
+	img := image.NewRGBA(image.Rect(0, 0, d.Width, d.Height))
+	draw.Draw(img, img.Bounds(), image.NewUniform(color.White), image.Point{}, draw.Src)
+	for _, e := range d.elements {
+		e.draw(img)
+	}
+
+	if err := png.Encode(w, img); err != nil {
+		return fmt.Errorf("cannot write PNG image: %w", err)
+	}
+
+	return nil
+}
+
+// hex returns the color c in the notation #rrggbb.
+func hex(c color.RGBA) string {
+	return fmt.Sprintf("#%02x%02x%02x", c.R, c.G, c.B)
+}
+
+type rect struct {
+	x, y, width, height float64
+	fill                color.RGBA
+}
+
+func (r rect) writeSVG(w io.Writer) {
+	fmt.Fprintf(w, `<rect x="%.1f" y="%.1f" width="%.1f" height="%.1f" fill="%v"/>`+"\n", r.x, r.y, r.width, r.height, hex(r.fill))
+}
+
+func (r rect) draw(img *image.RGBA) {
+	bounds := image.Rect(round(r.x), round(r.y), round(r.x+r.width), round(r.y+r.height))
+	draw.Draw(img, bounds, image.NewUniform(r.fill), image.Point{}, draw.Src)
+}
+
+type line struct {
+	x1, y1, x2, y2 float64
+	stroke         color.RGBA
+	width          float64
+}
+
+func (l line) writeSVG(w io.Writer) {
+	fmt.Fprintf(w, `<line x1="%.1f" y1="%.1f" x2="%.1f" y2="%.1f" stroke="%v" stroke-width="%.1f"/>`+"\n",
+		l.x1, l.y1, l.x2, l.y2, hex(l.stroke), l.width)
+}
+
+// draw sets the pixels of a square brush of the width of the line at every
+// step from one end to the other.
+func (l line) draw(img *image.RGBA) {
+	steps := int(math.Max(math.Abs(l.x2-l.x1), math.Abs(l.y2-l.y1)))
+	if steps == 0 {
+		steps = 1
+	}
+
+	size := int(math.Max(1, math.Round(l.width)))
+	for i := 0; i <= steps; i++ {
+		x := l.x1 + (l.x2-l.x1)*float64(i)/float64(steps)
+		y := l.y1 + (l.y2-l.y1)*float64(i)/float64(steps)
+		left, top := round(x-float64(size)/2), round(y-float64(size)/2)
+		draw.Draw(img, image.Rect(left, top, left+size, top+size), image.NewUniform(l.stroke), image.Point{}, draw.Src)
+	}
+}
+
+type text struct {
+	x, y   float64
+	s      string
+	anchor Anchor
+	fill   color.RGBA
+}
+
+func (t text) writeSVG(w io.Writer) {
+	anchor := "start"
+	switch t.anchor {
+	case Middle:
+		anchor = "middle"
+	case End:
+		anchor = "end"
+	}
+
+	var escaped strings.Builder
+	xml.EscapeText(&escaped, []byte(t.s))
+	fmt.Fprintf(w, `<text x="%.1f" y="%.1f" font-family="sans-serif" font-size="%v" text-anchor="%v" dominant-baseline="middle" fill="%v">%v</text>`+"\n",
+		t.x, t.y, charHeight, anchor, hex(t.fill), escaped.String())
+}
+
+// draw renders the text with the bitmap font.
+func (t text) draw(img *image.RGBA) {
+	x := t.x
+	switch t.anchor {
+	case Middle:
+		x -= textWidth(t.s) / 2
+	case End:
+		x -= textWidth(t.s)
+	}
+
+	left, top := round(x), round(t.y-charHeight/2)
+	for i, r := range []rune(t.s) {
+		g := glyph(r)
+		for col := 0; col < glyphWidth; col++ {
+			for row := 0; row < glyphHeight; row++ {
+				if g[col]&(1<<row) == 0 {
+					continue
+				}
+
+				px, py := left+i*charWidth+col*fontScale, top+row*fontScale
+				draw.Draw(img, image.Rect(px, py, px+fontScale, py+fontScale), image.NewUniform(t.fill), image.Point{}, draw.Src)
+			}
+		}
+	}
+}
+
+// round returns the nearest integer of f.
+func round(f float64) int {
+	return int(math.Round(f))
+}
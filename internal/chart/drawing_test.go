@@ -0,0 +1,73 @@
+// This source file is part of the attendance list project
+// as a part of the go lecture by H. Neemann.
+// For this reason you have no permission to use, modify or
+// share this code without the agreement of the authors.
+//
+// Matriculation numbers of the authors: 5703004, 5736465
+
+// Package chart provides functionality for rendering occupancy charts as SVG
+// or PNG images.
+package chart
+
+import (
+	"bytes"
+	"image/color"
+	"image/png"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDrawingWriteSVG(t *testing.T) {
+	d := NewDrawing(100, 50)
+	d.Rect(10, 10, 20, 5, color.RGBA{0xff, 0x00, 0x00, 0xff})
+	d.Line(0, 40, 100, 40, black, 1)
+	d.Text(50, 25, "Alte Mälzerei <&>", Middle, black)
+
+	var b bytes.Buffer
+	assert.NoError(t, d.WriteSVG(&b))
+	assert.Equal(t, `<svg xmlns="http://www.w3.org/2000/svg" width="100" height="50" viewBox="0 0 100 50">
+<rect width="100" height="50" fill="#ffffff"/>
+<rect x="10.0" y="10.0" width="20.0" height="5.0" fill="#ff0000"/>
+<line x1="0.0" y1="40.0" x2="100.0" y2="40.0" stroke="#000000" stroke-width="1.0"/>
+<text x="50.0" y="25.0" font-family="sans-serif" font-size="14" text-anchor="middle" dominant-baseline="middle" fill="#000000">Alte Mälzerei &lt;&amp;&gt;</text>
+</svg>
+`, b.String())
+}
+
+func TestDrawingWritePNG(t *testing.T) {
+	red := color.RGBA{0xff, 0x00, 0x00, 0xff}
+	d := NewDrawing(100, 50)
+	d.Rect(10, 10, 20, 5, red)
+	d.Line(0, 40, 100, 40, black, 1)
+	d.Text(50, 25, "|", Start, black)
+
+	var b bytes.Buffer
+	assert.NoError(t, d.WritePNG(&b))
+
+	img, err := png.Decode(&b)
+	assert.NoError(t, err)
+	assert.Equal(t, 100, img.Bounds().Dx())
+	assert.Equal(t, 50, img.Bounds().Dy())
+
+	rgba := func(x, y int) color.RGBA {
+		return color.RGBAModel.Convert(img.At(x, y)).(color.RGBA)
+	}
+
+	assert.Equal(t, red, rgba(15, 12))
+	assert.Equal(t, white, rgba(15, 20))
+	assert.Equal(t, black, rgba(50, 40))
+	// The bar of the glyph is in its middle column.
+	assert.Equal(t, black, rgba(50+2*fontScale, 25))
+	assert.Equal(t, white, rgba(50, 25))
+}
+
+func TestGlyphs(t *testing.T) {
+	for r := ' '; r <= '~'; r++ {
+		_, ok := glyphs[r]
+		assert.True(t, ok, string(r))
+	}
+
+	assert.Equal(t, glyphs['?'], glyph('€'))
+	assert.Equal(t, float64(3*charWidth), textWidth("Mäh"))
+}
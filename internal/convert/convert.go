@@ -11,7 +11,9 @@
 package convert
 
 import (
+	"bytes"
 	"encoding/csv"
+	"encoding/json"
 	"io"
 )
 
@@ -36,3 +38,51 @@ func ToCSV(w io.Writer, c Converter) error {
 	writer.Flush()
 	return writer.Error()
 }
+
+// ToJSON converts the data from a type which implements convert.Converter in a
+// JSON array. Every entry becomes an object which maps the names of the header
+// to the values of the entry. The keys keep the order of the header.
+//
+// An error returned if the data cannot be written.
+func ToJSON(w io.Writer, c Converter) error {
+	header := c.Header()
+
+	buf := new(bytes.Buffer)
+	buf.WriteString("[")
+	first := true
+	for entry := range c.NextEntry() {
+		if !first {
+			buf.WriteString(",")
+		}
+		first = false
+
+		buf.WriteString("{")
+		for i, value := range entry {
+			if i > 0 {
+				buf.WriteString(",")
+			}
+
+			key := ""
+			if i < len(header) {
+				key = header[i]
+			}
+
+			k, _ := json.Marshal(key)
+			v, _ := json.Marshal(value)
+			buf.Write(k)
+			buf.WriteString(":")
+			buf.Write(v)
+		}
+		buf.WriteString("}")
+	}
+	buf.WriteString("]")
+
+	out := new(bytes.Buffer)
+	if err := json.Indent(out, buf.Bytes(), "", "  "); err != nil {
+		return err
+	}
+
+	out.WriteString("\n")
+	_, err := out.WriteTo(w)
+	return err
+}
@@ -67,3 +67,40 @@ func TestToCSVFailedToWrite(t *testing.T) {
 	err := ToCSV(actual, journal.AttendanceList{})
 	assert.ErrorIs(t, err, errTest)
 }
+
+func TestToJSON(t *testing.T) {
+	expected := `[
+  {
+    "FirstName": "Hans",
+    "LastName": "Müller",
+    "Street": "Feldweg",
+    "Number": "12",
+    "ZipCode": "74722",
+    "City": "Buchen",
+    "Login": "13:40:11",
+    "Logout": ""
+  }
+]
+`
+	list := journal.AttendanceList{
+		journal.NewAttendanceEntry(journal.NewPerson("Hans", "Müller", "Feldweg", "12", "74722", "Buchen"), timeutil.NewTimestamp(2021, 10, 15, 13, 40, 11), timeutil.InvalidTimestamp),
+	}
+
+	actual := new(bytes.Buffer)
+	err := ToJSON(actual, list)
+	assert.NoError(t, err)
+	assert.Equal(t, expected, actual.String())
+}
+
+func TestEmptyAttendanceListToJSON(t *testing.T) {
+	actual := new(bytes.Buffer)
+	err := ToJSON(actual, journal.AttendanceList{})
+	assert.NoError(t, err)
+	assert.Equal(t, "[]\n", actual.String())
+}
+
+func TestToJSONFailedToWrite(t *testing.T) {
+	actual := errorWriter{}
+	err := ToJSON(actual, journal.AttendanceList{})
+	assert.ErrorIs(t, err, errTest)
+}
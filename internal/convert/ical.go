@@ -0,0 +1,182 @@
+// This source file is part of the attendance list project
+// as a part of the go lecture by H. Neemann.
+// For this reason you have no permission to use, modify or
+// share this code without the agreement of the authors.
+//
+// Matriculation numbers of the authors: 5703004, 5736465
+
+// Package convert provides functionality to convert types between multiple formats.
+// A type must implement the Converter interface to prepare it for using this it
+// with this package.
+package convert
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// The layout of a date with time in UTC in the iCalendar format.
+const icsTimeFormat = "20060102T150405Z"
+
+// An Event represents a VEVENT component of an iCalendar file.
+type Event struct {
+	UID         string
+	Summary     string
+	Location    string
+	Description string
+	Start, End  time.Time
+}
+
+// icsEscaper escapes the characters with a special meaning in iCalendar text
+// values.
+var icsEscaper = strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\n", `\n`)
+
+// icsUnescaper reverts the escaping of iCalendar text values.
+var icsUnescaper = strings.NewReplacer(`\\`, `\`, `\;`, ";", `\,`, ",", `\n`, "\n", `\N`, "\n")
+
+// ToICS writes the events as an iCalendar file (RFC 5545) to w.
+// The stamp is the creation time of the file which is required by the format.
+//
+// An error returned if the data cannot be written.
+func ToICS(w io.Writer, events []Event, stamp time.Time) error {
+	writer := bufio.NewWriter(w)
+
+	writeLine := func(line string) {
+		// Lines longer than 75 octets must be folded.
+		for len(line) > 75 {
+			cut := 75
+			// Don't split UTF-8 sequences.
+			for cut > 0 && line[cut]&0xC0 == 0x80 {
+				cut--
+			}
+
+			writer.WriteString(line[:cut] + "\r\n")
+			line = " " + line[cut:]
+		}
+
+		writer.WriteString(line + "\r\n")
+	}
+
+	writeLine("BEGIN:VCALENDAR")
+	writeLine("VERSION:2.0")
+	writeLine("PRODID:-//dateiexplorer//attendancelist//EN")
+	for _, e := range events {
+		writeLine("BEGIN:VEVENT")
+		writeLine("UID:" + icsEscaper.Replace(e.UID))
+		writeLine("DTSTAMP:" + stamp.UTC().Format(icsTimeFormat))
+		writeLine("DTSTART:" + e.Start.UTC().Format(icsTimeFormat))
+		writeLine("DTEND:" + e.End.UTC().Format(icsTimeFormat))
+		writeLine("SUMMARY:" + icsEscaper.Replace(e.Summary))
+		if e.Location != "" {
+			writeLine("LOCATION:" + icsEscaper.Replace(e.Location))
+		}
+		if e.Description != "" {
+			writeLine("DESCRIPTION:" + icsEscaper.Replace(e.Description))
+		}
+		writeLine("END:VEVENT")
+	}
+	writeLine("END:VCALENDAR")
+
+	if err := writer.Flush(); err != nil {
+		return fmt.Errorf("cannot write ics: %w", err)
+	}
+
+	return nil
+}
+
+// FromICS reads all VEVENT components of an iCalendar file (RFC 5545) from r.
+// Only the properties of an Event are read, all other properties and
+// components are ignored.
+//
+// Times are read in UTC, as local time with a TZID parameter or as floating
+// time which is treated as UTC. All-day events start and end at midnight.
+//
+// An error returned if the data cannot be read or a time cannot be parsed.
+func FromICS(r io.Reader) ([]Event, error) {
+	// Unfold all lines first.
+	lines := make([]string, 0)
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if len(lines) > 0 && (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+
+		lines = append(lines, line)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("cannot read ics: %w", err)
+	}
+
+	events := make([]Event, 0)
+	var event *Event
+	for i, line := range lines {
+		sep := strings.Index(line, ":")
+		if sep < 0 {
+			continue
+		}
+
+		params := strings.Split(line[:sep], ";")
+		name, value := strings.ToUpper(params[0]), line[sep+1:]
+
+		switch {
+		case name == "BEGIN" && value == "VEVENT":
+			event = &Event{}
+		case name == "END" && value == "VEVENT" && event != nil:
+			events = append(events, *event)
+			event = nil
+		case event == nil:
+			continue
+		case name == "UID":
+			event.UID = icsUnescaper.Replace(value)
+		case name == "SUMMARY":
+			event.Summary = icsUnescaper.Replace(value)
+		case name == "LOCATION":
+			event.Location = icsUnescaper.Replace(value)
+		case name == "DESCRIPTION":
+			event.Description = icsUnescaper.Replace(value)
+		case name == "DTSTART" || name == "DTEND":
+			t, err := parseICSTime(value, params[1:])
+			if err != nil {
+				return nil, fmt.Errorf("cannot parse %v on line %v: %w", name, i+1, err)
+			}
+
+			if name == "DTSTART" {
+				event.Start = t
+			} else {
+				event.End = t
+			}
+		}
+	}
+
+	return events, nil
+}
+
+// parseICSTime parses the value of a DTSTART or DTEND property with the given
+// parameters and returns the time in UTC.
+func parseICSTime(value string, params []string) (time.Time, error) {
+	loc := time.UTC
+	for _, p := range params {
+		if strings.HasPrefix(strings.ToUpper(p), "TZID=") {
+			l, err := time.LoadLocation(p[len("TZID="):])
+			if err != nil {
+				return time.Time{}, err
+			}
+
+			loc = l
+		}
+	}
+
+	for _, layout := range []string{icsTimeFormat, "20060102T150405", "20060102"} {
+		if t, err := time.ParseInLocation(layout, value, loc); err == nil {
+			return t.UTC(), nil
+		}
+	}
+
+	return time.Time{}, fmt.Errorf("invalid time \"%v\"", value)
+}
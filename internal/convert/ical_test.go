@@ -0,0 +1,131 @@
+// This source file is part of the attendance list project
+// as a part of the go lecture by H. Neemann.
+// For this reason you have no permission to use, modify or
+// share this code without the agreement of the authors.
+//
+// Matriculation numbers of the authors: 5703004, 5736465
+
+// Package convert provides functionality to convert types between multiple formats.
+// A type must implement the Converter interface to prepare it for using this it
+// with this package.
+package convert
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestToICS(t *testing.T) {
+	expected := "BEGIN:VCALENDAR\r\n" +
+		"VERSION:2.0\r\n" +
+		"PRODID:-//dateiexplorer//attendancelist//EN\r\n" +
+		"BEGIN:VEVENT\r\n" +
+		"UID:5faacdf0e6e7b44a@attendancelist\r\n" +
+		"DTSTAMP:20211201T080000Z\r\n" +
+		"DTSTART:20211015T134011Z\r\n" +
+		"DTEND:20211015T235959Z\r\n" +
+		"SUMMARY:Alte Mälzerei\r\n" +
+		"LOCATION:Alte Mälzerei\r\n" +
+		`DESCRIPTION:no logout\; gap\, 1s` + "\r\n" +
+		"END:VEVENT\r\n" +
+		"END:VCALENDAR\r\n"
+
+	events := []Event{{
+		UID:         "5faacdf0e6e7b44a@attendancelist",
+		Summary:     "Alte Mälzerei",
+		Location:    "Alte Mälzerei",
+		Description: "no logout; gap, 1s",
+		Start:       time.Date(2021, 10, 15, 13, 40, 11, 0, time.UTC),
+		End:         time.Date(2021, 10, 15, 23, 59, 59, 0, time.UTC),
+	}}
+
+	actual := new(bytes.Buffer)
+	err := ToICS(actual, events, time.Date(2021, 12, 1, 8, 0, 0, 0, time.UTC))
+	assert.NoError(t, err)
+	assert.Equal(t, expected, actual.String())
+}
+
+func TestToICSFoldsLongLines(t *testing.T) {
+	events := []Event{{UID: "1", Summary: strings.Repeat("ä", 60)}}
+
+	actual := new(bytes.Buffer)
+	err := ToICS(actual, events, time.Now())
+	assert.NoError(t, err)
+
+	for _, line := range strings.Split(actual.String(), "\r\n") {
+		assert.LessOrEqual(t, len(line), 75)
+	}
+	assert.Contains(t, strings.ReplaceAll(actual.String(), "\r\n ", ""), "SUMMARY:"+strings.Repeat("ä", 60))
+}
+
+func TestToICSFailedToWrite(t *testing.T) {
+	err := ToICS(errorWriter{}, nil, time.Now())
+	assert.ErrorIs(t, err, errTest)
+}
+
+func TestFromICS(t *testing.T) {
+	input := "BEGIN:VCALENDAR\r\n" +
+		"VERSION:2.0\r\n" +
+		"BEGIN:VEVENT\r\n" +
+		"UID:1@lectures\r\n" +
+		"DTSTART:20211015T090000Z\r\n" +
+		"DTEND:20211015T103000Z\r\n" +
+		"SUMMARY:Go Programmierung\\, Teil 1\r\n" +
+		"LOCATION:DHBW Mosbach\r\n" +
+		"END:VEVENT\r\n" +
+		"BEGIN:VEVENT\r\n" +
+		"UID:2@lectures\r\n" +
+		"DTSTART;TZID=Europe/Berlin:20211015T130000\r\n" +
+		"DTEND;TZID=Europe/Berlin:20211015T143000\r\n" +
+		"SUMMARY:Ein sehr langer Titel einer Vorlesung\\, der über mehrere Zeilen gef\r\n" +
+		" altet wird\r\n" +
+		"END:VEVENT\r\n" +
+		"END:VCALENDAR\r\n"
+
+	expected := []Event{
+		{
+			UID:      "1@lectures",
+			Summary:  "Go Programmierung, Teil 1",
+			Location: "DHBW Mosbach",
+			Start:    time.Date(2021, 10, 15, 9, 0, 0, 0, time.UTC),
+			End:      time.Date(2021, 10, 15, 10, 30, 0, 0, time.UTC),
+		},
+		{
+			UID:     "2@lectures",
+			Summary: "Ein sehr langer Titel einer Vorlesung, der über mehrere Zeilen gefaltet wird",
+			Start:   time.Date(2021, 10, 15, 11, 0, 0, 0, time.UTC),
+			End:     time.Date(2021, 10, 15, 12, 30, 0, 0, time.UTC),
+		},
+	}
+
+	actual, err := FromICS(strings.NewReader(input))
+	assert.NoError(t, err)
+	assert.Equal(t, expected, actual)
+}
+
+func TestFromICSRoundTrip(t *testing.T) {
+	expected := []Event{{
+		UID:         "5faacdf0e6e7b44a@attendancelist",
+		Summary:     "Alte Mälzerei; Saal",
+		Description: "no logout\ngap",
+		Start:       time.Date(2021, 10, 15, 13, 40, 11, 0, time.UTC),
+		End:         time.Date(2021, 10, 15, 23, 59, 59, 0, time.UTC),
+	}}
+
+	buf := new(bytes.Buffer)
+	assert.NoError(t, ToICS(buf, expected, time.Now()))
+
+	actual, err := FromICS(buf)
+	assert.NoError(t, err)
+	assert.Equal(t, expected, actual)
+}
+
+func TestFromICSInvalidTime(t *testing.T) {
+	input := "BEGIN:VEVENT\nDTSTART:tomorrow\nEND:VEVENT\n"
+	_, err := FromICS(strings.NewReader(input))
+	assert.Error(t, err)
+}
@@ -0,0 +1,155 @@
+// This source file is part of the attendance list project
+// as a part of the go lecture by H. Neemann.
+// For this reason you have no permission to use, modify or
+// share this code without the agreement of the authors.
+//
+// Matriculation numbers of the authors: 5703004, 5736465
+
+// Package journal provides functionality for writing text based journal files.
+package journal
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/dateiexplorer/attendancelist/internal/timeutil"
+)
+
+// AnonymizeOptions control how visits are anonymized.
+//
+// K is the minimum number of different Persons whose visits must share the same
+// quasi-identifiers to be released. ZipDigits is the number of leading digits of the zip code
+// which are kept, the other digits are replaced by "*". Resolution is the
+// length of the time slots the begin and the end of a visit are coarsened to.
+type AnonymizeOptions struct {
+	K          int
+	ZipDigits  int
+	Resolution time.Duration
+}
+
+// An AnonymousVisit is a visit of a Location without any identifying data of
+// the Person. All attributes are quasi-identifiers.
+//
+// Start and End are the offsets from the beginning of the Date, coarsened to
+// the Resolution of the AnonymizeOptions.
+type AnonymousVisit struct {
+	Location   Location
+	Date       timeutil.Date
+	ZipCode    string
+	Start, End time.Duration
+}
+
+// An AnonymousVisitList is a collection of AnonymousVisits.
+type AnonymousVisitList []AnonymousVisit
+
+// Anonymize returns the visits of all journals without names, streets, house
+// numbers and cities of the Persons. Zip codes and times are coarsened as
+// defined by the options, visits whose combination of quasi-identifiers is
+// shared by less than K different Persons are suppressed. Repeated visits of
+// the same Person therefore don't make a combination anonymous.
+//
+// Returns the released visits sorted by Date, Location and Start and the
+// number of suppressed visits. An error is returned if the options are invalid.
+func Anonymize(journals []Journal, opts AnonymizeOptions) (AnonymousVisitList, int, error) {
+	if opts.K < 1 {
+		return nil, 0, fmt.Errorf("k must be at least 1, got %v", opts.K)
+	}
+
+	if opts.ZipDigits < 0 {
+		return nil, 0, fmt.Errorf("the number of zip code digits must not be negative, got %v", opts.ZipDigits)
+	}
+
+	if opts.Resolution <= 0 || opts.Resolution > 24*time.Hour {
+		return nil, 0, fmt.Errorf("the time resolution must be between 0 and 24h, got %v", opts.Resolution)
+	}
+
+	visits := AnonymousVisitList{}
+	persons := make(map[AnonymousVisit]map[Person]bool)
+	for _, j := range journals {
+		midnight := j.Date.At(0)
+		for _, s := range j.GetSessions() {
+			start := s.Start(j.Date).Sub(midnight.Time)
+			end := s.End(j.Date).Sub(midnight.Time)
+
+			// Round the begin down and the end up, so the slots cover the visit.
+			start -= start % opts.Resolution
+			if rest := end % opts.Resolution; rest != 0 {
+				end += opts.Resolution - rest
+			}
+			if end > 24*time.Hour {
+				end = 24 * time.Hour
+			}
+
+			v := AnonymousVisit{s.Location, j.Date, coarsenZipCode(s.Person.Address.ZipCode, opts.ZipDigits), start, end}
+			if persons[v] == nil {
+				persons[v] = make(map[Person]bool)
+			}
+
+			persons[v][s.Person] = true
+			visits = append(visits, v)
+		}
+	}
+
+	released := AnonymousVisitList{}
+	for _, v := range visits {
+		if len(persons[v]) >= opts.K {
+			released = append(released, v)
+		}
+	}
+
+	sort.SliceStable(released, func(a, b int) bool {
+		va, vb := released[a], released[b]
+		if va.Date != vb.Date {
+			return va.Date.Before(vb.Date)
+		}
+		if va.Location != vb.Location {
+			return va.Location < vb.Location
+		}
+
+		return va.Start < vb.Start
+	})
+
+	return released, len(visits) - len(released), nil
+}
+
+// coarsenZipCode keeps the first digits of the zip code and replaces all other
+// characters by "*".
+func coarsenZipCode(zipCode string, digits int) string {
+	if len(zipCode) <= digits {
+		return zipCode
+	}
+
+	return zipCode[:digits] + strings.Repeat("*", len(zipCode)-digits)
+}
+
+// formatOffset formats an offset from the beginning of a day as "hh:mm".
+func formatOffset(d time.Duration) string {
+	return fmt.Sprintf("%02d:%02d", int(d.Hours()), int(d.Minutes())%60)
+}
+
+// NextEntry returns a read-only channel that loops through the hole
+// AnonymousVisitList and returns data of the AnonymousVisit as a string slice.
+//
+// Used to convert an AnonymousVisitList to any file format.
+func (l AnonymousVisitList) NextEntry() <-chan []string {
+	entries := make(chan []string)
+	go func() {
+		for _, v := range l {
+			entries <- []string{v.Date.String(), string(v.Location), v.ZipCode, formatOffset(v.Start), formatOffset(v.End)}
+		}
+
+		close(entries)
+	}()
+
+	return entries
+}
+
+// Header returns a string slice which describes the data given by the NextEntry
+// function.
+//
+// Used to convert an AnonymousVisitList to any file format.
+func (l AnonymousVisitList) Header() []string {
+	return []string{"Date", "Location", "ZipCode", "Start", "End"}
+}
@@ -0,0 +1,112 @@
+// This source file is part of the attendance list project
+// as a part of the go lecture by H. Neemann.
+// For this reason you have no permission to use, modify or
+// share this code without the agreement of the authors.
+//
+// Matriculation numbers of the authors: 5703004, 5736465
+
+// Package journal provides functionality for writing text based journal files.
+package journal
+
+import (
+	"testing"
+	"time"
+
+	"github.com/dateiexplorer/attendancelist/internal/timeutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAnonymize(t *testing.T) {
+	date := timeutil.NewDate(2021, 10, 15)
+	j := Journal{date, []JournalEntry{
+		{timeutil.NewTimestamp(2021, 10, 15, 9, 5, 0), "aa", Login, locs["DH"], persons["GM"]},
+		{timeutil.NewTimestamp(2021, 10, 15, 9, 20, 0), "bb", Login, locs["DH"], persons["MM"]},
+		{timeutil.NewTimestamp(2021, 10, 15, 9, 10, 0), "cc", Login, locs["DH"], persons["HM"]},
+		{timeutil.NewTimestamp(2021, 10, 15, 9, 45, 0), "dd", Login, locs["DH"], persons["LM"]},
+		{timeutil.NewTimestamp(2021, 10, 15, 11, 10, 0), "aa", Logout, locs["DH"], persons["GM"]},
+		{timeutil.NewTimestamp(2021, 10, 15, 11, 50, 0), "bb", Logout, locs["DH"], persons["MM"]},
+		{timeutil.NewTimestamp(2021, 10, 15, 11, 55, 0), "cc", Logout, locs["DH"], persons["HM"]},
+		{timeutil.NewTimestamp(2021, 10, 15, 11, 59, 0), "dd", Logout, locs["DH"], persons["LM"]},
+	}}
+
+	// Hans Müller lives in 74722, but shares the region with the others.
+	expected := AnonymousVisitList{
+		{locs["DH"], date, "74***", 9 * time.Hour, 12 * time.Hour},
+		{locs["DH"], date, "74***", 9 * time.Hour, 12 * time.Hour},
+		{locs["DH"], date, "74***", 9 * time.Hour, 12 * time.Hour},
+	}
+
+	actual, suppressed, err := Anonymize([]Journal{j}, AnonymizeOptions{3, 2, time.Hour})
+	assert.NoError(t, err)
+	assert.Equal(t, expected, actual)
+	assert.Equal(t, 1, suppressed)
+
+	// With the complete zip code, no group is large enough.
+	actual, suppressed, err = Anonymize([]Journal{j}, AnonymizeOptions{3, 5, time.Hour})
+	assert.NoError(t, err)
+	assert.Equal(t, AnonymousVisitList{}, actual)
+	assert.Equal(t, 4, suppressed)
+
+	// Shorter slots separate the visits.
+	actual, suppressed, err = Anonymize([]Journal{j}, AnonymizeOptions{2, 2, 30 * time.Minute})
+	assert.NoError(t, err)
+	assert.Equal(t, AnonymousVisitList{
+		{locs["DH"], date, "74***", 9 * time.Hour, 12 * time.Hour},
+		{locs["DH"], date, "74***", 9 * time.Hour, 12 * time.Hour},
+	}, actual)
+	assert.Equal(t, 2, suppressed)
+}
+
+func TestAnonymizeRepeatVisitor(t *testing.T) {
+	date := timeutil.NewDate(2021, 10, 15)
+	j := Journal{date, []JournalEntry{
+		{timeutil.NewTimestamp(2021, 10, 15, 9, 5, 0), "aa", Login, locs["DH"], persons["MM"]},
+		{timeutil.NewTimestamp(2021, 10, 15, 9, 20, 0), "aa", Logout, locs["DH"], persons["MM"]},
+		{timeutil.NewTimestamp(2021, 10, 15, 9, 25, 0), "bb", Login, locs["DH"], persons["MM"]},
+		{timeutil.NewTimestamp(2021, 10, 15, 9, 40, 0), "bb", Logout, locs["DH"], persons["MM"]},
+		{timeutil.NewTimestamp(2021, 10, 15, 9, 45, 0), "cc", Login, locs["DH"], persons["MM"]},
+		{timeutil.NewTimestamp(2021, 10, 15, 9, 55, 0), "cc", Logout, locs["DH"], persons["MM"]},
+	}}
+
+	// Three visits in the same slot are still only one person.
+	actual, suppressed, err := Anonymize([]Journal{j}, AnonymizeOptions{2, 2, time.Hour})
+	assert.NoError(t, err)
+	assert.Equal(t, AnonymousVisitList{}, actual)
+	assert.Equal(t, 3, suppressed)
+}
+
+func TestAnonymizeUnterminatedSession(t *testing.T) {
+	date := timeutil.NewDate(2021, 10, 15)
+	j := Journal{date, []JournalEntry{
+		{timeutil.NewTimestamp(2021, 10, 15, 22, 5, 0), "aa", Login, locs["AM"], persons["ON"]},
+	}}
+
+	actual, _, err := Anonymize([]Journal{j}, AnonymizeOptions{1, 0, 3 * time.Hour})
+	assert.NoError(t, err)
+	assert.Equal(t, AnonymousVisitList{{locs["AM"], date, "*****", 21 * time.Hour, 24 * time.Hour}}, actual)
+	assert.Equal(t, [][]string{{"2021-10-15", "Alte Mälzerei", "*****", "21:00", "24:00"}}, collect(actual.NextEntry()))
+}
+
+func TestAnonymizeInvalidOptions(t *testing.T) {
+	for _, opts := range []AnonymizeOptions{{0, 2, time.Hour}, {5, -1, time.Hour}, {5, 2, 0}, {5, 2, 25 * time.Hour}} {
+		_, _, err := Anonymize(nil, opts)
+		assert.Error(t, err)
+	}
+}
+
+func TestCoarsenZipCode(t *testing.T) {
+	assert.Equal(t, "74***", coarsenZipCode("74821", 2))
+	assert.Equal(t, "74821", coarsenZipCode("74821", 5))
+	assert.Equal(t, "748", coarsenZipCode("748", 4))
+	assert.Equal(t, "", coarsenZipCode("", 2))
+}
+
+// collect reads all entries from a channel.
+func collect(entries <-chan []string) [][]string {
+	all := [][]string{}
+	for e := range entries {
+		all = append(all, e)
+	}
+
+	return all
+}
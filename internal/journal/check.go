@@ -0,0 +1,130 @@
+// This source file is part of the attendance list project
+// as a part of the go lecture by H. Neemann.
+// For this reason you have no permission to use, modify or
+// share this code without the agreement of the authors.
+//
+// Matriculation numbers of the authors: 5703004, 5736465
+
+// Package journal provides functionality for writing text based journal files.
+package journal
+
+import (
+	"sort"
+
+	"github.com/dateiexplorer/attendancelist/internal/timeutil"
+)
+
+// An AnomalyKind describes which data-quality problem an Anomaly is.
+type AnomalyKind string
+
+const (
+	// A Logout without a Login for the same session on the same day.
+	LogoutWithoutLogin AnomalyKind = "logout without login"
+	// A Session of a Person which starts while another Session of the same
+	// Person at another Location is still open.
+	ConcurrentSessions AnomalyKind = "concurrent sessions"
+	// A Session which never ends on the same day.
+	UnterminatedSession AnomalyKind = "unterminated session"
+	// A Session at a Location which is not in the list of known locations.
+	UnknownLocation AnomalyKind = "unknown location"
+)
+
+// AnomalyKinds holds all AnomalyKinds in the order they are reported.
+var AnomalyKinds = []AnomalyKind{LogoutWithoutLogin, ConcurrentSessions, UnterminatedSession, UnknownLocation}
+
+// An Anomaly represents a Session of a Journal with a data-quality problem.
+type Anomaly struct {
+	Kind    AnomalyKind
+	Date    timeutil.Date
+	Session Session
+}
+
+// CheckAnomalies scans the Journal j for data-quality problems.
+//
+// If known is not nil, every Session at a Location which is not in known is an
+// Anomaly of the kind UnknownLocation. Otherwise Locations are not checked.
+// The AnomalyList is sorted by the kind of the Anomaly and the Start of the
+// Session.
+func (j Journal) CheckAnomalies(known []Location) AnomalyList {
+	list := AnomalyList{}
+
+	isKnown := make(map[Location]bool)
+	for _, l := range known {
+		isKnown[l] = true
+	}
+
+	open := make(map[Person]Session)
+	for _, s := range j.GetSessions() {
+		if s.Login == timeutil.InvalidTimestamp {
+			list = append(list, Anomaly{LogoutWithoutLogin, j.Date, s})
+		}
+		if s.Logout == timeutil.InvalidTimestamp {
+			list = append(list, Anomaly{UnterminatedSession, j.Date, s})
+		}
+		if known != nil && !isKnown[s.Location] {
+			list = append(list, Anomaly{UnknownLocation, j.Date, s})
+		}
+
+		// Sessions are sorted by Start, so only the Session of the Person
+		// which ends last can overlap.
+		if prev, ok := open[s.Person]; ok && prev.Location != s.Location && prev.End(j.Date).After(s.Start(j.Date).Time) {
+			list = append(list, Anomaly{ConcurrentSessions, j.Date, s})
+		}
+		if prev, ok := open[s.Person]; !ok || s.End(j.Date).After(prev.End(j.Date).Time) {
+			open[s.Person] = s
+		}
+	}
+
+	order := make(map[AnomalyKind]int)
+	for i, k := range AnomalyKinds {
+		order[k] = i
+	}
+
+	sort.SliceStable(list, func(a, b int) bool {
+		return order[list[a].Kind] < order[list[b].Kind]
+	})
+
+	return list
+}
+
+// An AnomalyList is a collection of Anomalies.
+type AnomalyList []Anomaly
+
+// Count returns the number of Anomalies for every AnomalyKind.
+func (l AnomalyList) Count() map[AnomalyKind]int {
+	count := make(map[AnomalyKind]int)
+	for _, a := range l {
+		count[a.Kind]++
+	}
+
+	return count
+}
+
+// NextEntry returns a read-only channel that loops through the hole AnomalyList
+// and returns data of the Anomaly as a string slice.
+// An unknown Login or Logout is represented by an empty string.
+//
+// Used to convert an AnomalyList to any file format.
+func (l AnomalyList) NextEntry() <-chan []string {
+	entries := make(chan []string)
+	go func() {
+		for _, a := range l {
+			session := SessionList{a.Session}
+			for entry := range session.NextEntry() {
+				entries <- append([]string{a.Date.String(), string(a.Kind), a.Session.ID}, entry...)
+			}
+		}
+
+		close(entries)
+	}()
+
+	return entries
+}
+
+// Header returns a string slice which describes the data given by the NextEntry
+// function.
+//
+// Used to convert an AnomalyList to any file format.
+func (l AnomalyList) Header() []string {
+	return append([]string{"Date", "Kind", "SessionID"}, SessionList{}.Header()...)
+}
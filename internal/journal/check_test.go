@@ -0,0 +1,75 @@
+// This source file is part of the attendance list project
+// as a part of the go lecture by H. Neemann.
+// For this reason you have no permission to use, modify or
+// share this code without the agreement of the authors.
+//
+// Matriculation numbers of the authors: 5703004, 5736465
+
+// Package journal provides functionality for writing text based journal files.
+package journal
+
+import (
+	"testing"
+
+	"github.com/dateiexplorer/attendancelist/internal/timeutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckAnomalies(t *testing.T) {
+	date := timeutil.NewDate(2021, 11, 30)
+	expected := AnomalyList{
+		{LogoutWithoutLogin, date, Session{"abcdefghijklmnop", persons["TT"], locs["DH"], timeutil.InvalidTimestamp, timeutil.NewTimestamp(2021, 11, 30, 14, 0, 0)}},
+		{UnterminatedSession, date, Session{"5faacdf0e6e7b44a", persons["HM"], locs["AM"], timeutil.NewTimestamp(2021, 11, 30, 8, 30, 0), timeutil.InvalidTimestamp}},
+		{UnterminatedSession, date, Session{"aabbccddeeffgghh", persons["TT"], locs["DH"], timeutil.NewTimestamp(2021, 11, 30, 18, 0, 0), timeutil.InvalidTimestamp}},
+		{UnterminatedSession, date, Session{"848dc86c0b5e62a0", persons["ON"], locs["DH"], timeutil.NewTimestamp(2021, 11, 30, 19, 0, 0), timeutil.InvalidTimestamp}},
+		{UnknownLocation, date, Session{"5faacdf0e6e7b44a", persons["HM"], locs["AM"], timeutil.NewTimestamp(2021, 11, 30, 8, 30, 0), timeutil.InvalidTimestamp}},
+	}
+
+	journal, err := ReadJournal("testdata", date)
+	assert.NoError(t, err)
+
+	actual := journal.CheckAnomalies([]Location{locs["DH"]})
+	assert.Equal(t, expected, actual)
+
+	count := actual.Count()
+	assert.Equal(t, 1, count[LogoutWithoutLogin])
+	assert.Equal(t, 3, count[UnterminatedSession])
+	assert.Equal(t, 0, count[ConcurrentSessions])
+
+	// Without known locations the locations are not checked.
+	assert.Equal(t, expected[:4], journal.CheckAnomalies(nil))
+}
+
+func TestCheckAnomaliesConcurrentSessions(t *testing.T) {
+	j := Journal{timeutil.NewDate(2021, 10, 15), []JournalEntry{
+		{timeutil.NewTimestamp(2021, 10, 15, 8, 0, 0), "aaaa", Login, locs["DH"], persons["MM"]},
+		{timeutil.NewTimestamp(2021, 10, 15, 9, 0, 0), "bbbb", Login, locs["AM"], persons["MM"]},
+		{timeutil.NewTimestamp(2021, 10, 15, 10, 0, 0), "aaaa", Logout, locs["DH"], persons["MM"]},
+		{timeutil.NewTimestamp(2021, 10, 15, 11, 0, 0), "bbbb", Logout, locs["AM"], persons["MM"]},
+		{timeutil.NewTimestamp(2021, 10, 15, 11, 0, 0), "cccc", Login, locs["DH"], persons["MM"]},
+		{timeutil.NewTimestamp(2021, 10, 15, 12, 0, 0), "cccc", Logout, locs["DH"], persons["MM"]},
+	}}
+
+	expected := AnomalyList{
+		{ConcurrentSessions, j.Date, Session{"bbbb", persons["MM"], locs["AM"], timeutil.NewTimestamp(2021, 10, 15, 9, 0, 0), timeutil.NewTimestamp(2021, 10, 15, 11, 0, 0)}},
+	}
+
+	assert.Equal(t, expected, j.CheckAnomalies(nil))
+}
+
+func TestAnomalyListNextEntry(t *testing.T) {
+	expected := []string{"2021-11-30", "logout without login", "abcdefghijklmnop", "DHBW Mosbach", "Torsten", "Test", "Teststraße", "10", "74821", "Mosbach", "", "2021/11/30 14:00:00 UTC"}
+
+	list := AnomalyList{
+		{LogoutWithoutLogin, timeutil.NewDate(2021, 11, 30), Session{"abcdefghijklmnop", persons["TT"], locs["DH"], timeutil.InvalidTimestamp, timeutil.NewTimestamp(2021, 11, 30, 14, 0, 0)}},
+	}
+
+	counter := 0
+	for actual := range list.NextEntry() {
+		assert.Equal(t, expected, actual)
+		counter++
+	}
+
+	assert.Equal(t, 1, counter)
+	assert.Equal(t, len(expected), len(list.Header()))
+}
@@ -0,0 +1,94 @@
+// This source file is part of the attendance list project
+// as a part of the go lecture by H. Neemann.
+// For this reason you have no permission to use, modify or
+// share this code without the agreement of the authors.
+//
+// Matriculation numbers of the authors: 5703004, 5736465
+
+package journal
+
+import (
+	"crypto"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+
+	"github.com/dateiexplorer/attendancelist/internal/timeutil"
+)
+
+// ReadJournalsConcurrently works like ReadJournalsWithKey but reads and parses
+// the journal files with the given number of workers concurrently. If prepare
+// is not nil, every Journal is replaced by the result of prepare in the worker
+// which read it, e.g. to filter the JournalEntries early. prepare must be safe
+// for concurrent use.
+//
+// The Journals are returned in chronological order, the result and the error
+// are the same as reading the journal files one after another: if a journal
+// file cannot be read, the Journals before it and the error are returned.
+func ReadJournalsConcurrently(dir string, from, to timeutil.Date, priv crypto.PrivateKey, workers int, prepare func(j Journal) Journal) ([]Journal, error) {
+	dates := make([]timeutil.Date, 0)
+	for date := from; !to.Before(date); date = date.AddDays(1) {
+		dates = append(dates, date)
+	}
+
+	if workers < 1 {
+		workers = 1
+	}
+
+	// Every worker writes only the results of the dates it has taken, so no
+	// further synchronization is needed.
+	journals := make([]Journal, len(dates))
+	errs := make([]error, len(dates))
+	indices := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indices {
+				j, err := ReadJournalWithKey(dir, dates[i], priv)
+				if err == nil && prepare != nil {
+					j = prepare(j)
+				}
+
+				journals[i], errs[i] = j, err
+			}
+		}()
+	}
+
+	for i := range dates {
+		indices <- i
+	}
+
+	close(indices)
+	wg.Wait()
+
+	result := make([]Journal, 0, len(dates))
+	for i, err := range errs {
+		if err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				continue
+			}
+
+			return result, fmt.Errorf("cannot read journal for %v: %w", dates[i], err)
+		}
+
+		result = append(result, journals[i])
+	}
+
+	return result, nil
+}
+
+// Filter returns a copy of the Journal j with the JournalEntries for which keep
+// returns true.
+func (j Journal) Filter(keep func(e JournalEntry) bool) Journal {
+	entries := make([]JournalEntry, 0, len(j.Entries))
+	for _, e := range j.Entries {
+		if keep(e) {
+			entries = append(entries, e)
+		}
+	}
+
+	return Journal{j.Date, entries}
+}
@@ -0,0 +1,71 @@
+// This source file is part of the attendance list project
+// as a part of the go lecture by H. Neemann.
+// For this reason you have no permission to use, modify or
+// share this code without the agreement of the authors.
+//
+// Matriculation numbers of the authors: 5703004, 5736465
+
+// Package journal provides functionality for writing text based journal files.
+package journal
+
+import (
+	"os"
+	"testing"
+
+	"github.com/dateiexplorer/attendancelist/internal/timeutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReadJournalsConcurrently(t *testing.T) {
+	from, to := timeutil.NewDate(2021, 10, 1), timeutil.NewDate(2021, 12, 31)
+	expected, err := ReadJournals("testdata", from, to)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(expected))
+
+	for _, workers := range []int{0, 1, 2, 4, 16} {
+		actual, err := ReadJournalsConcurrently("testdata", from, to, nil, workers, nil)
+		assert.NoError(t, err)
+		assert.Equal(t, expected, actual, workers)
+	}
+}
+
+func TestReadJournalsConcurrentlyPrepare(t *testing.T) {
+	date := timeutil.NewDate(2021, 10, 15)
+	keep := func(e JournalEntry) bool {
+		return e.Location == "Alte Mälzerei"
+	}
+
+	j, err := ReadJournal("testdata", date)
+	assert.NoError(t, err)
+
+	actual, err := ReadJournalsConcurrently("testdata", date, date, nil, 4, func(j Journal) Journal {
+		return j.Filter(keep)
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, []Journal{j.Filter(keep)}, actual)
+	assert.Equal(t, 3, len(actual[0].Entries))
+}
+
+func TestReadJournalsConcurrentlyFailed(t *testing.T) {
+	dir := t.TempDir()
+	date := timeutil.NewDate(2021, 10, 15)
+	hans := NewPerson("Hans", "Müller", "Feldweg", "12", "74722", "Buchen")
+	for _, d := range []timeutil.Date{date, date.AddDays(3)} {
+		e := NewJournalEntry(d.At(0), "a", Login, "DHBW Mosbach", hans)
+		assert.NoError(t, WriteToJournalFile(dir, &e))
+	}
+
+	assert.NoError(t, os.WriteFile(FilePath(dir, date.AddDays(2)), []byte("invalid\n"), 0644))
+
+	// The same Journals and the same error as reading one after another.
+	expected, expectedErr := ReadJournals(dir, date, date.AddDays(5))
+	assert.Error(t, expectedErr)
+	assert.Equal(t, 1, len(expected))
+
+	for _, workers := range []int{1, 3, 8} {
+		actual, err := ReadJournalsConcurrently(dir, date, date.AddDays(5), nil, workers, nil)
+		assert.Equal(t, expected, actual)
+		assert.Equal(t, expectedErr, err)
+	}
+}
@@ -0,0 +1,404 @@
+// This source file is part of the attendance list project
+// as a part of the go lecture by H. Neemann.
+// For this reason you have no permission to use, modify or
+// share this code without the agreement of the authors.
+//
+// Matriculation numbers of the authors: 5703004, 5736465
+
+// Package journal provides functionality for writing text based journal files.
+package journal
+
+import (
+	"crypto"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// The first byte of an encrypted Person which describes how the AES key is
+// transferred.
+const (
+	wrapRSA  byte = 'R'
+	wrapECDH byte = 'E'
+)
+
+// A PersonEncrypter encrypts Persons with a public key, so that only the owner
+// of the private key, e.g. the health authority, can read them.
+//
+// Every Person is encrypted with a random AES-256-GCM key. The AES key is
+// encrypted with RSA-OAEP for RSA keys or derived by an ephemeral ECDH key
+// exchange for EC keys.
+type PersonEncrypter struct {
+	pub     crypto.PublicKey
+	hashKey []byte
+}
+
+// NewPersonEncrypter returns a new PersonEncrypter for the RSA or EC public key
+// pub. The hashKey is the secret key of the keyed person hash.
+//
+// Returns an error if the type of the key is not supported.
+func NewPersonEncrypter(pub crypto.PublicKey, hashKey []byte) (*PersonEncrypter, error) {
+	switch pub.(type) {
+	case *rsa.PublicKey, *ecdsa.PublicKey:
+		return &PersonEncrypter{pub, hashKey}, nil
+	default:
+		return nil, fmt.Errorf("cannot encrypt with a key of type %T", pub)
+	}
+}
+
+// Hash returns the keyed hash of the Person p as hexadecimal string. The hash
+// is equal for equal Persons, so Persons can be distinguished without knowing
+// their data.
+func (e *PersonEncrypter) Hash(p Person) string {
+	mac := hmac.New(sha256.New, e.hashKey)
+	mac.Write([]byte(p.String()))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// Encrypt encrypts the Person p and returns the keyed hash of p and the
+// ciphertext encoded as base64 string. The hash is authenticated with the
+// ciphertext, so a ciphertext cannot be moved to another hash.
+//
+// Returns an error if the encryption fails.
+func (e *PersonEncrypter) Encrypt(p Person) (string, string, error) {
+	hash := e.Hash(p)
+
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		return "", "", fmt.Errorf("cannot encrypt person: %w", err)
+	}
+
+	var header []byte
+	switch pub := e.pub.(type) {
+	case *rsa.PublicKey:
+		wrapped, err := rsa.EncryptOAEP(sha256.New(), rand.Reader, pub, key, nil)
+		if err != nil {
+			return "", "", fmt.Errorf("cannot encrypt person: %w", err)
+		}
+
+		header = withLength(wrapRSA, wrapped)
+	case *ecdsa.PublicKey:
+		priv, x, y, err := elliptic.GenerateKey(pub.Curve, rand.Reader)
+		if err != nil {
+			return "", "", fmt.Errorf("cannot encrypt person: %w", err)
+		}
+
+		sx, _ := pub.Curve.ScalarMult(pub.X, pub.Y, priv)
+		key = deriveKey(sx.Bytes())
+		header = withLength(wrapECDH, elliptic.Marshal(pub.Curve, x, y))
+	}
+
+	plaintext, err := json.Marshal(p)
+	if err != nil {
+		return "", "", fmt.Errorf("cannot encrypt person: %w", err)
+	}
+
+	sealed, err := seal(key, plaintext, []byte(hash))
+	if err != nil {
+		return "", "", fmt.Errorf("cannot encrypt person: %w", err)
+	}
+
+	return hash, base64.StdEncoding.EncodeToString(append(header, sealed...)), nil
+}
+
+// DecryptPerson decrypts a Person encrypted by a PersonEncrypter with the
+// private key priv. The hash must be the hash returned with the ciphertext.
+//
+// Returns an error if the ciphertext cannot be decrypted with the key.
+func DecryptPerson(priv crypto.PrivateKey, hash string, ciphertext string) (Person, error) {
+	data, err := base64.StdEncoding.DecodeString(ciphertext)
+	if err != nil {
+		return Person{}, fmt.Errorf("cannot decrypt person: %w", err)
+	}
+
+	if len(data) < 3 {
+		return Person{}, errors.New("cannot decrypt person: ciphertext too short")
+	}
+
+	n := int(binary.BigEndian.Uint16(data[1:3]))
+	if len(data) < 3+n {
+		return Person{}, errors.New("cannot decrypt person: ciphertext too short")
+	}
+	wrapped, sealed := data[3:3+n], data[3+n:]
+
+	var key []byte
+	switch k := priv.(type) {
+	case *rsa.PrivateKey:
+		if data[0] != wrapRSA {
+			return Person{}, errors.New("cannot decrypt person: person was not encrypted with a RSA key")
+		}
+
+		key, err = rsa.DecryptOAEP(sha256.New(), rand.Reader, k, wrapped, nil)
+		if err != nil {
+			return Person{}, fmt.Errorf("cannot decrypt person: %w", err)
+		}
+	case *ecdsa.PrivateKey:
+		if data[0] != wrapECDH {
+			return Person{}, errors.New("cannot decrypt person: person was not encrypted with an EC key")
+		}
+
+		x, y := elliptic.Unmarshal(k.Curve, wrapped)
+		if x == nil {
+			return Person{}, errors.New("cannot decrypt person: invalid ephemeral key")
+		}
+
+		sx, _ := k.Curve.ScalarMult(x, y, k.D.Bytes())
+		key = deriveKey(sx.Bytes())
+	default:
+		return Person{}, fmt.Errorf("cannot decrypt with a key of type %T", priv)
+	}
+
+	plaintext, err := open(key, sealed, []byte(hash))
+	if err != nil {
+		return Person{}, fmt.Errorf("cannot decrypt person: %w", err)
+	}
+
+	var p Person
+	if err := json.Unmarshal(plaintext, &p); err != nil {
+		return Person{}, fmt.Errorf("cannot decrypt person: %w", err)
+	}
+
+	return p, nil
+}
+
+// Pseudonym returns the Person which represents an encrypted Person with the
+// keyed hash if the Person cannot be decrypted. The hash is stored as FirstName,
+// all other attributes are empty.
+func Pseudonym(hash string) Person {
+	return Person{FirstName: hash}
+}
+
+// withLength returns the header of an encrypted Person which consists of the
+// kind of the key transfer and the length prefixed data.
+func withLength(kind byte, data []byte) []byte {
+	header := make([]byte, 3, 3+len(data))
+	header[0] = kind
+	binary.BigEndian.PutUint16(header[1:], uint16(len(data)))
+	return append(header, data...)
+}
+
+// deriveKey derives an AES-256 key from the shared secret of a key exchange.
+func deriveKey(secret []byte) []byte {
+	key := sha256.Sum256(secret)
+	return key[:]
+}
+
+// seal encrypts the plaintext with AES-GCM and returns the nonce followed by
+// the ciphertext.
+func seal(key, plaintext, additionalData []byte) ([]byte, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+
+	return gcm.Seal(nonce, nonce, plaintext, additionalData), nil
+}
+
+// open decrypts data sealed by seal.
+func open(key, data, additionalData []byte) ([]byte, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(data) < gcm.NonceSize() {
+		return nil, errors.New("ciphertext too short")
+	}
+
+	return gcm.Open(nil, data[:gcm.NonceSize()], data[gcm.NonceSize():], additionalData)
+}
+
+// LoadSecretKey reads a hex encoded secret key, e.g. of a keyed hash, from the
+// file at filePath. If the file doesn't exist, a random key is created and
+// written to it, readable only by its owner.
+//
+// Returns an error if the file cannot be read or written or doesn't contain a
+// hex encoded key.
+func LoadSecretKey(filePath string) ([]byte, error) {
+	key, err := ReadSecretKey(filePath)
+	if errors.Is(err, os.ErrNotExist) {
+		return CreateSecretKey(filePath)
+	}
+
+	return key, err
+}
+
+// ReadSecretKey reads a hex encoded secret key from the file at filePath.
+//
+// Returns an error wrapping os.ErrNotExist if the file doesn't exist or an
+// error if it cannot be read or doesn't contain a hex encoded key.
+func ReadSecretKey(filePath string) ([]byte, error) {
+	data, err := ioutil.ReadFile(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read secret key: %w", err)
+	}
+
+	key, err := hex.DecodeString(strings.TrimSpace(string(data)))
+	if err != nil || len(key) == 0 {
+		return nil, fmt.Errorf("cannot read secret key: %v contains no hex encoded key", filePath)
+	}
+
+	return key, nil
+}
+
+// CreateSecretKey creates a random secret key and writes it hex encoded to a
+// new file at filePath, readable only by its owner.
+//
+// Returns an error if the file already exists or cannot be written.
+func CreateSecretKey(filePath string) ([]byte, error) {
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		return nil, fmt.Errorf("cannot create secret key: %w", err)
+	}
+
+	f, err := os.OpenFile(filePath, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("cannot create secret key: %w", err)
+	}
+
+	if _, err := f.WriteString(hex.EncodeToString(key) + "\n"); err != nil {
+		f.Close()
+		return nil, fmt.Errorf("cannot write secret key: %w", err)
+	}
+
+	if err := f.Close(); err != nil {
+		return nil, fmt.Errorf("cannot write secret key: %w", err)
+	}
+
+	return key, nil
+}
+
+// IsInDir reports whether the file at filePath is stored in the directory dir
+// or one of its subdirectories. A secret key must not be stored in the journal
+// directory, otherwise whoever has the journal files has the key as well.
+func IsInDir(filePath string, dir string) bool {
+	fileDir, _ := filepath.Abs(filepath.Dir(filePath))
+	dir, _ = filepath.Abs(dir)
+	rel, err := filepath.Rel(dir, fileDir)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// ReadPublicKey reads a PEM encoded public key or the public key of a PEM
+// encoded X.509 certificate from a file.
+//
+// Returns an error if the file cannot be read or parsed.
+func ReadPublicKey(path string) (crypto.PublicKey, error) {
+	block, err := readPEM(path)
+	if err != nil {
+		return nil, err
+	}
+
+	switch block.Type {
+	case "CERTIFICATE":
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("cannot parse certificate: %w", err)
+		}
+
+		return cert.PublicKey, nil
+	case "PUBLIC KEY":
+		pub, err := x509.ParsePKIXPublicKey(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("cannot parse public key: %w", err)
+		}
+
+		return pub, nil
+	case "RSA PUBLIC KEY":
+		pub, err := x509.ParsePKCS1PublicKey(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("cannot parse public key: %w", err)
+		}
+
+		return pub, nil
+	default:
+		return nil, fmt.Errorf("cannot parse public key: unsupported PEM type \"%v\"", block.Type)
+	}
+}
+
+// ReadPrivateKey reads a PEM encoded RSA or EC private key from a file.
+//
+// Returns an error if the file cannot be read or parsed.
+func ReadPrivateKey(path string) (crypto.PrivateKey, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read key file: %w", err)
+	}
+
+	return ParsePrivateKey(data)
+}
+
+// ParsePrivateKey parses a PEM encoded RSA or EC private key.
+//
+// Returns an error if the data cannot be parsed.
+func ParsePrivateKey(data []byte) (crypto.PrivateKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("cannot parse key file: no PEM data found")
+	}
+
+	var priv crypto.PrivateKey
+	var err error
+	switch block.Type {
+	case "PRIVATE KEY":
+		priv, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	case "RSA PRIVATE KEY":
+		priv, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "EC PRIVATE KEY":
+		priv, err = x509.ParseECPrivateKey(block.Bytes)
+	default:
+		return nil, fmt.Errorf("cannot parse private key: unsupported PEM type \"%v\"", block.Type)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("cannot parse private key: %w", err)
+	}
+
+	return priv, nil
+}
+
+// readPEM reads the first PEM block of a file.
+func readPEM(path string) (*pem.Block, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read key file: %w", err)
+	}
+
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("cannot parse key file: no PEM data found")
+	}
+
+	return block, nil
+}
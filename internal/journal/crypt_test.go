@@ -0,0 +1,244 @@
+// This source file is part of the attendance list project
+// as a part of the go lecture by H. Neemann.
+// For this reason you have no permission to use, modify or
+// share this code without the agreement of the authors.
+//
+// Matriculation numbers of the authors: 5703004, 5736465
+
+// Package journal provides functionality for writing text based journal files.
+package journal
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"os"
+	"path"
+	"strings"
+	"testing"
+
+	"github.com/dateiexplorer/attendancelist/internal/timeutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEncryptAndDecryptPersonRSA(t *testing.T) {
+	pub, err := ReadPublicKey("testdata/cert.pem")
+	assert.NoError(t, err)
+
+	priv, err := ReadPrivateKey("testdata/key.pem")
+	assert.NoError(t, err)
+
+	enc, err := NewPersonEncrypter(pub, []byte("secret"))
+	assert.NoError(t, err)
+
+	hash, ciphertext, err := enc.Encrypt(persons["HM"])
+	assert.NoError(t, err)
+	assert.Equal(t, enc.Hash(persons["HM"]), hash)
+	assert.NotContains(t, ciphertext, ",")
+
+	actual, err := DecryptPerson(priv, hash, ciphertext)
+	assert.NoError(t, err)
+	assert.Equal(t, persons["HM"], actual)
+}
+
+func TestEncryptAndDecryptPersonEC(t *testing.T) {
+	priv, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+
+	enc, err := NewPersonEncrypter(priv.Public(), []byte("secret"))
+	assert.NoError(t, err)
+
+	hash, ciphertext, err := enc.Encrypt(persons["GM"])
+	assert.NoError(t, err)
+
+	actual, err := DecryptPerson(priv, hash, ciphertext)
+	assert.NoError(t, err)
+	assert.Equal(t, persons["GM"], actual)
+}
+
+func TestDecryptPersonFailed(t *testing.T) {
+	rsaPriv, err := ReadPrivateKey("testdata/key.pem")
+	assert.NoError(t, err)
+
+	ecPriv, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+
+	enc, err := NewPersonEncrypter(ecPriv.Public(), []byte("secret"))
+	assert.NoError(t, err)
+
+	hash, ciphertext, err := enc.Encrypt(persons["GM"])
+	assert.NoError(t, err)
+
+	// Wrong hash
+	_, err = DecryptPerson(ecPriv, enc.Hash(persons["HM"]), ciphertext)
+	assert.Error(t, err)
+
+	// Wrong kind of key
+	_, err = DecryptPerson(rsaPriv, hash, ciphertext)
+	assert.Error(t, err)
+
+	// Other key of the same kind
+	otherPriv, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+	_, err = DecryptPerson(otherPriv, hash, ciphertext)
+	assert.Error(t, err)
+
+	// Invalid ciphertext
+	_, err = DecryptPerson(ecPriv, hash, "no base64")
+	assert.Error(t, err)
+	_, err = DecryptPerson(ecPriv, hash, "RQ==")
+	assert.Error(t, err)
+}
+
+func TestNewPersonEncrypterUnsupportedKey(t *testing.T) {
+	pub, _, err := ed25519.GenerateKey(rand.Reader)
+	assert.NoError(t, err)
+
+	_, err = NewPersonEncrypter(pub, nil)
+	assert.Error(t, err)
+}
+
+func TestPersonHash(t *testing.T) {
+	enc1, _ := NewPersonEncrypter(&ecdsa.PublicKey{}, []byte("secret"))
+	enc2, _ := NewPersonEncrypter(&ecdsa.PublicKey{}, []byte("other secret"))
+
+	assert.Equal(t, enc1.Hash(persons["HM"]), enc1.Hash(persons["HM"]))
+	assert.NotEqual(t, enc1.Hash(persons["HM"]), enc1.Hash(persons["GM"]))
+	assert.NotEqual(t, enc1.Hash(persons["HM"]), enc2.Hash(persons["HM"]))
+	assert.Equal(t, 64, len(enc1.Hash(persons["HM"])))
+}
+
+func TestLoadSecretKey(t *testing.T) {
+	filePath := path.Join(t.TempDir(), "hash.key")
+	key, err := LoadSecretKey(filePath)
+	assert.NoError(t, err)
+	assert.Len(t, key, 32)
+
+	info, err := os.Stat(filePath)
+	assert.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+	again, err := LoadSecretKey(filePath)
+	assert.NoError(t, err)
+	assert.Equal(t, key, again)
+
+	assert.NoError(t, os.WriteFile(filePath, []byte("no key\n"), 0600))
+	_, err = LoadSecretKey(filePath)
+	assert.Error(t, err)
+}
+
+func TestReadAndCreateSecretKey(t *testing.T) {
+	filePath := path.Join(t.TempDir(), "summary.key")
+	_, err := ReadSecretKey(filePath)
+	assert.True(t, errors.Is(err, os.ErrNotExist))
+
+	key, err := CreateSecretKey(filePath)
+	assert.NoError(t, err)
+	assert.Len(t, key, 32)
+
+	actual, err := ReadSecretKey(filePath)
+	assert.NoError(t, err)
+	assert.Equal(t, key, actual)
+
+	// An existing key is never replaced.
+	_, err = CreateSecretKey(filePath)
+	assert.Error(t, err)
+	actual, err = ReadSecretKey(filePath)
+	assert.NoError(t, err)
+	assert.Equal(t, key, actual)
+}
+
+func TestIsInDir(t *testing.T) {
+	assert.True(t, IsInDir("data/hash.key", "data"))
+	assert.True(t, IsInDir("data/keys/hash.key", "data"))
+	assert.True(t, IsInDir("./data/../data/hash.key", "data"))
+	assert.False(t, IsInDir("hash.key", "data"))
+	assert.False(t, IsInDir("../data/hash.key", "data"))
+	assert.True(t, IsInDir("..keys/hash.key", "."))
+	assert.True(t, IsInDir("keys/hash.key", "."))
+}
+
+func TestWriteEncryptedToJournalFile(t *testing.T) {
+	dir := t.TempDir()
+	date := timeutil.NewDate(2021, 10, 16)
+	expected := []JournalEntry{
+		{timeutil.NewTimestamp(2021, 10, 16, 15, 30, 0), "aabbccddeeff", Login, locs["DH"], persons["MM"]},
+		{timeutil.NewTimestamp(2021, 10, 16, 17, 20, 0), "aabbccddeeff", Logout, locs["DH"], persons["MM"]},
+	}
+
+	priv, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+
+	enc, err := NewPersonEncrypter(priv.Public(), []byte("secret"))
+	assert.NoError(t, err)
+
+	for _, e := range expected {
+		assert.NoError(t, WriteEncryptedToJournalFile(dir, &e, enc))
+	}
+
+	content, err := os.ReadFile(FilePath(dir, date))
+	assert.NoError(t, err)
+	assert.NotContains(t, string(content), persons["MM"].LastName)
+	assert.True(t, strings.HasPrefix(string(content), "2021/10/16 15:30:00 UTC,aabbccddeeff,0,DHBW Mosbach,"+enc.Hash(persons["MM"])+","))
+
+	// Decrypted with the private key
+	j, err := ReadJournalWithKey(dir, date, priv)
+	assert.NoError(t, err)
+	assert.Equal(t, expected, j.Entries)
+
+	// Pseudonymized without the private key
+	j, err = ReadJournal(dir, date)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(j.Entries))
+	assert.Equal(t, Pseudonym(enc.Hash(persons["MM"])), j.Entries[0].Person)
+	assert.Equal(t, j.Entries[0].Person, j.Entries[1].Person)
+	assert.Equal(t, expected[0].Location, j.Entries[0].Location)
+	assert.Equal(t, expected[1].Timestamp, j.Entries[1].Timestamp)
+
+	// Failed with a wrong private key
+	other, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+	_, err = ReadJournalsWithKey(dir, date, date, other)
+	assert.Error(t, err)
+}
+
+func TestReadKeysFailed(t *testing.T) {
+	_, err := ReadPublicKey("testdata/missing.pem")
+	assert.Error(t, err)
+
+	_, err = ReadPublicKey("testdata/key.pem")
+	assert.Error(t, err)
+
+	_, err = ReadPrivateKey("testdata/cert.pem")
+	assert.Error(t, err)
+
+	_, err = ReadPrivateKey("testdata/2021-10-15.journal")
+	assert.Error(t, err)
+}
+
+func TestReadECKeys(t *testing.T) {
+	dir := t.TempDir()
+	priv, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+
+	privBytes, err := x509.MarshalECPrivateKey(priv)
+	assert.NoError(t, err)
+	pubBytes, err := x509.MarshalPKIXPublicKey(priv.Public())
+	assert.NoError(t, err)
+
+	privPath, pubPath := path.Join(dir, "key.pem"), path.Join(dir, "pub.pem")
+	assert.NoError(t, os.WriteFile(privPath, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: privBytes}), 0600))
+	assert.NoError(t, os.WriteFile(pubPath, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: pubBytes}), 0600))
+
+	actualPriv, err := ReadPrivateKey(privPath)
+	assert.NoError(t, err)
+	assert.True(t, priv.Equal(actualPriv))
+
+	actualPub, err := ReadPublicKey(pubPath)
+	assert.NoError(t, err)
+	assert.True(t, priv.PublicKey.Equal(actualPub))
+}
@@ -0,0 +1,147 @@
+// This source file is part of the attendance list project
+// as a part of the go lecture by H. Neemann.
+// For this reason you have no permission to use, modify or
+// share this code without the agreement of the authors.
+//
+// Matriculation numbers of the authors: 5703004, 5736465
+
+// Package journal provides functionality for writing text based journal files.
+package journal
+
+import (
+	"crypto"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/dateiexplorer/attendancelist/internal/timeutil"
+)
+
+// ListJournals returns the dates of all journal files in the dir directory in
+// chronological order. Files which aren't named like a journal file are
+// ignored.
+//
+// Returns an error if the directory cannot be read.
+func ListJournals(dir string) ([]timeutil.Date, error) {
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("cannot list journal files: %w", err)
+	}
+
+	dates := make([]timeutil.Date, 0)
+	for _, f := range files {
+		name := f.Name()
+		if f.IsDir() || !strings.HasSuffix(name, journalFileExtension) {
+			continue
+		}
+
+		t, err := time.Parse("2006-01-02", strings.TrimSuffix(name, journalFileExtension))
+		if err != nil {
+			continue
+		}
+
+		dates = append(dates, timeutil.NewDate(t.Year(), t.Month(), t.Day()))
+	}
+
+	sort.Slice(dates, func(i, j int) bool {
+		return dates[i].Before(dates[j])
+	})
+
+	return dates, nil
+}
+
+// RemoveEntries removes all lines from the journal file for the date in the
+// dir directory whose JournalEntry is selected by the remove function. All
+// other lines are kept unchanged, so encrypted or pseudonymized lines stay
+// encrypted or pseudonymized. The file is replaced in one step, a failure
+// leaves the original file in place.
+//
+// Encrypted Persons are decrypted with priv before they are passed to remove.
+// Because the Person of an encrypted line is unknown without the key, an error
+// is returned if the file contains encrypted lines and priv is nil.
+//
+// If the service may be writing to the file, the caller must hold the lock of
+// the directory, see LockDir. Otherwise lines appended between reading and
+// replacing the file are lost.
+//
+// Returns the number of removed lines. An error is returned if the file cannot
+// be read, parsed or written.
+func RemoveEntries(dir string, date timeutil.Date, priv crypto.PrivateKey, remove func(e JournalEntry) bool) (int, error) {
+	filePath := FilePath(dir, date)
+	info, err := os.Stat(filePath)
+	if err != nil {
+		return 0, fmt.Errorf("cannot open journal file: %w", err)
+	}
+
+	data, err := ioutil.ReadFile(filePath)
+	if err != nil {
+		return 0, fmt.Errorf("cannot read journal file: %w", err)
+	}
+
+	var kept strings.Builder
+	removed := 0
+	lines := strings.Split(string(data), "\n")
+	for i, line := range lines {
+		if len(line) == 0 {
+			continue
+		}
+
+		if priv == nil && len(strings.Split(line, ",")) == 6 {
+			return 0, errors.New("cannot remove entries from journal file: encrypted persons require the private key")
+		}
+
+		e, err := parseLine(line, i, priv)
+		if err != nil {
+			return 0, err
+		}
+
+		if remove(e) {
+			removed++
+			continue
+		}
+
+		kept.WriteString(line + "\n")
+	}
+
+	if removed == 0 {
+		return 0, nil
+	}
+
+	if err := replaceFile(filePath, []byte(kept.String()), info.Mode().Perm()); err != nil {
+		return 0, fmt.Errorf("cannot write journal file: %w", err)
+	}
+
+	return removed, nil
+}
+
+// replaceFile writes the data to a temporary file in the directory of
+// filePath and renames it to filePath afterwards.
+func replaceFile(filePath string, data []byte, perm os.FileMode) error {
+	f, err := ioutil.TempFile(path.Dir(filePath), "."+path.Base(filePath)+"-*")
+	if err != nil {
+		return err
+	}
+
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return err
+	}
+
+	if err := f.Close(); err != nil {
+		os.Remove(f.Name())
+		return err
+	}
+
+	if err := os.Chmod(f.Name(), perm); err != nil {
+		os.Remove(f.Name())
+		return err
+	}
+
+	return os.Rename(f.Name(), filePath)
+}
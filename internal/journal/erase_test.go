@@ -0,0 +1,150 @@
+// This source file is part of the attendance list project
+// as a part of the go lecture by H. Neemann.
+// For this reason you have no permission to use, modify or
+// share this code without the agreement of the authors.
+//
+// Matriculation numbers of the authors: 5703004, 5736465
+
+// Package journal provides functionality for writing text based journal files.
+package journal
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"os"
+	"path"
+	"strings"
+	"testing"
+
+	"github.com/dateiexplorer/attendancelist/internal/timeutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestListJournals(t *testing.T) {
+	dates, err := ListJournals("testdata")
+	assert.NoError(t, err)
+	assert.Equal(t, []timeutil.Date{
+		timeutil.NewDate(2020, 1, 1),
+		timeutil.NewDate(2020, 1, 2),
+		timeutil.NewDate(2021, 10, 15),
+		timeutil.NewDate(2021, 11, 30),
+	}, dates)
+
+	_, err = ListJournals("testdata/missing")
+	assert.Error(t, err)
+}
+
+func TestRemoveEntries(t *testing.T) {
+	dir := t.TempDir()
+	date := timeutil.NewDate(2021, 10, 15)
+	source, err := os.ReadFile(FilePath("testdata", date))
+	assert.NoError(t, err)
+	assert.NoError(t, os.WriteFile(FilePath(dir, date), source, 0640))
+
+	isHans := func(e JournalEntry) bool {
+		return e.Person == persons["HM"]
+	}
+
+	removed, err := RemoveEntries(dir, date, nil, isHans)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, removed)
+
+	// The other lines are kept unchanged.
+	content, err := os.ReadFile(FilePath(dir, date))
+	assert.NoError(t, err)
+	assert.NotContains(t, string(content), "Feldweg")
+	for _, line := range strings.Split(strings.TrimSpace(string(content)), "\n") {
+		assert.Contains(t, string(source), line)
+	}
+
+	info, err := os.Stat(FilePath(dir, date))
+	assert.NoError(t, err)
+	assert.Equal(t, os.FileMode(0640), info.Mode().Perm())
+
+	// Nothing left to remove
+	removed, err = RemoveEntries(dir, date, nil, isHans)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, removed)
+
+	// No temporary files are left.
+	files, err := os.ReadDir(dir)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(files))
+}
+
+func TestRemoveEntriesEncrypted(t *testing.T) {
+	dir := t.TempDir()
+	date := timeutil.NewDate(2021, 10, 16)
+	entries := []JournalEntry{
+		{timeutil.NewTimestamp(2021, 10, 16, 15, 30, 0), "aabbccddeeff", Login, locs["DH"], persons["MM"]},
+		{timeutil.NewTimestamp(2021, 10, 16, 16, 0, 0), "ffeeddccbbaa", Login, locs["DH"], persons["HM"]},
+		{timeutil.NewTimestamp(2021, 10, 16, 17, 20, 0), "aabbccddeeff", Logout, locs["DH"], persons["MM"]},
+	}
+
+	priv, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+
+	enc, err := NewPersonEncrypter(priv.Public(), []byte("secret"))
+	assert.NoError(t, err)
+
+	for _, e := range entries {
+		assert.NoError(t, WriteEncryptedToJournalFile(dir, &e, enc))
+	}
+
+	isMax := func(e JournalEntry) bool {
+		return e.Person == persons["MM"]
+	}
+
+	// The persons are unknown without the key.
+	_, err = RemoveEntries(dir, date, nil, isMax)
+	assert.Error(t, err)
+
+	removed, err := RemoveEntries(dir, date, priv, isMax)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, removed)
+
+	j, err := ReadJournalWithKey(dir, date, priv)
+	assert.NoError(t, err)
+	assert.Equal(t, entries[1:2], j.Entries)
+}
+
+func TestRemoveEntriesFailed(t *testing.T) {
+	_, err := RemoveEntries(t.TempDir(), timeutil.NewDate(2021, 10, 15), nil, func(e JournalEntry) bool {
+		return true
+	})
+	assert.Error(t, err)
+}
+
+func TestRegistryWriteToFileAndResolve(t *testing.T) {
+	filePath := path.Join(t.TempDir(), RegistryFileName)
+	r := Registry{"id-2": persons["HM"], "id-1": persons["MM"]}
+	assert.NoError(t, r.WriteToFile(filePath))
+
+	content, err := os.ReadFile(filePath)
+	assert.NoError(t, err)
+	assert.Equal(t, "id-1,Max,Mustermann,Musterstraße,20,74821,Mosbach\n"+
+		"id-2,Hans,Müller,Feldweg,12,74722,Buchen\n", string(content))
+
+	info, err := os.Stat(filePath)
+	assert.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+	actual, err := ReadRegistry(filePath)
+	assert.NoError(t, err)
+	assert.Equal(t, r, actual)
+
+	assert.Equal(t, persons["MM"], r.Resolve(Pseudonym("id-1")))
+	assert.Equal(t, Pseudonym("id-3"), r.Resolve(Pseudonym("id-3")))
+	assert.Equal(t, persons["GM"], r.Resolve(persons["GM"]))
+}
+
+func TestPersonMappingRemove(t *testing.T) {
+	m := PersonMapping{}
+	m.Merge(persons["HM"], persons["LM"], persons["TT"])
+	m.Merge(persons["MM"], persons["AM"])
+
+	assert.Equal(t, 2, m.Remove(persons["HM"]))
+	assert.Equal(t, PersonMapping{persons["AM"]: persons["MM"]}, m)
+	assert.Equal(t, 0, m.Remove(persons["GM"]))
+}
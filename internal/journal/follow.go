@@ -0,0 +1,103 @@
+// This source file is part of the attendance list project
+// as a part of the go lecture by H. Neemann.
+// For this reason you have no permission to use, modify or
+// share this code without the agreement of the authors.
+//
+// Matriculation numbers of the authors: 5703004, 5736465
+
+package journal
+
+import (
+	"bytes"
+	"crypto"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+
+	"github.com/dateiexplorer/attendancelist/internal/timeutil"
+)
+
+// A Follower reads the JournalEntries which are appended to the journal file
+// of the current day while the service is running.
+type Follower struct {
+	dir  string
+	priv crypto.PrivateKey
+	// Date is the date of the journal file which is followed.
+	Date timeutil.Date
+	// offset is the position in the file after the last complete line read,
+	// line the number of this line.
+	offset int64
+	line   int
+}
+
+// NewFollower returns a Follower for the journal file of the date in the dir
+// directory. Encrypted Persons are decrypted with priv like ReadJournalWithKey.
+func NewFollower(dir string, date timeutil.Date, priv crypto.PrivateKey) *Follower {
+	return &Follower{dir: dir, priv: priv, Date: date}
+}
+
+// Poll returns the JournalEntries appended to the journal file since the last
+// call, the first call returns all JournalEntries of the file. A line which is
+// not completely written yet is returned by a later call.
+//
+// If the Date today is after the Date of the Follower, the rest of the old
+// journal file is read and the Follower switches to the journal file of today.
+// A journal file which doesn't exist yet contains no JournalEntries.
+//
+// An error is returned if the journal file cannot be read or parsed.
+func (f *Follower) Poll(today timeutil.Date) ([]JournalEntry, error) {
+	entries, err := f.read()
+	if err != nil {
+		return entries, err
+	}
+
+	if f.Date.Before(today) {
+		f.Date, f.offset, f.line = today, 0, 0
+		next, err := f.read()
+		return append(entries, next...), err
+	}
+
+	return entries, nil
+}
+
+// read returns the JournalEntries of the complete lines after the offset.
+func (f *Follower) read() ([]JournalEntry, error) {
+	entries := []JournalEntry{}
+	file, err := os.Open(FilePath(f.dir, f.Date))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return entries, nil
+		}
+
+		return entries, fmt.Errorf("cannot open journal file: %w", err)
+	}
+	defer file.Close()
+
+	if _, err := file.Seek(f.offset, io.SeekStart); err != nil {
+		return entries, fmt.Errorf("cannot read journal file: %w", err)
+	}
+
+	data, err := ioutil.ReadAll(file)
+	if err != nil {
+		return entries, fmt.Errorf("cannot read journal file: %w", err)
+	}
+
+	for {
+		i := bytes.IndexByte(data, '\n')
+		if i < 0 {
+			return entries, nil
+		}
+
+		entry, err := parseLine(string(data[:i]), f.line, f.priv)
+		if err != nil {
+			return entries, err
+		}
+
+		entries = append(entries, entry)
+		data = data[i+1:]
+		f.offset += int64(i + 1)
+		f.line++
+	}
+}
@@ -0,0 +1,84 @@
+// This source file is part of the attendance list project
+// as a part of the go lecture by H. Neemann.
+// For this reason you have no permission to use, modify or
+// share this code without the agreement of the authors.
+//
+// Matriculation numbers of the authors: 5703004, 5736465
+
+// Package journal provides functionality for writing text based journal files.
+package journal
+
+import (
+	"os"
+	"testing"
+
+	"github.com/dateiexplorer/attendancelist/internal/timeutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFollowerPoll(t *testing.T) {
+	dir := t.TempDir()
+	date := timeutil.NewDate(2021, 10, 15)
+	f := NewFollower(dir, date, nil)
+
+	// The journal file doesn't exist yet.
+	entries, err := f.Poll(date)
+	assert.NoError(t, err)
+	assert.Empty(t, entries)
+
+	hans := NewPerson("Hans", "Müller", "Feldweg", "12", "74722", "Buchen")
+	login := NewJournalEntry(timeutil.NewTimestamp(2021, 10, 15, 9, 0, 0), "a", Login, "DHBW Mosbach", hans)
+	logout := NewJournalEntry(timeutil.NewTimestamp(2021, 10, 15, 10, 0, 0), "a", Logout, "DHBW Mosbach", hans)
+	assert.NoError(t, WriteToJournalFile(dir, &login))
+
+	entries, err = f.Poll(date)
+	assert.NoError(t, err)
+	assert.Equal(t, []JournalEntry{login}, entries)
+
+	entries, err = f.Poll(date)
+	assert.NoError(t, err)
+	assert.Empty(t, entries)
+
+	// A partially written line is returned when it is complete.
+	file, err := os.OpenFile(FilePath(dir, date), os.O_APPEND|os.O_WRONLY, 0644)
+	assert.NoError(t, err)
+	defer file.Close()
+
+	_, err = file.WriteString("2021/10/15 10:00:00 UTC,a,1,DHBW Mosbach,Hans,Müller")
+	assert.NoError(t, err)
+	entries, err = f.Poll(date)
+	assert.NoError(t, err)
+	assert.Empty(t, entries)
+
+	_, err = file.WriteString(",Feldweg,12,74722,Buchen\n")
+	assert.NoError(t, err)
+	entries, err = f.Poll(date)
+	assert.NoError(t, err)
+	assert.Equal(t, []JournalEntry{logout}, entries)
+}
+
+func TestFollowerPollNextDay(t *testing.T) {
+	dir := t.TempDir()
+	date := timeutil.NewDate(2021, 10, 15)
+	f := NewFollower(dir, date, nil)
+
+	hans := NewPerson("Hans", "Müller", "Feldweg", "12", "74722", "Buchen")
+	late := NewJournalEntry(timeutil.NewTimestamp(2021, 10, 15, 23, 59, 59), "a", Login, "DHBW Mosbach", hans)
+	early := NewJournalEntry(timeutil.NewTimestamp(2021, 10, 16, 0, 0, 1), "a", Logout, "DHBW Mosbach", hans)
+	assert.NoError(t, WriteToJournalFile(dir, &late))
+	assert.NoError(t, WriteToJournalFile(dir, &early))
+
+	entries, err := f.Poll(date.AddDays(1))
+	assert.NoError(t, err)
+	assert.Equal(t, []JournalEntry{late, early}, entries)
+	assert.Equal(t, date.AddDays(1), f.Date)
+}
+
+func TestFollowerPollInvalidLine(t *testing.T) {
+	dir := t.TempDir()
+	date := timeutil.NewDate(2021, 10, 15)
+	assert.NoError(t, os.WriteFile(FilePath(dir, date), []byte("invalid,a,0,DHBW Mosbach,1\n"), 0644))
+
+	_, err := NewFollower(dir, date, nil).Poll(date)
+	assert.Error(t, err)
+}
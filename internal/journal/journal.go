@@ -10,6 +10,8 @@ package journal
 
 import (
 	"bufio"
+	"crypto"
+	"errors"
 	"fmt"
 	"os"
 	"path"
@@ -23,6 +25,12 @@ import (
 
 const journalFileExtension = ".journal"
 
+// FilePath returns the path of the journal file for the date in the dir
+// directory.
+func FilePath(dir string, date timeutil.Date) string {
+	return path.Join(dir, date.String()+journalFileExtension)
+}
+
 // A Journal represents a journal file with a date an severeal JournalEntries
 type Journal struct {
 	Date    timeutil.Date
@@ -53,13 +61,34 @@ type Journal struct {
 // zipCode is the zipCode attribute of an Address
 // and city is the city attribute of an Address.
 //
+// If the Person is encrypted, the line holds the keyed person hash and the
+// ciphertext instead of the attributes of the Person:
+//
+// timestamp,sessionIdentifier,event,locationName,personHash,ciphertext
+//
+// ReadJournal cannot decrypt Persons, they are replaced by their Pseudonym.
+//
+// In pseudonymized mode the line holds only the ID of the Person which is
+// also replaced by its Pseudonym, see Registry:
+//
+// timestamp,sessionIdentifier,event,locationName,personID
+//
 // An error returned if the specific journal file cannot be open or cannot be
 // parsed. If an error occured the functions returns also an empty Journal which
 // contains the date and an empty slice of JournalEntries.
 func ReadJournal(dir string, date timeutil.Date) (Journal, error) {
+	return ReadJournalWithKey(dir, date, nil)
+}
+
+// ReadJournalWithKey works like ReadJournal but decrypts encrypted Persons with
+// the private key priv. If priv is nil, encrypted Persons are replaced by their
+// Pseudonym.
+//
+// An error returned if an encrypted Person cannot be decrypted with the key.
+func ReadJournalWithKey(dir string, date timeutil.Date, priv crypto.PrivateKey) (Journal, error) {
 	entries := []JournalEntry{}
 	// Open file
-	f, err := os.Open(path.Join(dir, date.String()+journalFileExtension))
+	f, err := os.Open(FilePath(dir, date))
 	if err != nil {
 		return Journal{date, []JournalEntry{}}, fmt.Errorf("cannot open journal file: %w", err)
 	}
@@ -69,25 +98,80 @@ func ReadJournal(dir string, date timeutil.Date) (Journal, error) {
 	scanner := bufio.NewScanner(f)
 	scanner.Split(bufio.ScanLines)
 	for i := 0; scanner.Scan(); i++ {
-		values := strings.Split(scanner.Text(), ",")
-		timestamp, err := timeutil.ParseTimestamp(values[0])
+		entry, err := parseLine(scanner.Text(), i, priv)
 		if err != nil {
-			return Journal{date, []JournalEntry{}}, fmt.Errorf("cannot parse timestamp of journal file on line %v: %w", i, err)
+			return Journal{date, []JournalEntry{}}, err
 		}
 
-		id := values[1]
-		action, err := strconv.Atoi(values[2])
+		entries = append(entries, entry)
+	}
+
+	return Journal{date, entries}, nil
+}
+
+// parseLine parses the line with the number i of a journal file. Encrypted
+// Persons are decrypted with priv if it isn't nil.
+func parseLine(line string, i int, priv crypto.PrivateKey) (JournalEntry, error) {
+	values := strings.Split(line, ",")
+	timestamp, err := timeutil.ParseTimestamp(values[0])
+	if err != nil {
+		return JournalEntry{}, fmt.Errorf("cannot parse timestamp of journal file on line %v: %w", i, err)
+	}
+
+	id := values[1]
+	action, err := strconv.Atoi(values[2])
+	if err != nil {
+		return JournalEntry{}, fmt.Errorf("cannot parse action of journal file on line %v: %w", i, err)
+	}
+
+	location := Location(values[3])
+	var person Person
+	switch {
+	case len(values) == 5:
+		person = Pseudonym(values[4])
+	case len(values) == 6 && priv == nil:
+		person = Pseudonym(values[4])
+	case len(values) == 6:
+		person, err = DecryptPerson(priv, values[4], values[5])
 		if err != nil {
-			return Journal{date, []JournalEntry{}}, fmt.Errorf("cannot parse action of journal file on line %v: %w", i, err)
+			return JournalEntry{}, fmt.Errorf("cannot decrypt person of journal file on line %v: %w", i, err)
 		}
+	default:
+		person = Person{values[4], values[5], Address{values[6], values[7], values[8], values[9]}}
+	}
 
-		location := Location(values[3])
-		person := Person{values[4], values[5], Address{values[6], values[7], values[8], values[9]}}
-		entry := JournalEntry{timestamp, id, Event(action), location, person}
-		entries = append(entries, entry)
+	return JournalEntry{timestamp, id, Event(action), location, person}, nil
+}
+
+// ReadJournals reads the journal files for every date from the Date from to the
+// Date to (inclusive) from the dir directory and returns them in chronological
+// order.
+//
+// A missing journal file is not an error, because there are days without any
+// visitors, so the date is skipped. An error is returned if a journal file
+// exists but cannot be read or parsed.
+func ReadJournals(dir string, from, to timeutil.Date) ([]Journal, error) {
+	return ReadJournalsWithKey(dir, from, to, nil)
+}
+
+// ReadJournalsWithKey works like ReadJournals but decrypts encrypted Persons
+// with the private key priv like ReadJournalWithKey.
+func ReadJournalsWithKey(dir string, from, to timeutil.Date, priv crypto.PrivateKey) ([]Journal, error) {
+	journals := make([]Journal, 0)
+	for date := from; !to.Before(date); date = date.AddDays(1) {
+		j, err := ReadJournalWithKey(dir, date, priv)
+		if err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				continue
+			}
+
+			return journals, fmt.Errorf("cannot read journal for %v: %w", date, err)
+		}
+
+		journals = append(journals, j)
 	}
 
-	return Journal{date, entries}, nil
+	return journals, nil
 }
 
 // WriteToJournalFile appends a JournalEntry e to the corresponding journal file in
@@ -97,20 +181,40 @@ func ReadJournal(dir string, date timeutil.Date) (Journal, error) {
 //
 // The functions returns an error if the writing operations causes an error.
 func WriteToJournalFile(dir string, e *JournalEntry) error {
+	s := fmt.Sprintf("%v,%v,%v,%v,%v,%v,%v,%v,%v,%v\n", e.Timestamp, e.SessionID, e.Event, e.Location, e.Person.FirstName, e.Person.LastName,
+		e.Person.Address.Street, e.Person.Address.Number, e.Person.Address.ZipCode, e.Person.Address.City)
+	return appendToJournalFile(dir, e.Timestamp.Date(), s)
+}
+
+// WriteEncryptedToJournalFile works like WriteToJournalFile but encrypts the
+// Person of the JournalEntry e with the PersonEncrypter enc. The location,
+// timestamp and keyed hash of the Person stay in the clear.
+//
+// The functions returns an error if the encryption or the writing operations
+// causes an error.
+func WriteEncryptedToJournalFile(dir string, e *JournalEntry, enc *PersonEncrypter) error {
+	hash, ciphertext, err := enc.Encrypt(e.Person)
+	if err != nil {
+		return fmt.Errorf("cannot write to journal file: %w", err)
+	}
+
+	s := fmt.Sprintf("%v,%v,%v,%v,%v,%v\n", e.Timestamp, e.SessionID, e.Event, e.Location, hash, ciphertext)
+	return appendToJournalFile(dir, e.Timestamp.Date(), s)
+}
+
+// appendToJournalFile appends the line s to the journal file for the date in
+// the dir directory.
+func appendToJournalFile(dir string, date timeutil.Date, s string) error {
 	// Get wright journal file for this entry.
 	// Every day has it's own journal file.
-	date := e.Timestamp.Date()
-	f, err := os.OpenFile(path.Join(dir, date.String()+journalFileExtension), os.O_APPEND|os.O_WRONLY|os.O_CREATE, 0644)
+	f, err := os.OpenFile(FilePath(dir, date), os.O_APPEND|os.O_WRONLY|os.O_CREATE, 0644)
 	if err != nil {
 		return fmt.Errorf("cannot write to journal file: %w", err)
 	}
 	defer f.Close()
 
 	// Write to journal file
-	s := fmt.Sprintf("%v,%v,%v,%v,%v,%v,%v,%v,%v,%v\n", e.Timestamp, e.SessionID, e.Event, e.Location, e.Person.FirstName, e.Person.LastName,
-		e.Person.Address.Street, e.Person.Address.Number, e.Person.Address.ZipCode, e.Person.Address.City)
-	_, err = f.WriteString(s)
-	if err != nil {
+	if _, err = f.WriteString(s); err != nil {
 		return fmt.Errorf("cannot write to journal file: %w", err)
 	}
 
@@ -210,17 +314,19 @@ func (j Journal) GetContactsForPerson(p *Person) ContactList {
 			}
 		} else {
 			// Get all entries from local map
-			for key, value := range local {
+			for _, value := range local {
 				contacts = append(contacts, NewContact(value.Person, value.Location, value.Timestamp, end))
-				delete(local, key)
 			}
 
-			// Get corresponding contacts from global map
-			for _, value := range global {
-				if value.Location == loc {
+			// Get corresponding contacts from global map, the entries of the
+			// local map are still present but already counted.
+			for key, value := range global {
+				if _, ok := local[key]; !ok && value.Location == loc {
 					contacts = append(contacts, NewContact(value.Person, value.Location, startTimestamp, end))
 				}
 			}
+
+			local = nil
 		}
 
 		startTimestamp = timeutil.InvalidTimestamp
@@ -236,8 +342,10 @@ func (j Journal) GetContactsForPerson(p *Person) ContactList {
 					local[entry.SessionID] = entry
 				} else {
 					hold[entry.SessionID] = NewContact(entry.Person, entry.Location, entry.Timestamp, timeutil.NewTimestamp(j.Date.Year, j.Date.Month, j.Date.Day, 23, 59, 59))
-					global[entry.SessionID] = entry
 				}
+
+				// Also needed if the searched person logs in again later.
+				global[entry.SessionID] = entry
 			case Logout:
 				if startTimestamp != timeutil.InvalidTimestamp && entry.Location == loc {
 					if value, ok := local[entry.SessionID]; ok {
@@ -427,3 +535,20 @@ type AttendanceEntry struct {
 func NewAttendanceEntry(person Person, login, logout timeutil.Timestamp) AttendanceEntry {
 	return AttendanceEntry{person, login, logout}
 }
+
+// Person returns the Person of the AttendanceEntry.
+func (e AttendanceEntry) Person() Person {
+	return e.person
+}
+
+// Login returns the login Timestamp of the AttendanceEntry. It is the
+// InvalidTimestamp if the login is unknown.
+func (e AttendanceEntry) Login() timeutil.Timestamp {
+	return e.login
+}
+
+// Logout returns the logout Timestamp of the AttendanceEntry. It is the
+// InvalidTimestamp if the logout is unknown.
+func (e AttendanceEntry) Logout() timeutil.Timestamp {
+	return e.logout
+}
@@ -80,6 +80,19 @@ func TestReadMalformedJournal(t *testing.T) {
 	}
 }
 
+func TestReadJournals(t *testing.T) {
+	journals, err := ReadJournals("testdata", timeutil.NewDate(2021, 10, 14), timeutil.NewDate(2021, 11, 30))
+	assert.NoError(t, err)
+	assert.Len(t, journals, 2)
+	assert.Equal(t, timeutil.NewDate(2021, 10, 15), journals[0].Date)
+	assert.Equal(t, timeutil.NewDate(2021, 11, 30), journals[1].Date)
+}
+
+func TestReadJournalsMalformedJournal(t *testing.T) {
+	_, err := ReadJournals("testdata", timeutil.NewDate(2019, 12, 31), timeutil.NewDate(2020, 1, 3))
+	assert.Error(t, err)
+}
+
 func TestGetVisitedLocationsForPerson(t *testing.T) {
 	expected := []Location{
 		"DHBW Mosbach",
@@ -185,6 +198,29 @@ func TestGetContactsForPersonOnlyLoggedOut(t *testing.T) {
 	assert.Equal(t, len(expected), len(actual))
 }
 
+func TestGetContactsForPersonLoggedInAgain(t *testing.T) {
+	// Gisela logs in during the first visit of Max and stays until his second
+	// visit.
+	at := func(h int) timeutil.Timestamp {
+		return timeutil.NewTimestamp(2021, 10, 16, h, 0, 0)
+	}
+
+	journal := Journal{timeutil.NewDate(2021, 10, 16), []JournalEntry{
+		{at(9), "aa", Login, locs["DH"], persons["MM"]},
+		{at(10), "bb", Login, locs["DH"], persons["GM"]},
+		{at(11), "aa", Logout, locs["DH"], persons["MM"]},
+		{at(12), "cc", Login, locs["DH"], persons["MM"]},
+		{at(13), "cc", Logout, locs["DH"], persons["MM"]},
+		{at(14), "bb", Logout, locs["DH"], persons["GM"]},
+	}}
+
+	p := persons["MM"]
+	assert.Equal(t, ContactList{
+		NewContact(persons["GM"], locs["DH"], at(10), at(11)),
+		NewContact(persons["GM"], locs["DH"], at(12), at(13)),
+	}, journal.GetContactsForPerson(&p))
+}
+
 func TestContactListNextEntry(t *testing.T) {
 	expected := [][]string{
 		{"Hans", "Müller", "Feldweg", "12", "74722", "Buchen", "DHBW Mosbach", "2021/11/30 12:00:00 UTC", "2021/11/30 12:30:00 UTC", "30m0s"},
@@ -243,6 +279,13 @@ func TestAttendanceListHeader(t *testing.T) {
 	assert.Equal(t, expected, actual)
 }
 
+func TestAttendanceEntryGetters(t *testing.T) {
+	e := NewAttendanceEntry(persons["HM"], timeutil.NewTimestamp(2021, 10, 15, 13, 40, 11), timeutil.InvalidTimestamp)
+	assert.Equal(t, persons["HM"], e.Person())
+	assert.Equal(t, timeutil.NewTimestamp(2021, 10, 15, 13, 40, 11), e.Login())
+	assert.Equal(t, timeutil.InvalidTimestamp, e.Logout())
+}
+
 func TestNewPerson(t *testing.T) {
 	p := NewPerson("Max", "Mustermann", "Musterstraße", "20", "74821", "Mosbach")
 	assert.Equal(t, persons["MM"], p)
@@ -299,3 +342,7 @@ func TestNewJournalEntry(t *testing.T) {
 	actual := NewJournalEntry(timeutil.NewTimestamp(2021, 10, 16, 15, 30, 0), "aabbccddeeff", Login, locs["DH"], persons["MM"])
 	assert.Equal(t, expected, actual)
 }
+
+func TestFilePath(t *testing.T) {
+	assert.Equal(t, "data/2021-10-15.journal", FilePath("data", timeutil.NewDate(2021, 10, 15)))
+}
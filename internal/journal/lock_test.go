@@ -0,0 +1,48 @@
+// This source file is part of the attendance list project
+// as a part of the go lecture by H. Neemann.
+// For this reason you have no permission to use, modify or
+// share this code without the agreement of the authors.
+//
+// Matriculation numbers of the authors: 5703004, 5736465
+
+// Package journal provides functionality for writing text based journal files.
+package journal
+
+import (
+	"path"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLockDir(t *testing.T) {
+	dir := t.TempDir()
+	unlock, err := LockDir(dir)
+	assert.NoError(t, err)
+
+	locked := make(chan bool)
+	go func() {
+		unlock, err := LockDir(dir)
+		assert.NoError(t, err)
+		locked <- true
+		unlock()
+	}()
+
+	// The second lock waits until the first one is released.
+	select {
+	case <-locked:
+		t.Fatal("the directory was locked twice")
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	unlock()
+	select {
+	case <-locked:
+	case <-time.After(5 * time.Second):
+		t.Fatal("the directory wasn't locked after the release")
+	}
+
+	_, err = LockDir(path.Join(dir, "missing"))
+	assert.Error(t, err)
+}
@@ -0,0 +1,158 @@
+// This source file is part of the attendance list project
+// as a part of the go lecture by H. Neemann.
+// For this reason you have no permission to use, modify or
+// share this code without the agreement of the authors.
+//
+// Matriculation numbers of the authors: 5703004, 5736465
+
+// Package journal provides functionality for writing text based journal files.
+package journal
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sort"
+)
+
+// The name of the mapping file in the journal directory. It maps probably
+// identical persons to one person and is applied to every journal if it exists.
+const MappingFileName = "identities.json"
+
+// A PersonMapping maps Persons which are known to be the same visitor, e.g.
+// because of typos, to one canonical Person.
+type PersonMapping map[Person]Person
+
+// A jsonMerge is used for marshalling and unmarshalling a PersonMapping,
+// because a JSON object cannot have a Person as key.
+type jsonMerge struct {
+	Person  Person   `json:"person"`
+	Aliases []Person `json:"aliases"`
+}
+
+// ReadPersonMapping reads a PersonMapping from a JSON file in the filesystem.
+//
+// Returns an error if the file cannot be read or parsed.
+func ReadPersonMapping(path string) (PersonMapping, error) {
+	bytes, err := ioutil.ReadFile(path)
+	if err != nil {
+		return PersonMapping{}, fmt.Errorf("cannot read mapping file: %w", err)
+	}
+
+	var merges []jsonMerge
+	if err := json.Unmarshal(bytes, &merges); err != nil {
+		return PersonMapping{}, fmt.Errorf("cannot parse mapping file: %w", err)
+	}
+
+	m := PersonMapping{}
+	for _, merge := range merges {
+		m.Merge(merge.Person, merge.Aliases...)
+	}
+
+	return m, nil
+}
+
+// ReadPersonMappingIfExists works like ReadPersonMapping but returns an empty
+// PersonMapping without an error if the file doesn't exist.
+func ReadPersonMappingIfExists(path string) (PersonMapping, error) {
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return PersonMapping{}, nil
+	}
+
+	return ReadPersonMapping(path)
+}
+
+// WriteToFile writes the PersonMapping as JSON file to the filesystem.
+// An existing file will be overwritten.
+//
+// Returns an error if the file cannot be written.
+func (m PersonMapping) WriteToFile(path string) error {
+	groups := make(map[Person][]Person)
+	for alias, p := range m {
+		groups[p] = append(groups[p], alias)
+	}
+
+	merges := make([]jsonMerge, 0, len(groups))
+	for p, aliases := range groups {
+		sort.Slice(aliases, func(i, j int) bool {
+			return aliases[i].String() < aliases[j].String()
+		})
+
+		merges = append(merges, jsonMerge{p, aliases})
+	}
+
+	// Sort to get the same file for the same mapping.
+	sort.Slice(merges, func(i, j int) bool {
+		return merges[i].Person.String() < merges[j].Person.String()
+	})
+
+	bytes, err := json.MarshalIndent(merges, "", "  ")
+	if err != nil {
+		return fmt.Errorf("cannot marshal mapping: %w", err)
+	}
+
+	if err := ioutil.WriteFile(path, bytes, 0600); err != nil {
+		return fmt.Errorf("cannot write mapping file: %w", err)
+	}
+
+	return nil
+}
+
+// Merge maps all aliases to the canonical Person p.
+// If an alias was the canonical Person of other merges before, these are
+// redirected to p as well, so every Person is resolved in one step.
+func (m PersonMapping) Merge(p Person, aliases ...Person) {
+	p = m.Resolve(p)
+	for _, alias := range aliases {
+		if alias == p {
+			continue
+		}
+
+		for other, canonical := range m {
+			if canonical == alias {
+				m[other] = p
+			}
+		}
+
+		m[alias] = p
+	}
+}
+
+// Resolve returns the canonical Person for p. If p is not merged with another
+// Person, p itself will be returned.
+func (m PersonMapping) Resolve(p Person) Person {
+	if canonical, ok := m[p]; ok {
+		return canonical
+	}
+
+	return p
+}
+
+// Remove removes the canonical Person p and all of its aliases from the
+// PersonMapping and returns the number of removed aliases.
+func (m PersonMapping) Remove(p Person) int {
+	removed := 0
+	for alias, canonical := range m {
+		if canonical == p || alias == p {
+			delete(m, alias)
+			removed++
+		}
+	}
+
+	return removed
+}
+
+// ApplyMapping returns a copy of the Journal j in which every Person is
+// replaced by its canonical Person in the PersonMapping m.
+// Because all analysis functions compare Persons, they treat merged Persons as
+// one Person on the returned Journal.
+func (j Journal) ApplyMapping(m PersonMapping) Journal {
+	entries := make([]JournalEntry, 0, len(j.Entries))
+	for _, e := range j.Entries {
+		e.Person = m.Resolve(e.Person)
+		entries = append(entries, e)
+	}
+
+	return Journal{j.Date, entries}
+}
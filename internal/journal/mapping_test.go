@@ -0,0 +1,84 @@
+// This source file is part of the attendance list project
+// as a part of the go lecture by H. Neemann.
+// For this reason you have no permission to use, modify or
+// share this code without the agreement of the authors.
+//
+// Matriculation numbers of the authors: 5703004, 5736465
+
+// Package journal provides functionality for writing text based journal files.
+package journal
+
+import (
+	"path"
+	"testing"
+
+	"github.com/dateiexplorer/attendancelist/internal/timeutil"
+	"github.com/stretchr/testify/assert"
+)
+
+var typos = map[string]Person{
+	"HM": {"Hans", "Mueller", Address{"Feldweg", "12", "74722", "Buchen"}},
+	"Hm": {"hans", "Müler", Address{"Feldweg", "12", "74722", "Buchen"}},
+}
+
+func TestPersonMappingResolve(t *testing.T) {
+	m := PersonMapping{}
+	m.Merge(persons["HM"], typos["HM"])
+
+	assert.Equal(t, persons["HM"], m.Resolve(typos["HM"]))
+	assert.Equal(t, persons["HM"], m.Resolve(persons["HM"]))
+	assert.Equal(t, persons["MM"], m.Resolve(persons["MM"]))
+}
+
+func TestPersonMappingMergeRedirectsCanonical(t *testing.T) {
+	m := PersonMapping{}
+	m.Merge(typos["HM"], typos["Hm"])
+	m.Merge(persons["HM"], typos["HM"])
+
+	assert.Equal(t, persons["HM"], m.Resolve(typos["HM"]))
+	assert.Equal(t, persons["HM"], m.Resolve(typos["Hm"]))
+}
+
+func TestPersonMappingWriteAndRead(t *testing.T) {
+	expected := PersonMapping{}
+	expected.Merge(persons["HM"], typos["HM"], typos["Hm"])
+
+	file := path.Join(t.TempDir(), "identities.json")
+	err := expected.WriteToFile(file)
+	assert.NoError(t, err)
+
+	actual, err := ReadPersonMapping(file)
+	assert.NoError(t, err)
+	assert.Equal(t, expected, actual)
+}
+
+func TestReadPersonMappingIfExists(t *testing.T) {
+	actual, err := ReadPersonMappingIfExists(path.Join(t.TempDir(), "identities.json"))
+	assert.NoError(t, err)
+	assert.Equal(t, PersonMapping{}, actual)
+
+	_, err = ReadPersonMapping(path.Join(t.TempDir(), "identities.json"))
+	assert.Error(t, err)
+}
+
+func TestJournalApplyMapping(t *testing.T) {
+	j := Journal{timeutil.NewDate(2021, 10, 15), []JournalEntry{
+		{timeutil.NewTimestamp(2021, 10, 15, 6, 20, 13), "d61ec70b78628e15", Login, locs["DH"], typos["HM"]},
+		{timeutil.NewTimestamp(2021, 10, 15, 13, 40, 10), "d61ec70b78628e15", Logout, locs["DH"], typos["HM"]},
+		{timeutil.NewTimestamp(2021, 10, 15, 13, 40, 11), "5faacdf0e6e7b44a", Login, locs["AM"], persons["HM"]},
+	}}
+
+	m := PersonMapping{}
+	m.Merge(persons["HM"], typos["HM"])
+
+	actual := j.ApplyMapping(m)
+	for _, e := range actual.Entries {
+		assert.Equal(t, persons["HM"], e.Person)
+	}
+
+	// The original journal must not be modified.
+	assert.Equal(t, typos["HM"], j.Entries[0].Person)
+
+	p := persons["HM"]
+	assert.Len(t, actual.GetVisitedLocationsForPerson(&p), 2)
+}
@@ -0,0 +1,222 @@
+// This source file is part of the attendance list project
+// as a part of the go lecture by H. Neemann.
+// For this reason you have no permission to use, modify or
+// share this code without the agreement of the authors.
+//
+// Matriculation numbers of the authors: 5703004, 5736465
+
+// Package journal provides functionality for writing text based journal files.
+package journal
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/dateiexplorer/attendancelist/internal/timeutil"
+)
+
+// An Occupancy describes how full a Location was on the day of a Journal.
+//
+// The Timeline is a step function of the number of concurrent visitors, Peak is
+// its maximum which is reached first at PeakTime. Visits is the number of
+// Sessions and AverageDwell the mean duration of these Sessions. Hourly holds
+// the number of visitors present at any time during each hour of the day, a
+// visitor who leaves on the hour doesn't count for the following hour.
+type Occupancy struct {
+	Location     Location
+	Timeline     OccupancyTimeline
+	Peak         int
+	PeakTime     timeutil.Timestamp
+	Visits       int
+	AverageDwell time.Duration
+	Hourly       [24]int
+}
+
+// GetOccupancy returns the Occupancy for every Location of the Journal j,
+// sorted by the name of the Location.
+func (j Journal) GetOccupancy() OccupancyList {
+	m := make(map[Location][]Session)
+	for _, s := range j.GetSessions() {
+		m[s.Location] = append(m[s.Location], s)
+	}
+
+	list := make(OccupancyList, 0, len(m))
+	for l, sessions := range m {
+		list = append(list, newOccupancy(j.Date, l, sessions))
+	}
+
+	sort.Slice(list, func(a, b int) bool {
+		return list[a].Location < list[b].Location
+	})
+
+	return list
+}
+
+// GetOccupancyForLocation returns the Occupancy of the Location l.
+//
+// If the Location wasn't found in the Journal an Occupancy with an empty
+// Timeline will be returned.
+func (j Journal) GetOccupancyForLocation(l Location) Occupancy {
+	sessions := make([]Session, 0)
+	for _, s := range j.GetSessions() {
+		if s.Location == l {
+			sessions = append(sessions, s)
+		}
+	}
+
+	return newOccupancy(j.Date, l, sessions)
+}
+
+// newOccupancy calculates the Occupancy of the Location l on the Date d from
+// the Sessions at this Location.
+func newOccupancy(d timeutil.Date, l Location, sessions []Session) Occupancy {
+	o := Occupancy{Location: l, Timeline: OccupancyTimeline{}, PeakTime: timeutil.InvalidTimestamp, Visits: len(sessions)}
+
+	type change struct {
+		timestamp timeutil.Timestamp
+		delta     int
+	}
+
+	changes := make([]change, 0, 2*len(sessions))
+	var dwell time.Duration
+	for _, s := range sessions {
+		start, end := s.Start(d), s.End(d)
+		changes = append(changes, change{start, 1}, change{end, -1})
+		dwell += end.Sub(start.Time)
+
+		// The end is exclusive, a visitor who leaves on the hour isn't present
+		// during the next hour.
+		last := end.Hour()
+		if end.Minute() == 0 && end.Second() == 0 && end.Nanosecond() == 0 && last > start.Hour() {
+			last--
+		}
+
+		for h := start.Hour(); h <= last; h++ {
+			o.Hourly[h]++
+		}
+	}
+
+	if len(sessions) > 0 {
+		o.AverageDwell = (dwell / time.Duration(len(sessions))).Round(time.Second)
+	}
+
+	// Leaving visitors are processed first, so that a logout and a login at
+	// the same time doesn't count as an additional visitor.
+	sort.SliceStable(changes, func(a, b int) bool {
+		if changes[a].timestamp.Equal(changes[b].timestamp.Time) {
+			return changes[a].delta < changes[b].delta
+		}
+
+		return changes[a].timestamp.Before(changes[b].timestamp.Time)
+	})
+
+	count := 0
+	for _, c := range changes {
+		count += c.delta
+		last := len(o.Timeline) - 1
+		if last >= 0 && o.Timeline[last].Timestamp == c.timestamp {
+			o.Timeline[last].Count = count
+		} else {
+			o.Timeline = append(o.Timeline, OccupancyStep{l, c.timestamp, count})
+		}
+
+		if count > o.Peak {
+			o.Peak = count
+			o.PeakTime = c.timestamp
+		}
+	}
+
+	return o
+}
+
+// An OccupancyStep represents a point in time from which on Count visitors
+// are at a Location.
+type OccupancyStep struct {
+	Location  Location
+	Timestamp timeutil.Timestamp
+	Count     int
+}
+
+// An OccupancyTimeline is a step function of OccupancySteps.
+type OccupancyTimeline []OccupancyStep
+
+// NextEntry returns a read-only channel that loops through the hole
+// OccupancyTimeline and returns data of the OccupancyStep as a string slice.
+//
+// Used to convert an OccupancyTimeline to any file format.
+func (t OccupancyTimeline) NextEntry() <-chan []string {
+	entries := make(chan []string)
+	go func() {
+		for _, s := range t {
+			entries <- []string{string(s.Location), s.Timestamp.String(), strconv.Itoa(s.Count)}
+		}
+
+		close(entries)
+	}()
+
+	return entries
+}
+
+// Header returns a string slice which describes the data given by the NextEntry
+// function.
+//
+// Used to convert an OccupancyTimeline to any file format.
+func (t OccupancyTimeline) Header() []string {
+	return []string{"Location", "Timestamp", "Count"}
+}
+
+// An OccupancyList is a collection of Occupancies.
+type OccupancyList []Occupancy
+
+// Timeline returns the Timelines of all Occupancies in the OccupancyList as one
+// OccupancyTimeline.
+func (l OccupancyList) Timeline() OccupancyTimeline {
+	timeline := OccupancyTimeline{}
+	for _, o := range l {
+		timeline = append(timeline, o.Timeline...)
+	}
+
+	return timeline
+}
+
+// NextEntry returns a read-only channel that loops through the hole
+// OccupancyList and returns the statistics of the Occupancy as a string slice.
+//
+// Used to convert an OccupancyList to any file format.
+func (l OccupancyList) NextEntry() <-chan []string {
+	entries := make(chan []string)
+	go func() {
+		for _, o := range l {
+			peakTime := ""
+			if o.PeakTime != timeutil.InvalidTimestamp {
+				peakTime = o.PeakTime.Clock()
+			}
+
+			entry := []string{string(o.Location), strconv.Itoa(o.Visits), strconv.Itoa(o.Peak), peakTime, o.AverageDwell.String()}
+			for _, n := range o.Hourly {
+				entry = append(entry, strconv.Itoa(n))
+			}
+
+			entries <- entry
+		}
+
+		close(entries)
+	}()
+
+	return entries
+}
+
+// Header returns a string slice which describes the data given by the NextEntry
+// function. The columns "00" to "23" hold the visitors per hour.
+//
+// Used to convert an OccupancyList to any file format.
+func (l OccupancyList) Header() []string {
+	header := []string{"Location", "Visits", "Peak", "PeakTime", "AverageDwell"}
+	for h := 0; h < 24; h++ {
+		header = append(header, fmt.Sprintf("%02d", h))
+	}
+
+	return header
+}
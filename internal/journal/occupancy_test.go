@@ -0,0 +1,129 @@
+// This source file is part of the attendance list project
+// as a part of the go lecture by H. Neemann.
+// For this reason you have no permission to use, modify or
+// share this code without the agreement of the authors.
+//
+// Matriculation numbers of the authors: 5703004, 5736465
+
+// Package journal provides functionality for writing text based journal files.
+package journal
+
+import (
+	"testing"
+	"time"
+
+	"github.com/dateiexplorer/attendancelist/internal/timeutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetOccupancyForLocation(t *testing.T) {
+	expected := Occupancy{
+		Location: locs["AM"],
+		Timeline: OccupancyTimeline{
+			{locs["AM"], timeutil.NewTimestamp(2021, 10, 15, 13, 40, 11), 1},
+			{locs["AM"], timeutil.NewTimestamp(2021, 10, 15, 17, 32, 45), 2},
+			{locs["AM"], timeutil.NewTimestamp(2021, 10, 15, 19, 15, 12), 1},
+			{locs["AM"], timeutil.NewTimestamp(2021, 10, 15, 23, 59, 59), 0},
+		},
+		Peak:         2,
+		PeakTime:     timeutil.NewTimestamp(2021, 10, 15, 17, 32, 45),
+		Visits:       2,
+		AverageDwell: 6*time.Hour + 1*time.Minute + 8*time.Second,
+		Hourly:       [24]int{13: 1, 14: 1, 15: 1, 16: 1, 17: 2, 18: 2, 19: 2, 20: 1, 21: 1, 22: 1, 23: 1},
+	}
+
+	journal, err := ReadJournal("testdata", timeutil.NewDate(2021, 10, 15))
+	assert.NoError(t, err)
+	assert.Equal(t, expected, journal.GetOccupancyForLocation(locs["AM"]))
+}
+
+func TestGetOccupancyForLocationNotExistingLocation(t *testing.T) {
+	expected := Occupancy{Location: "Night Club", Timeline: OccupancyTimeline{}, PeakTime: timeutil.InvalidTimestamp}
+
+	journal, err := ReadJournal("testdata", timeutil.NewDate(2021, 10, 15))
+	assert.NoError(t, err)
+	assert.Equal(t, expected, journal.GetOccupancyForLocation("Night Club"))
+}
+
+func TestGetOccupancy(t *testing.T) {
+	journal, err := ReadJournal("testdata", timeutil.NewDate(2021, 10, 15))
+	assert.NoError(t, err)
+
+	list := journal.GetOccupancy()
+	assert.Len(t, list, 2)
+	assert.Equal(t, locs["AM"], list[0].Location)
+	assert.Equal(t, locs["DH"], list[1].Location)
+
+	assert.Equal(t, 5, list[1].Peak)
+	assert.Equal(t, timeutil.NewTimestamp(2021, 10, 15, 13, 30, 0), list[1].PeakTime)
+	assert.Equal(t, 0, list[1].Timeline[len(list[1].Timeline)-1].Count)
+	assert.Len(t, list.Timeline(), len(list[0].Timeline)+len(list[1].Timeline))
+}
+
+func TestGetOccupancySameTimestamp(t *testing.T) {
+	j := Journal{timeutil.NewDate(2021, 10, 15), []JournalEntry{
+		{timeutil.NewTimestamp(2021, 10, 15, 8, 0, 0), "aaaa", Login, locs["DH"], persons["MM"]},
+		{timeutil.NewTimestamp(2021, 10, 15, 9, 0, 0), "aaaa", Logout, locs["DH"], persons["MM"]},
+		{timeutil.NewTimestamp(2021, 10, 15, 9, 0, 0), "bbbb", Login, locs["DH"], persons["GM"]},
+		{timeutil.NewTimestamp(2021, 10, 15, 10, 0, 0), "bbbb", Logout, locs["DH"], persons["GM"]},
+	}}
+
+	o := j.GetOccupancyForLocation(locs["DH"])
+	assert.Equal(t, 1, o.Peak)
+	assert.Len(t, o.Timeline, 3)
+	assert.Equal(t, time.Hour, o.AverageDwell)
+}
+
+func TestGetOccupancyLogoutOnTheHour(t *testing.T) {
+	j := Journal{timeutil.NewDate(2021, 10, 15), []JournalEntry{
+		{timeutil.NewTimestamp(2021, 10, 15, 14, 20, 0), "aaaa", Login, locs["DH"], persons["MM"]},
+		{timeutil.NewTimestamp(2021, 10, 15, 15, 0, 0), "aaaa", Logout, locs["DH"], persons["MM"]},
+		{timeutil.NewTimestamp(2021, 10, 15, 16, 0, 0), "bbbb", Login, locs["DH"], persons["GM"]},
+		{timeutil.NewTimestamp(2021, 10, 15, 16, 0, 0), "bbbb", Logout, locs["DH"], persons["GM"]},
+	}}
+
+	// The first visitor leaves at 15:00:00, the second visits only for a
+	// moment on the hour.
+	o := j.GetOccupancyForLocation(locs["DH"])
+	assert.Equal(t, [24]int{14: 1, 16: 1}, o.Hourly)
+}
+
+func TestOccupancyListNextEntry(t *testing.T) {
+	expected := []string{"Alte Mälzerei", "2", "2", "17:32:45", "6h1m8s",
+		"0", "0", "0", "0", "0", "0", "0", "0", "0", "0", "0", "0", "0",
+		"1", "1", "1", "1", "2", "2", "2", "1", "1", "1", "1"}
+
+	journal, err := ReadJournal("testdata", timeutil.NewDate(2021, 10, 15))
+	assert.NoError(t, err)
+
+	list := OccupancyList{journal.GetOccupancyForLocation(locs["AM"])}
+	counter := 0
+	for actual := range list.NextEntry() {
+		assert.Equal(t, expected, actual)
+		counter++
+	}
+
+	assert.Equal(t, 1, counter)
+	assert.Equal(t, len(expected), len(list.Header()))
+}
+
+func TestOccupancyTimelineNextEntry(t *testing.T) {
+	expected := [][]string{
+		{"Alte Mälzerei", "2021/10/15 13:40:11 UTC", "1"},
+		{"Alte Mälzerei", "2021/10/15 17:32:45 UTC", "2"},
+	}
+
+	timeline := OccupancyTimeline{
+		{locs["AM"], timeutil.NewTimestamp(2021, 10, 15, 13, 40, 11), 1},
+		{locs["AM"], timeutil.NewTimestamp(2021, 10, 15, 17, 32, 45), 2},
+	}
+
+	counter := 0
+	for actual := range timeline.NextEntry() {
+		assert.Equal(t, expected[counter], actual)
+		counter++
+	}
+
+	assert.Equal(t, len(timeline), counter)
+	assert.Equal(t, []string{"Location", "Timestamp", "Count"}, timeline.Header())
+}
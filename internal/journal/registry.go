@@ -0,0 +1,222 @@
+// This source file is part of the attendance list project
+// as a part of the go lecture by H. Neemann.
+// For this reason you have no permission to use, modify or
+// share this code without the agreement of the authors.
+//
+// Matriculation numbers of the authors: 5703004, 5736465
+
+// Package journal provides functionality for writing text based journal files.
+package journal
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+// The name of the registry file in the journal directory.
+const RegistryFileName = "persons.registry"
+
+// A Registry maps the pseudonymous IDs of Persons to the Persons.
+//
+// A journal file in pseudonymized mode holds only the ID of a Person, the
+// attributes of the Person are stored once in a separate registry file which
+// can be protected by stricter access rights. A registry file is a text file
+// with the layout
+//
+// id,firstName,lastName,street,number,zipCode,city
+type Registry map[string]Person
+
+// ReadRegistry reads a Registry from a registry file in the filesystem.
+//
+// Returns an error if the file cannot be read or parsed.
+func ReadRegistry(path string) (Registry, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return Registry{}, fmt.Errorf("cannot open registry file: %w", err)
+	}
+	defer f.Close()
+
+	r := Registry{}
+	scanner := bufio.NewScanner(f)
+	for i := 0; scanner.Scan(); i++ {
+		values := strings.Split(scanner.Text(), ",")
+		if len(values) != 7 {
+			return Registry{}, fmt.Errorf("cannot parse registry file on line %v: expected 7 values, got %v", i, len(values))
+		}
+
+		r[values[0]] = Person{values[1], values[2], Address{values[3], values[4], values[5], values[6]}}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return Registry{}, fmt.Errorf("cannot read registry file: %w", err)
+	}
+
+	return r, nil
+}
+
+// ReadRegistryIfExists works like ReadRegistry but returns an empty Registry
+// without an error if the file doesn't exist.
+func ReadRegistryIfExists(path string) (Registry, error) {
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return Registry{}, nil
+	}
+
+	return ReadRegistry(path)
+}
+
+// WriteToFile writes the Registry sorted by ID to the filesystem. An existing
+// file will be overwritten, a new file is created with permissions for the
+// owner only.
+//
+// Returns an error if the file cannot be written.
+func (r Registry) WriteToFile(path string) error {
+	ids := make([]string, 0, len(r))
+	for id := range r {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	var b strings.Builder
+	for _, id := range ids {
+		p := r[id]
+		fmt.Fprintf(&b, "%v,%v,%v,%v,%v,%v,%v\n", id, p.FirstName, p.LastName, p.Address.Street, p.Address.Number, p.Address.ZipCode, p.Address.City)
+	}
+
+	info, err := os.Stat(path)
+	perm := os.FileMode(0600)
+	if err == nil {
+		perm = info.Mode().Perm()
+	}
+
+	if err := replaceFile(path, []byte(b.String()), perm); err != nil {
+		return fmt.Errorf("cannot write registry file: %w", err)
+	}
+
+	return nil
+}
+
+// Resolve returns the registered Person if p is the Pseudonym of an ID of the
+// Registry r, otherwise p itself.
+func (r Registry) Resolve(p Person) Person {
+	if registered, ok := r[p.FirstName]; ok && p == Pseudonym(p.FirstName) {
+		return registered
+	}
+
+	return p
+}
+
+// ApplyRegistry returns a copy of the Journal j where every pseudonymous Person
+// with an ID of the Registry r is replaced by the registered Person.
+func (j Journal) ApplyRegistry(r Registry) Journal {
+	entries := make([]JournalEntry, len(j.Entries))
+	for i, e := range j.Entries {
+		e.Person = r.Resolve(e.Person)
+		entries[i] = e
+	}
+
+	return Journal{j.Date, entries}
+}
+
+// A Pseudonymizer writes JournalEntries in pseudonymized mode. The journal
+// files hold only the ID of the Person, the Person itself is appended to the
+// registry file the first time its ID appears.
+//
+// If the registry file is changed by someone else, e.g. a Person is erased,
+// the Pseudonymizer reads it again before the next write.
+//
+// A Pseudonymizer is not safe for concurrent use.
+type Pseudonymizer struct {
+	registryPath string
+	id           func(p Person) (string, error)
+	known        map[string]bool
+	// modified is the modification time of the registry file when the
+	// Pseudonymizer read or wrote it the last time.
+	modified time.Time
+}
+
+// NewPseudonymizer returns a new Pseudonymizer which writes to the registry
+// file at registryPath. The id function returns the stable pseudonymous ID of
+// a Person.
+//
+// Returns an error if an existing registry file cannot be read.
+func NewPseudonymizer(registryPath string, id func(p Person) (string, error)) (*Pseudonymizer, error) {
+	ps := &Pseudonymizer{registryPath: registryPath, id: id}
+	if err := ps.load(); err != nil {
+		return nil, err
+	}
+
+	return ps, nil
+}
+
+// load reads the IDs of the registry file if it was changed since the last
+// time.
+func (ps *Pseudonymizer) load() error {
+	var modified time.Time
+	if info, err := os.Stat(ps.registryPath); err == nil {
+		modified = info.ModTime()
+	}
+
+	if ps.known != nil && modified.Equal(ps.modified) {
+		return nil
+	}
+
+	r, err := ReadRegistryIfExists(ps.registryPath)
+	if err != nil {
+		return err
+	}
+
+	ps.known = make(map[string]bool, len(r))
+	for id := range r {
+		ps.known[id] = true
+	}
+
+	ps.modified = modified
+	return nil
+}
+
+// WriteToJournalFile works like the function WriteToJournalFile but writes
+// only the ID of the Person to the journal file:
+//
+// timestamp,sessionIdentifier,event,locationName,personID
+//
+// If the ID is unknown, the Person is appended to the registry file which is
+// created with permissions for the owner only.
+//
+// The functions returns an error if the ID cannot be computed or the writing
+// operations causes an error.
+func (ps *Pseudonymizer) WriteToJournalFile(dir string, e *JournalEntry) error {
+	id, err := ps.id(e.Person)
+	if err != nil {
+		return fmt.Errorf("cannot write to journal file: %w", err)
+	}
+
+	if err := ps.load(); err != nil {
+		return fmt.Errorf("cannot write to registry file: %w", err)
+	}
+
+	if !ps.known[id] {
+		f, err := os.OpenFile(ps.registryPath, os.O_APPEND|os.O_WRONLY|os.O_CREATE, 0600)
+		if err != nil {
+			return fmt.Errorf("cannot write to registry file: %w", err)
+		}
+
+		p := e.Person
+		_, err = fmt.Fprintf(f, "%v,%v,%v,%v,%v,%v,%v\n", id, p.FirstName, p.LastName, p.Address.Street, p.Address.Number, p.Address.ZipCode, p.Address.City)
+		f.Close()
+		if err != nil {
+			return fmt.Errorf("cannot write to registry file: %w", err)
+		}
+
+		ps.known[id] = true
+		if info, err := os.Stat(ps.registryPath); err == nil {
+			ps.modified = info.ModTime()
+		}
+	}
+
+	s := fmt.Sprintf("%v,%v,%v,%v,%v\n", e.Timestamp, e.SessionID, e.Event, e.Location, id)
+	return appendToJournalFile(dir, e.Timestamp.Date(), s)
+}
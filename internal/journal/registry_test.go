@@ -0,0 +1,139 @@
+// This source file is part of the attendance list project
+// as a part of the go lecture by H. Neemann.
+// For this reason you have no permission to use, modify or
+// share this code without the agreement of the authors.
+//
+// Matriculation numbers of the authors: 5703004, 5736465
+
+// Package journal provides functionality for writing text based journal files.
+package journal
+
+import (
+	"errors"
+	"os"
+	"path"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dateiexplorer/attendancelist/internal/timeutil"
+	"github.com/stretchr/testify/assert"
+)
+
+// testID returns the last name of a Person as ID.
+func testID(p Person) (string, error) {
+	return "id-" + p.LastName, nil
+}
+
+func TestPseudonymizerWriteToJournalFile(t *testing.T) {
+	dir := t.TempDir()
+	registryPath := path.Join(dir, RegistryFileName)
+	date := timeutil.NewDate(2021, 10, 16)
+	expected := []JournalEntry{
+		{timeutil.NewTimestamp(2021, 10, 16, 15, 30, 0), "aabbccddeeff", Login, locs["DH"], persons["MM"]},
+		{timeutil.NewTimestamp(2021, 10, 16, 16, 0, 0), "ffeeddccbbaa", Login, locs["DH"], persons["HM"]},
+		{timeutil.NewTimestamp(2021, 10, 16, 17, 20, 0), "aabbccddeeff", Logout, locs["DH"], persons["MM"]},
+	}
+
+	ps, err := NewPseudonymizer(registryPath, testID)
+	assert.NoError(t, err)
+
+	for _, e := range expected {
+		assert.NoError(t, ps.WriteToJournalFile(dir, &e))
+	}
+
+	content, err := os.ReadFile(FilePath(dir, date))
+	assert.NoError(t, err)
+	assert.Equal(t, "2021/10/16 15:30:00 UTC,aabbccddeeff,0,DHBW Mosbach,id-Mustermann\n"+
+		"2021/10/16 16:00:00 UTC,ffeeddccbbaa,0,DHBW Mosbach,id-Müller\n"+
+		"2021/10/16 17:20:00 UTC,aabbccddeeff,1,DHBW Mosbach,id-Mustermann\n", string(content))
+
+	// Every Person is registered once.
+	content, err = os.ReadFile(registryPath)
+	assert.NoError(t, err)
+	assert.Equal(t, "id-Mustermann,Max,Mustermann,Musterstraße,20,74821,Mosbach\n"+
+		"id-Müller,Hans,Müller,Feldweg,12,74722,Buchen\n", string(content))
+
+	info, err := os.Stat(registryPath)
+	assert.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+	// A new Pseudonymizer knows the registered Persons.
+	ps, err = NewPseudonymizer(registryPath, testID)
+	assert.NoError(t, err)
+	assert.NoError(t, ps.WriteToJournalFile(dir, &expected[1]))
+	content, err = os.ReadFile(registryPath)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, strings.Count(string(content), "\n"))
+
+	// The journal holds only pseudonyms.
+	j, err := ReadJournal(dir, date)
+	assert.NoError(t, err)
+	assert.Equal(t, Pseudonym("id-Mustermann"), j.Entries[0].Person)
+
+	// Join the journal with the registry.
+	r, err := ReadRegistry(registryPath)
+	assert.NoError(t, err)
+	assert.Equal(t, expected, j.ApplyRegistry(r).Entries[:3])
+}
+
+func TestPseudonymizerRegistryReplaced(t *testing.T) {
+	dir := t.TempDir()
+	registryPath := path.Join(dir, RegistryFileName)
+	e := JournalEntry{timeutil.NewTimestamp(2021, 10, 16, 15, 30, 0), "aabbccddeeff", Login, locs["DH"], persons["MM"]}
+
+	ps, err := NewPseudonymizer(registryPath, testID)
+	assert.NoError(t, err)
+	assert.NoError(t, ps.WriteToJournalFile(dir, &e))
+
+	// The Person is erased from the registry while the Pseudonymizer runs, a
+	// later visit registers it again.
+	assert.NoError(t, Registry{}.WriteToFile(registryPath))
+	assert.NoError(t, os.Chtimes(registryPath, time.Now(), time.Now().Add(time.Second)))
+	assert.NoError(t, ps.WriteToJournalFile(dir, &e))
+
+	r, err := ReadRegistry(registryPath)
+	assert.NoError(t, err)
+	assert.Equal(t, Registry{"id-Mustermann": persons["MM"]}, r)
+}
+
+func TestPseudonymizerFailed(t *testing.T) {
+	dir := t.TempDir()
+	ps, err := NewPseudonymizer(path.Join(dir, RegistryFileName), func(p Person) (string, error) {
+		return "", errors.New("no id")
+	})
+	assert.NoError(t, err)
+
+	e := JournalEntry{timeutil.NewTimestamp(2021, 10, 16, 15, 30, 0), "aabbccddeeff", Login, locs["DH"], persons["MM"]}
+	assert.Error(t, ps.WriteToJournalFile(dir, &e))
+
+	ps, err = NewPseudonymizer(path.Join(dir, "missing", RegistryFileName), testID)
+	assert.NoError(t, err)
+	assert.Error(t, ps.WriteToJournalFile(dir, &e))
+}
+
+func TestReadRegistryFailed(t *testing.T) {
+	_, err := ReadRegistry("testdata/missing.registry")
+	assert.Error(t, err)
+
+	// A journal file has too many values.
+	_, err = ReadRegistry("testdata/2021-10-15.journal")
+	assert.Error(t, err)
+
+	_, err = NewPseudonymizer("testdata/2021-10-15.journal", testID)
+	assert.Error(t, err)
+
+	r, err := ReadRegistryIfExists("testdata/missing.registry")
+	assert.NoError(t, err)
+	assert.Equal(t, Registry{}, r)
+}
+
+func TestApplyRegistryKeepsOtherPersons(t *testing.T) {
+	j := Journal{timeutil.NewDate(2021, 10, 16), []JournalEntry{
+		{timeutil.NewTimestamp(2021, 10, 16, 15, 30, 0), "aabbccddeeff", Login, locs["DH"], Pseudonym("unknown")},
+		{timeutil.NewTimestamp(2021, 10, 16, 15, 30, 0), "ffeeddccbbaa", Login, locs["DH"], persons["HM"]},
+	}}
+
+	actual := j.ApplyRegistry(Registry{"id-Müller": persons["MM"]})
+	assert.Equal(t, j, actual)
+}
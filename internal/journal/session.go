@@ -0,0 +1,188 @@
+// This source file is part of the attendance list project
+// as a part of the go lecture by H. Neemann.
+// For this reason you have no permission to use, modify or
+// share this code without the agreement of the authors.
+//
+// Matriculation numbers of the authors: 5703004, 5736465
+
+// Package journal provides functionality for writing text based journal files.
+package journal
+
+import (
+	"sort"
+	"time"
+
+	"github.com/dateiexplorer/attendancelist/internal/timeutil"
+)
+
+// A Session represents the stay of a Person at a Location, from the Login to
+// the Logout event with the same session identifier.
+//
+// If the Journal contains only one of the events, the other Timestamp is the
+// InvalidTimestamp.
+type Session struct {
+	ID       string
+	Person   Person
+	Location Location
+	Login    timeutil.Timestamp
+	Logout   timeutil.Timestamp
+}
+
+// Start returns the Login Timestamp of the Session. If the Login is unknown the
+// Session started before the journal, so the beginning of the Date d will be
+// returned.
+func (s Session) Start(d timeutil.Date) timeutil.Timestamp {
+	if s.Login == timeutil.InvalidTimestamp {
+		return timeutil.NewTimestamp(d.Year, d.Month, d.Day, 0, 0, 0)
+	}
+
+	return s.Login
+}
+
+// End returns the Logout Timestamp of the Session. If the Logout is unknown the
+// Session lasts until the end of the Date d.
+func (s Session) End(d timeutil.Date) timeutil.Timestamp {
+	if s.Logout == timeutil.InvalidTimestamp {
+		return timeutil.NewTimestamp(d.Year, d.Month, d.Day, 23, 59, 59)
+	}
+
+	return s.Logout
+}
+
+// Duration returns the time between Start and End of the Session on the
+// Date d.
+func (s Session) Duration(d timeutil.Date) time.Duration {
+	return s.End(d).Sub(s.Start(d).Time)
+}
+
+// GetSessions returns all Sessions of the Journal j, sorted by their Start.
+// Sessions with the same Start appear in the order of their first event in
+// the journal.
+func (j Journal) GetSessions() []Session {
+	m := make(map[string]int)
+	sessions := make([]Session, 0)
+	for _, e := range j.Entries {
+		i, ok := m[e.SessionID]
+		if !ok || (e.Event == Login && sessions[i].Login != timeutil.InvalidTimestamp) {
+			// A new session begins, even if the identifier was used before.
+			i = len(sessions)
+			m[e.SessionID] = i
+			sessions = append(sessions, Session{e.SessionID, e.Person, e.Location, timeutil.InvalidTimestamp, timeutil.InvalidTimestamp})
+		}
+
+		switch e.Event {
+		case Login:
+			sessions[i].Login = e.Timestamp
+		case Logout:
+			sessions[i].Logout = e.Timestamp
+			delete(m, e.SessionID)
+		}
+	}
+
+	sort.SliceStable(sessions, func(a, b int) bool {
+		return sessions[a].Start(j.Date).Before(sessions[b].Start(j.Date).Time)
+	})
+
+	return sessions
+}
+
+// GetSessionsAt returns all Sessions which are open at the Timestamp t, i.e.
+// every Person who is present at a Location at this instant.
+// This is the state of the Journal j after replaying all login and logout
+// events up to t. A Session which starts exactly at t is open, a Session which
+// ends exactly at t is closed.
+//
+// If locations are given, only Sessions at these Locations are returned.
+// The SessionList is sorted by Location and Start.
+func (j Journal) GetSessionsAt(t timeutil.Timestamp, locations ...Location) SessionList {
+	filter := make(map[Location]bool)
+	for _, l := range locations {
+		filter[l] = true
+	}
+
+	list := SessionList{}
+	for _, s := range j.GetSessions() {
+		if len(filter) > 0 && !filter[s.Location] {
+			continue
+		}
+
+		if !s.Start(j.Date).After(t.Time) && s.End(j.Date).After(t.Time) {
+			list = append(list, s)
+		}
+	}
+
+	sort.SliceStable(list, func(a, b int) bool {
+		return list[a].Location < list[b].Location
+	})
+
+	return list
+}
+
+// GetVisitorsForLocation returns every Person whose Session at the Location l
+// overlapped the time window from the Timestamp from to the Timestamp to.
+//
+// The Start and End of each returned Contact is the overlap of the Session
+// with the window and the Duration is the overlap duration. Sessions which
+// only touch the window are not returned. The ContactList is sorted by the
+// Start of the Sessions.
+func (j Journal) GetVisitorsForLocation(l Location, from, to timeutil.Timestamp) ContactList {
+	contacts := ContactList{}
+	for _, s := range j.GetSessions() {
+		if s.Location != l {
+			continue
+		}
+
+		start, end := s.Start(j.Date), s.End(j.Date)
+		if start.Before(from.Time) {
+			start = from
+		}
+		if end.After(to.Time) {
+			end = to
+		}
+
+		if end.After(start.Time) {
+			contacts = append(contacts, NewContact(s.Person, l, start, end))
+		}
+	}
+
+	return contacts
+}
+
+// A SessionList is a collection of Sessions.
+type SessionList []Session
+
+// NextEntry returns a read-only channel that loops through the hole SessionList
+// and returns data of the Session as a string slice.
+// An unknown Login or Logout is represented by an empty string.
+//
+// Used to convert a SessionList to any file format.
+func (l SessionList) NextEntry() <-chan []string {
+	entries := make(chan []string)
+	go func() {
+		for _, s := range l {
+			login := ""
+			if s.Login != timeutil.InvalidTimestamp {
+				login = s.Login.String()
+			}
+			logout := ""
+			if s.Logout != timeutil.InvalidTimestamp {
+				logout = s.Logout.String()
+			}
+			entries <- []string{string(s.Location), s.Person.FirstName, s.Person.LastName,
+				s.Person.Address.Street, s.Person.Address.Number, s.Person.Address.ZipCode, s.Person.Address.City,
+				login, logout}
+		}
+
+		close(entries)
+	}()
+
+	return entries
+}
+
+// Header returns a string slice which describes the data given by the NextEntry
+// function.
+//
+// Used to convert a SessionList to any file format.
+func (l SessionList) Header() []string {
+	return []string{"Location", "FirstName", "LastName", "Street", "Number", "ZipCode", "City", "Login", "Logout"}
+}
@@ -0,0 +1,146 @@
+// This source file is part of the attendance list project
+// as a part of the go lecture by H. Neemann.
+// For this reason you have no permission to use, modify or
+// share this code without the agreement of the authors.
+//
+// Matriculation numbers of the authors: 5703004, 5736465
+
+// Package journal provides functionality for writing text based journal files.
+package journal
+
+import (
+	"testing"
+	"time"
+
+	"github.com/dateiexplorer/attendancelist/internal/timeutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetSessions(t *testing.T) {
+	expected := []Session{
+		{"abcdefghijklmnop", persons["TT"], locs["DH"], timeutil.InvalidTimestamp, timeutil.NewTimestamp(2021, 11, 30, 14, 0, 0)},
+		{"d61ec70b78628e15", persons["HM"], locs["DH"], timeutil.NewTimestamp(2021, 11, 30, 6, 0, 0), timeutil.NewTimestamp(2021, 11, 30, 8, 0, 0)},
+		{"5faacdf0e6e7b44a", persons["HM"], locs["AM"], timeutil.NewTimestamp(2021, 11, 30, 8, 30, 0), timeutil.InvalidTimestamp},
+		{"989ce491d5df53c9", persons["GM"], locs["DH"], timeutil.NewTimestamp(2021, 11, 30, 9, 0, 0), timeutil.NewTimestamp(2021, 11, 30, 17, 0, 0)},
+		{"f797f342aebab436", persons["MM"], locs["DH"], timeutil.NewTimestamp(2021, 11, 30, 10, 0, 0), timeutil.NewTimestamp(2021, 11, 30, 15, 0, 0)},
+		{"1ce7549a51133e9f", persons["AM"], locs["DH"], timeutil.NewTimestamp(2021, 11, 30, 11, 0, 0), timeutil.NewTimestamp(2021, 11, 30, 12, 0, 0)},
+		{"68e7faee906ffd4c", persons["LM"], locs["DH"], timeutil.NewTimestamp(2021, 11, 30, 13, 0, 0), timeutil.NewTimestamp(2021, 11, 30, 16, 0, 0)},
+		{"aabbccddeeffgghh", persons["TT"], locs["DH"], timeutil.NewTimestamp(2021, 11, 30, 18, 0, 0), timeutil.InvalidTimestamp},
+		{"848dc86c0b5e62a0", persons["ON"], locs["DH"], timeutil.NewTimestamp(2021, 11, 30, 19, 0, 0), timeutil.InvalidTimestamp},
+	}
+
+	journal, err := ReadJournal("testdata", timeutil.NewDate(2021, 11, 30))
+	assert.NoError(t, err)
+	assert.Equal(t, expected, journal.GetSessions())
+}
+
+func TestGetSessionsReusedID(t *testing.T) {
+	j := Journal{timeutil.NewDate(2021, 10, 15), []JournalEntry{
+		{timeutil.NewTimestamp(2021, 10, 15, 8, 0, 0), "aaaa", Login, locs["DH"], persons["MM"]},
+		{timeutil.NewTimestamp(2021, 10, 15, 9, 0, 0), "aaaa", Logout, locs["DH"], persons["MM"]},
+		{timeutil.NewTimestamp(2021, 10, 15, 10, 0, 0), "aaaa", Login, locs["AM"], persons["MM"]},
+	}}
+
+	sessions := j.GetSessions()
+	assert.Len(t, sessions, 2)
+	assert.Equal(t, locs["AM"], sessions[1].Location)
+	assert.Equal(t, timeutil.InvalidTimestamp, sessions[1].Logout)
+}
+
+func TestSessionBounds(t *testing.T) {
+	date := timeutil.NewDate(2021, 11, 30)
+	s := Session{"aabbccddeeffgghh", persons["TT"], locs["DH"], timeutil.InvalidTimestamp, timeutil.InvalidTimestamp}
+
+	assert.Equal(t, timeutil.NewTimestamp(2021, 11, 30, 0, 0, 0), s.Start(date))
+	assert.Equal(t, timeutil.NewTimestamp(2021, 11, 30, 23, 59, 59), s.End(date))
+	assert.Equal(t, 23*time.Hour+59*time.Minute+59*time.Second, s.Duration(date))
+}
+
+func TestGetSessionsAt(t *testing.T) {
+	expected := SessionList{
+		{"5faacdf0e6e7b44a", persons["HM"], locs["AM"], timeutil.NewTimestamp(2021, 10, 15, 13, 40, 11), timeutil.InvalidTimestamp},
+		{"989ce491d5df53c9", persons["GM"], locs["DH"], timeutil.NewTimestamp(2021, 10, 15, 9, 15, 20), timeutil.NewTimestamp(2021, 10, 15, 16, 52, 0)},
+		{"f797f342aebab436", persons["MM"], locs["DH"], timeutil.NewTimestamp(2021, 10, 15, 12, 15, 30), timeutil.NewTimestamp(2021, 10, 15, 16, 48, 21)},
+		{"1ce7549a51133e9f", persons["AM"], locs["DH"], timeutil.NewTimestamp(2021, 10, 15, 12, 17, 20), timeutil.NewTimestamp(2021, 10, 15, 17, 15, 22)},
+		{"68e7faee906ffd4c", persons["LM"], locs["DH"], timeutil.NewTimestamp(2021, 10, 15, 13, 30, 0), timeutil.NewTimestamp(2021, 10, 15, 15, 42, 23)},
+	}
+
+	journal, err := ReadJournal("testdata", timeutil.NewDate(2021, 10, 15))
+	assert.NoError(t, err)
+
+	// Hans Müller logged out at DHBW Mosbach at 13:40:10 and logged in at the
+	// Alte Mälzerei at 13:40:11.
+	actual := journal.GetSessionsAt(timeutil.NewTimestamp(2021, 10, 15, 13, 40, 11))
+	assert.Equal(t, expected, actual)
+
+	actual = journal.GetSessionsAt(timeutil.NewTimestamp(2021, 10, 15, 13, 40, 11), locs["AM"])
+	assert.Equal(t, expected[:1], actual)
+}
+
+func TestGetSessionsAtNobodyPresent(t *testing.T) {
+	journal, err := ReadJournal("testdata", timeutil.NewDate(2021, 10, 15))
+	assert.NoError(t, err)
+
+	actual := journal.GetSessionsAt(timeutil.NewTimestamp(2021, 10, 15, 5, 0, 0))
+	assert.Equal(t, SessionList{}, actual)
+}
+
+func TestGetSessionsAtOnlyLoggedOut(t *testing.T) {
+	journal, err := ReadJournal("testdata", timeutil.NewDate(2021, 11, 30))
+	assert.NoError(t, err)
+
+	// Torsten Test logged out at 14:00 without a login on this day.
+	actual := journal.GetSessionsAt(timeutil.NewTimestamp(2021, 11, 30, 7, 0, 0))
+	assert.Len(t, actual, 2)
+	assert.Equal(t, persons["TT"], actual[0].Person)
+	assert.Equal(t, persons["HM"], actual[1].Person)
+}
+
+func TestSessionListNextEntry(t *testing.T) {
+	expected := [][]string{
+		{"Alte Mälzerei", "Hans", "Müller", "Feldweg", "12", "74722", "Buchen", "2021/10/15 13:40:11 UTC", ""},
+	}
+
+	list := SessionList{
+		{"5faacdf0e6e7b44a", persons["HM"], locs["AM"], timeutil.NewTimestamp(2021, 10, 15, 13, 40, 11), timeutil.InvalidTimestamp},
+	}
+
+	counter := 0
+	for actual := range list.NextEntry() {
+		assert.Equal(t, expected[counter], actual)
+		counter++
+	}
+
+	assert.Equal(t, len(list), counter)
+	assert.Equal(t, len(expected[0]), len(list.Header()))
+}
+
+func TestGetVisitorsForLocation(t *testing.T) {
+	expected := ContactList{
+		NewContact(persons["HM"], locs["DH"], timeutil.NewTimestamp(2021, 10, 15, 9, 0, 0), timeutil.NewTimestamp(2021, 10, 15, 12, 30, 0)),
+		NewContact(persons["GM"], locs["DH"], timeutil.NewTimestamp(2021, 10, 15, 9, 15, 20), timeutil.NewTimestamp(2021, 10, 15, 12, 30, 0)),
+		NewContact(persons["MM"], locs["DH"], timeutil.NewTimestamp(2021, 10, 15, 12, 15, 30), timeutil.NewTimestamp(2021, 10, 15, 12, 30, 0)),
+		NewContact(persons["AM"], locs["DH"], timeutil.NewTimestamp(2021, 10, 15, 12, 17, 20), timeutil.NewTimestamp(2021, 10, 15, 12, 30, 0)),
+	}
+
+	journal, err := ReadJournal("testdata", timeutil.NewDate(2021, 10, 15))
+	assert.NoError(t, err)
+
+	actual := journal.GetVisitorsForLocation(locs["DH"], timeutil.NewTimestamp(2021, 10, 15, 9, 0, 0), timeutil.NewTimestamp(2021, 10, 15, 12, 30, 0))
+	assert.Equal(t, expected, actual)
+	assert.Equal(t, 3*time.Hour+30*time.Minute, actual[0].Duration)
+}
+
+func TestGetVisitorsForLocationTouchingWindow(t *testing.T) {
+	journal, err := ReadJournal("testdata", timeutil.NewDate(2021, 10, 15))
+	assert.NoError(t, err)
+
+	// Lieschen Müller logged in exactly at 13:30:00.
+	actual := journal.GetVisitorsForLocation(locs["DH"], timeutil.NewTimestamp(2021, 10, 15, 13, 0, 0), timeutil.NewTimestamp(2021, 10, 15, 13, 30, 0))
+	for _, c := range actual {
+		assert.NotEqual(t, persons["LM"], c.Person)
+	}
+
+	actual = journal.GetVisitorsForLocation("Night Club", timeutil.NewTimestamp(2021, 10, 15, 0, 0, 0), timeutil.NewTimestamp(2021, 10, 15, 23, 59, 59))
+	assert.Equal(t, ContactList{}, actual)
+}
@@ -0,0 +1,126 @@
+// This source file is part of the attendance list project
+// as a part of the go lecture by H. Neemann.
+// For this reason you have no permission to use, modify or
+// share this code without the agreement of the authors.
+//
+// Matriculation numbers of the authors: 5703004, 5736465
+
+// Package journal provides functionality for writing text based journal files.
+package journal
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"time"
+)
+
+// Sources identifies the mapping and the registry file of a journal directory
+// a Journal was read with, so that results derived from the Journal can tell
+// whether its persons would be read differently now.
+//
+// The mapping file is identified by its modification time. The service only
+// appends new persons to the registry file, which doesn't change how an
+// existing Journal is read, so the registry file is identified by its size and
+// the hash of its content. A registry which is rewritten, e.g. by an erasure,
+// doesn't start with the same content anymore. The zero Sources stand for a
+// directory without both files.
+type Sources struct {
+	MappingModified time.Time `json:"mappingModified"`
+	RegistrySize    int64     `json:"registrySize"`
+	RegistryHash    string    `json:"registryHash"`
+}
+
+// ReadSources returns the Sources of the dir directory.
+//
+// Returns an error if the mapping or the registry file exists but cannot be
+// read.
+func ReadSources(dir string) (Sources, error) {
+	modified, err := mappingModified(dir)
+	if err != nil {
+		return Sources{}, err
+	}
+
+	size, hash, err := hashRegistry(dir, -1)
+	if err != nil {
+		return Sources{}, err
+	}
+
+	return Sources{modified, size, hash}, nil
+}
+
+// Unchanged reports whether Journals are read in the dir directory like with
+// the Sources s, i.e. the mapping file wasn't changed and the registry file
+// still starts with the same content.
+//
+// Returns an error if the mapping or the registry file exists but cannot be
+// read.
+func (s Sources) Unchanged(dir string) (bool, error) {
+	modified, err := mappingModified(dir)
+	if err != nil {
+		return false, err
+	}
+
+	if !modified.Equal(s.MappingModified) {
+		return false, nil
+	}
+
+	size, hash, err := hashRegistry(dir, s.RegistrySize)
+	if err != nil {
+		return false, err
+	}
+
+	return size == s.RegistrySize && hash == s.RegistryHash, nil
+}
+
+// mappingModified returns the modification time of the mapping file in the dir
+// directory or the zero time if it doesn't exist.
+func mappingModified(dir string) (time.Time, error) {
+	info, err := os.Stat(path.Join(dir, MappingFileName))
+	if errors.Is(err, os.ErrNotExist) {
+		return time.Time{}, nil
+	}
+
+	if err != nil {
+		return time.Time{}, fmt.Errorf("cannot read mapping file: %w", err)
+	}
+
+	return info.ModTime(), nil
+}
+
+// hashRegistry returns the number of bytes and the hex encoded SHA-256 hash of
+// the first limit bytes of the registry file in the dir directory, of the hole
+// file if limit is negative. The hash of no bytes is empty, so a file which
+// doesn't exist or is empty has the zero values.
+func hashRegistry(dir string, limit int64) (int64, string, error) {
+	f, err := os.Open(path.Join(dir, RegistryFileName))
+	if errors.Is(err, os.ErrNotExist) {
+		return 0, "", nil
+	}
+
+	if err != nil {
+		return 0, "", fmt.Errorf("cannot read registry file: %w", err)
+	}
+	defer f.Close()
+
+	var r io.Reader = f
+	if limit >= 0 {
+		r = io.LimitReader(f, limit)
+	}
+
+	h := sha256.New()
+	n, err := io.Copy(h, r)
+	if err != nil {
+		return 0, "", fmt.Errorf("cannot read registry file: %w", err)
+	}
+
+	if n == 0 {
+		return 0, "", nil
+	}
+
+	return n, hex.EncodeToString(h.Sum(nil)), nil
+}
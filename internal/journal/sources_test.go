@@ -0,0 +1,74 @@
+// This source file is part of the attendance list project
+// as a part of the go lecture by H. Neemann.
+// For this reason you have no permission to use, modify or
+// share this code without the agreement of the authors.
+//
+// Matriculation numbers of the authors: 5703004, 5736465
+
+// Package journal provides functionality for writing text based journal files.
+package journal
+
+import (
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSourcesUnchanged(t *testing.T) {
+	dir := t.TempDir()
+	mappingPath := path.Join(dir, MappingFileName)
+	registryPath := path.Join(dir, RegistryFileName)
+
+	// Neither the mapping nor the registry file exists.
+	empty, err := ReadSources(dir)
+	assert.NoError(t, err)
+	assert.Equal(t, Sources{}, empty)
+	unchanged, err := empty.Unchanged(dir)
+	assert.NoError(t, err)
+	assert.True(t, unchanged)
+
+	// A new registry only has appended entries.
+	entry := "a1,Hans,Müller,Feldweg,12,74722,Buchen\n"
+	assert.NoError(t, ioutil.WriteFile(registryPath, []byte(entry), 0600))
+	unchanged, err = empty.Unchanged(dir)
+	assert.NoError(t, err)
+	assert.True(t, unchanged)
+
+	sources, err := ReadSources(dir)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(len(entry)), sources.RegistrySize)
+
+	// A changed entry or a deleted registry changes the Sources.
+	assert.NoError(t, ioutil.WriteFile(registryPath, []byte("a1,Hans,Meier,Feldweg,12,74722,Buchen\n"), 0600))
+	unchanged, err = sources.Unchanged(dir)
+	assert.NoError(t, err)
+	assert.False(t, unchanged)
+
+	assert.NoError(t, os.Remove(registryPath))
+	unchanged, err = sources.Unchanged(dir)
+	assert.NoError(t, err)
+	assert.False(t, unchanged)
+
+	// A created, changed or deleted mapping file changes the Sources.
+	assert.NoError(t, ioutil.WriteFile(mappingPath, []byte("[]\n"), 0600))
+	unchanged, err = empty.Unchanged(dir)
+	assert.NoError(t, err)
+	assert.False(t, unchanged)
+
+	sources, err = ReadSources(dir)
+	assert.NoError(t, err)
+	later := sources.MappingModified.Add(time.Minute)
+	assert.NoError(t, os.Chtimes(mappingPath, later, later))
+	unchanged, err = sources.Unchanged(dir)
+	assert.NoError(t, err)
+	assert.False(t, unchanged)
+
+	assert.NoError(t, os.Remove(mappingPath))
+	unchanged, err = empty.Unchanged(dir)
+	assert.NoError(t, err)
+	assert.True(t, unchanged)
+}
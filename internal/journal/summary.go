@@ -0,0 +1,264 @@
+// This source file is part of the attendance list project
+// as a part of the go lecture by H. Neemann.
+// For this reason you have no permission to use, modify or
+// share this code without the agreement of the authors.
+//
+// Matriculation numbers of the authors: 5703004, 5736465
+
+package journal
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/dateiexplorer/attendancelist/internal/timeutil"
+)
+
+// The file extension of summary files.
+const summaryFileExtension = ".summary.json"
+
+// ErrOutdatedSummary is returned by ReadSummary if the journal file was changed
+// after the summary was written.
+var ErrOutdatedSummary = errors.New("summary is outdated")
+
+// SummaryFilePath returns the path of the summary file for the date in the dir
+// directory.
+func SummaryFilePath(dir string, date timeutil.Date) string {
+	return path.Join(dir, date.String()+summaryFileExtension)
+}
+
+// A Summary holds the precomputed statistics of a Journal, so that reports over
+// a long period don't need to parse every journal file.
+//
+// JournalModified is the modification time of the journal file the Summary was
+// created from, the Sources are the mapping and the registry file it was read
+// with. The Persons are only identified by pseudonyms.
+type Summary struct {
+	Date            timeutil.Date `json:"date"`
+	JournalModified time.Time     `json:"journalModified"`
+	Sources
+	Locations []LocationSummary `json:"locations"`
+	Persons   []PersonSummary   `json:"persons"`
+}
+
+// A LocationSummary holds the statistics of an Occupancy without its Timeline
+// and the number of different Persons which visited the Location.
+type LocationSummary struct {
+	Location     Location           `json:"location"`
+	Visits       int                `json:"visits"`
+	Visitors     int                `json:"visitors"`
+	Peak         int                `json:"peak"`
+	PeakTime     timeutil.Timestamp `json:"peakTime"`
+	AverageDwell time.Duration      `json:"averageDwell"`
+	Hourly       [24]int            `json:"hourly"`
+}
+
+// A PersonSummary holds the visits of the Person with the pseudonym ID.
+type PersonSummary struct {
+	ID     string  `json:"id"`
+	Visits []Visit `json:"visits"`
+}
+
+// A Visit is the stay of a Person at a Location on the day of a Summary. Visits
+// which span midnight are cut at the beginning or the end of the day.
+type Visit struct {
+	Location Location           `json:"location"`
+	Start    timeutil.Timestamp `json:"start"`
+	End      timeutil.Timestamp `json:"end"`
+}
+
+// Summarize returns the Summary of the Journal j. The Persons are listed under
+// the pseudonym returned by the pseudonym function, ordered by the pseudonyms.
+// If pseudonym is nil, the Summary contains no Persons.
+func Summarize(j Journal, pseudonym func(p Person) string) Summary {
+	sessions := j.GetSessions()
+	visitors := make(map[Location]map[Person]bool)
+	visits := make(map[string][]Visit)
+	for _, s := range sessions {
+		if visitors[s.Location] == nil {
+			visitors[s.Location] = make(map[Person]bool)
+		}
+
+		visitors[s.Location][s.Person] = true
+		if pseudonym != nil {
+			id := pseudonym(s.Person)
+			visits[id] = append(visits[id], Visit{s.Location, s.Start(j.Date), s.End(j.Date)})
+		}
+	}
+
+	summary := Summary{Date: j.Date, Locations: []LocationSummary{}, Persons: []PersonSummary{}}
+	for _, o := range j.GetOccupancy() {
+		summary.Locations = append(summary.Locations, LocationSummary{
+			Location:     o.Location,
+			Visits:       o.Visits,
+			Visitors:     len(visitors[o.Location]),
+			Peak:         o.Peak,
+			PeakTime:     o.PeakTime,
+			AverageDwell: o.AverageDwell,
+			Hourly:       o.Hourly,
+		})
+	}
+
+	for id, v := range visits {
+		sort.SliceStable(v, func(a, b int) bool {
+			return v[a].Start.Before(v[b].Start.Time)
+		})
+
+		summary.Persons = append(summary.Persons, PersonSummary{id, v})
+	}
+
+	sort.Slice(summary.Persons, func(a, b int) bool {
+		return summary.Persons[a].ID < summary.Persons[b].ID
+	})
+
+	return summary
+}
+
+// Occupancy returns the Occupancy of every Location of the Summary s. The
+// Occupancies have an empty Timeline, because it isn't part of a Summary.
+func (s Summary) Occupancy() OccupancyList {
+	list := make(OccupancyList, 0, len(s.Locations))
+	for _, l := range s.Locations {
+		list = append(list, l.occupancy())
+	}
+
+	return list
+}
+
+// OccupancyForLocation returns the Occupancy of the Location l like
+// GetOccupancyForLocation, but without a Timeline.
+func (s Summary) OccupancyForLocation(l Location) Occupancy {
+	for _, ls := range s.Locations {
+		if ls.Location == l {
+			return ls.occupancy()
+		}
+	}
+
+	return Occupancy{Location: l, Timeline: OccupancyTimeline{}, PeakTime: timeutil.InvalidTimestamp}
+}
+
+// occupancy returns the LocationSummary ls as Occupancy with an empty Timeline.
+func (ls LocationSummary) occupancy() Occupancy {
+	return Occupancy{
+		Location:     ls.Location,
+		Timeline:     OccupancyTimeline{},
+		Peak:         ls.Peak,
+		PeakTime:     ls.PeakTime,
+		Visits:       ls.Visits,
+		AverageDwell: ls.AverageDwell,
+		Hourly:       ls.Hourly,
+	}
+}
+
+// WriteSummary writes the Summary s to its summary file in the dir directory.
+// An existing summary file is replaced.
+//
+// Returns an error if the file cannot be written.
+func WriteSummary(dir string, s Summary) error {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return fmt.Errorf("cannot encode summary: %w", err)
+	}
+
+	// The summary is written to a temporary file first, so that readers never
+	// see a half written summary.
+	filePath := SummaryFilePath(dir, s.Date)
+	if err := ioutil.WriteFile(filePath+".tmp", append(data, '\n'), 0600); err != nil {
+		return fmt.Errorf("cannot write summary: %w", err)
+	}
+
+	if err := os.Rename(filePath+".tmp", filePath); err != nil {
+		return fmt.Errorf("cannot write summary: %w", err)
+	}
+
+	return nil
+}
+
+// ReadSummary reads the summary file for the date in the dir directory.
+//
+// If the summary file doesn't exist, an error wrapping os.ErrNotExist is
+// returned. If the journal file was modified after the summary was created,
+// ErrOutdatedSummary is returned. A summary of a deleted journal file stays
+// valid. The summary is outdated as well if its Sources changed, because the
+// persons of the journal file would be read differently.
+func ReadSummary(dir string, date timeutil.Date) (Summary, error) {
+	data, err := ioutil.ReadFile(SummaryFilePath(dir, date))
+	if err != nil {
+		return Summary{}, fmt.Errorf("cannot read summary: %w", err)
+	}
+
+	var s Summary
+	if err := json.Unmarshal(data, &s); err != nil {
+		return Summary{}, fmt.Errorf("cannot parse summary: %w", err)
+	}
+
+	info, err := os.Stat(FilePath(dir, date))
+	if err == nil && !info.ModTime().Equal(s.JournalModified) {
+		return Summary{}, ErrOutdatedSummary
+	}
+
+	unchanged, err := s.Sources.Unchanged(dir)
+	if err != nil {
+		return Summary{}, fmt.Errorf("cannot read summary: %w", err)
+	}
+
+	if !unchanged {
+		return Summary{}, ErrOutdatedSummary
+	}
+
+	return s, nil
+}
+
+// A SummaryList is a collection of Summaries.
+type SummaryList []Summary
+
+// NextEntry returns a read-only channel that loops through the hole
+// SummaryList and returns the date and the statistics of every
+// LocationSummary as a string slice.
+//
+// Used to convert a SummaryList to any file format.
+func (l SummaryList) NextEntry() <-chan []string {
+	entries := make(chan []string)
+	go func() {
+		for _, s := range l {
+			for _, ls := range s.Locations {
+				peakTime := ""
+				if ls.PeakTime != timeutil.InvalidTimestamp {
+					peakTime = ls.PeakTime.Clock()
+				}
+
+				entry := []string{s.Date.String(), string(ls.Location), strconv.Itoa(ls.Visits), strconv.Itoa(ls.Visitors),
+					strconv.Itoa(ls.Peak), peakTime, ls.AverageDwell.String()}
+				for _, n := range ls.Hourly {
+					entry = append(entry, strconv.Itoa(n))
+				}
+
+				entries <- entry
+			}
+		}
+
+		close(entries)
+	}()
+
+	return entries
+}
+
+// Header returns a string slice which describes the data given by the NextEntry
+// function. The columns "00" to "23" hold the visitors per hour.
+//
+// Used to convert a SummaryList to any file format.
+func (l SummaryList) Header() []string {
+	header := []string{"Date", "Location", "Visits", "Visitors", "Peak", "PeakTime", "AverageDwell"}
+	for h := 0; h < 24; h++ {
+		header = append(header, fmt.Sprintf("%02d", h))
+	}
+
+	return header
+}
@@ -0,0 +1,171 @@
+// This source file is part of the attendance list project
+// as a part of the go lecture by H. Neemann.
+// For this reason you have no permission to use, modify or
+// share this code without the agreement of the authors.
+//
+// Matriculation numbers of the authors: 5703004, 5736465
+
+// Package journal provides functionality for writing text based journal files.
+package journal
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+	"time"
+
+	"github.com/dateiexplorer/attendancelist/internal/timeutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSummarize(t *testing.T) {
+	j := Journal{timeutil.NewDate(2021, 10, 15), []JournalEntry{
+		{timeutil.NewTimestamp(2021, 10, 15, 8, 0, 0), "aaaa", Login, locs["DH"], persons["MM"]},
+		{timeutil.NewTimestamp(2021, 10, 15, 9, 0, 0), "aaaa", Logout, locs["DH"], persons["MM"]},
+		{timeutil.NewTimestamp(2021, 10, 15, 9, 30, 0), "bbbb", Login, locs["DH"], persons["MM"]},
+		{timeutil.NewTimestamp(2021, 10, 15, 9, 45, 0), "cccc", Login, locs["AM"], persons["GM"]},
+		{timeutil.NewTimestamp(2021, 10, 15, 10, 30, 0), "bbbb", Logout, locs["DH"], persons["MM"]},
+	}}
+
+	s := Summarize(j, func(p Person) string {
+		return p.LastName
+	})
+
+	assert.Equal(t, j.Date, s.Date)
+	assert.Equal(t, []LocationSummary{
+		{locs["AM"], 1, 1, 1, timeutil.NewTimestamp(2021, 10, 15, 9, 45, 0), 14*time.Hour + 15*time.Minute - time.Second,
+			[24]int{9: 1, 10: 1, 11: 1, 12: 1, 13: 1, 14: 1, 15: 1, 16: 1, 17: 1, 18: 1, 19: 1, 20: 1, 21: 1, 22: 1, 23: 1}},
+		{locs["DH"], 2, 1, 1, timeutil.NewTimestamp(2021, 10, 15, 8, 0, 0), time.Hour, [24]int{8: 1, 9: 1, 10: 1}},
+	}, s.Locations)
+
+	// The visit of GM lasts until the end of the day, because there is no
+	// logout.
+	assert.Equal(t, []PersonSummary{
+		{"Musterfrau", []Visit{{locs["AM"], timeutil.NewTimestamp(2021, 10, 15, 9, 45, 0), timeutil.NewTimestamp(2021, 10, 15, 23, 59, 59)}}},
+		{"Mustermann", []Visit{
+			{locs["DH"], timeutil.NewTimestamp(2021, 10, 15, 8, 0, 0), timeutil.NewTimestamp(2021, 10, 15, 9, 0, 0)},
+			{locs["DH"], timeutil.NewTimestamp(2021, 10, 15, 9, 30, 0), timeutil.NewTimestamp(2021, 10, 15, 10, 30, 0)},
+		}},
+	}, s.Persons)
+
+	assert.Empty(t, Summarize(j, nil).Persons)
+}
+
+func TestSummaryOccupancy(t *testing.T) {
+	j, err := ReadJournal("testdata", timeutil.NewDate(2021, 10, 15))
+	assert.NoError(t, err)
+
+	// The Occupancies of a Summary equal the Occupancies of the Journal
+	// without their Timelines.
+	expected := j.GetOccupancy()
+	for i := range expected {
+		expected[i].Timeline = OccupancyTimeline{}
+	}
+
+	s := Summarize(j, nil)
+	assert.Equal(t, expected, s.Occupancy())
+
+	for _, o := range expected {
+		assert.Equal(t, o, s.OccupancyForLocation(o.Location))
+	}
+
+	notExisting := j.GetOccupancyForLocation("Night Club")
+	assert.Equal(t, notExisting, s.OccupancyForLocation("Night Club"))
+}
+
+func TestWriteAndReadSummary(t *testing.T) {
+	dir := t.TempDir()
+	date := timeutil.NewDate(2021, 10, 15)
+
+	_, err := ReadSummary(dir, date)
+	assert.True(t, errors.Is(err, os.ErrNotExist))
+
+	data, err := ioutil.ReadFile(FilePath("testdata", date))
+	assert.NoError(t, err)
+	assert.NoError(t, ioutil.WriteFile(FilePath(dir, date), data, 0600))
+
+	j, err := ReadJournal(dir, date)
+	assert.NoError(t, err)
+
+	info, err := os.Stat(FilePath(dir, date))
+	assert.NoError(t, err)
+
+	s := Summarize(j, func(p Person) string {
+		return p.FirstName
+	})
+	s.JournalModified = info.ModTime()
+	assert.NoError(t, WriteSummary(dir, s))
+
+	actual, err := ReadSummary(dir, date)
+	assert.NoError(t, err)
+	assert.True(t, s.JournalModified.Equal(actual.JournalModified))
+	actual.JournalModified = s.JournalModified
+	assert.Equal(t, s, actual)
+
+	// A changed journal file outdates the summary.
+	later := info.ModTime().Add(time.Minute)
+	assert.NoError(t, os.Chtimes(FilePath(dir, date), later, later))
+	_, err = ReadSummary(dir, date)
+	assert.Equal(t, ErrOutdatedSummary, err)
+
+	// The summary of a deleted journal file is still valid.
+	assert.NoError(t, os.Remove(FilePath(dir, date)))
+	_, err = ReadSummary(dir, date)
+	assert.NoError(t, err)
+
+	dates, err := ListJournals(dir)
+	assert.NoError(t, err)
+	assert.Empty(t, dates)
+}
+
+func TestReadSummaryOutdatedSources(t *testing.T) {
+	dir := t.TempDir()
+	date := timeutil.NewDate(2021, 10, 15)
+	registryPath := path.Join(dir, RegistryFileName)
+	assert.NoError(t, ioutil.WriteFile(registryPath, []byte("a1,Hans,Müller,Feldweg,12,74722,Buchen\n"), 0600))
+
+	sources, err := ReadSources(dir)
+	assert.NoError(t, err)
+	assert.NoError(t, WriteSummary(dir, Summary{Date: date, Sources: sources}))
+	_, err = ReadSummary(dir, date)
+	assert.NoError(t, err)
+
+	// The service appends a new visitor to the registry, the summary is still
+	// valid.
+	f, err := os.OpenFile(registryPath, os.O_APPEND|os.O_WRONLY, 0600)
+	assert.NoError(t, err)
+	_, err = f.WriteString("b2,Anne,Meier,Hauptstraße,18,74821,Mosbach\n")
+	assert.NoError(t, err)
+	assert.NoError(t, f.Close())
+	_, err = ReadSummary(dir, date)
+	assert.NoError(t, err)
+
+	// A rewritten registry outdates the summary.
+	assert.NoError(t, Registry{"b2": NewPerson("Anne", "Meier", "Hauptstraße", "18", "74821", "Mosbach")}.WriteToFile(registryPath))
+	_, err = ReadSummary(dir, date)
+	assert.Equal(t, ErrOutdatedSummary, err)
+
+	// A created mapping file outdates the summary.
+	sources, err = ReadSources(dir)
+	assert.NoError(t, err)
+	assert.NoError(t, WriteSummary(dir, Summary{Date: date, Sources: sources}))
+	assert.NoError(t, ioutil.WriteFile(path.Join(dir, MappingFileName), []byte("[]\n"), 0600))
+	_, err = ReadSummary(dir, date)
+	assert.Equal(t, ErrOutdatedSummary, err)
+}
+
+func TestSummaryListNextEntry(t *testing.T) {
+	j, err := ReadJournal("testdata", timeutil.NewDate(2021, 10, 15))
+	assert.NoError(t, err)
+
+	l := SummaryList{Summarize(j, nil)}
+	expected := []string{"2021-10-15", "Alte Mälzerei", "2", "2", "2", "17:32:45", "6h1m8s",
+		"0", "0", "0", "0", "0", "0", "0", "0", "0", "0", "0", "0", "0",
+		"1", "1", "1", "1", "2", "2", "2", "1", "1", "1", "1"}
+
+	entry := <-l.NextEntry()
+	assert.Equal(t, expected, entry)
+	assert.Equal(t, len(l.Header()), len(entry))
+}
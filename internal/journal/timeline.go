@@ -0,0 +1,117 @@
+// This source file is part of the attendance list project
+// as a part of the go lecture by H. Neemann.
+// For this reason you have no permission to use, modify or
+// share this code without the agreement of the authors.
+//
+// Matriculation numbers of the authors: 5703004, 5736465
+
+// Package journal provides functionality for writing text based journal files.
+package journal
+
+import (
+	"strings"
+	"time"
+
+	"github.com/dateiexplorer/attendancelist/internal/timeutil"
+)
+
+// A TimelineEntry represents a visit of a Person in a Timeline.
+//
+// Start and End are the bounds of the Session on its Date, Duration is the time
+// between them. Gap is the time between the End of the previous visit on the
+// same Date and the Start of this visit, it is negative if both visits overlap
+// and zero for the first visit of a day.
+type TimelineEntry struct {
+	Session
+	Date       timeutil.Date
+	Start, End timeutil.Timestamp
+	Duration   time.Duration
+	Gap        time.Duration
+}
+
+// Flags returns the anomalies of the TimelineEntry, which are "no login" if
+// the Session has no Login, "no logout" if the Session never ended, "gap" if
+// there is time between this and the previous visit and "overlap" if the visit
+// begins before the previous visit ended.
+func (e TimelineEntry) Flags() []string {
+	flags := make([]string, 0)
+	if e.Login == timeutil.InvalidTimestamp {
+		flags = append(flags, "no login")
+	}
+	if e.Logout == timeutil.InvalidTimestamp {
+		flags = append(flags, "no logout")
+	}
+	if e.Gap > 0 {
+		flags = append(flags, "gap")
+	}
+	if e.Gap < 0 {
+		flags = append(flags, "overlap")
+	}
+
+	return flags
+}
+
+// A Timeline is a chronological list of the visits of a Person.
+type Timeline []TimelineEntry
+
+// GetTimelineForPerson returns the visits of the Person p in all journals in
+// chronological order. The journals must be sorted by their Date.
+//
+// If the Person doesn't exist an empty Timeline will be returned.
+func GetTimelineForPerson(journals []Journal, p *Person) Timeline {
+	timeline := Timeline{}
+	for _, j := range journals {
+		first := len(timeline)
+		for _, s := range j.GetSessions() {
+			if s.Person != *p {
+				continue
+			}
+
+			e := TimelineEntry{Session: s, Date: j.Date, Start: s.Start(j.Date), End: s.End(j.Date)}
+			e.Duration = e.End.Sub(e.Start.Time)
+			if len(timeline) > first {
+				e.Gap = e.Start.Sub(timeline[len(timeline)-1].End.Time)
+			}
+
+			timeline = append(timeline, e)
+		}
+	}
+
+	return timeline
+}
+
+// NextEntry returns a read-only channel that loops through the hole Timeline
+// and returns data of the TimelineEntry as a string slice.
+// An unknown Login or Logout is represented by an empty string, multiple flags
+// are separated by a semicolon.
+//
+// Used to convert a Timeline to any file format.
+func (t Timeline) NextEntry() <-chan []string {
+	entries := make(chan []string)
+	go func() {
+		for _, e := range t {
+			login := ""
+			if e.Login != timeutil.InvalidTimestamp {
+				login = e.Login.Clock()
+			}
+			logout := ""
+			if e.Logout != timeutil.InvalidTimestamp {
+				logout = e.Logout.Clock()
+			}
+			entries <- []string{e.Date.String(), string(e.Location), login, logout, e.Duration.String(), e.Gap.String(),
+				strings.Join(e.Flags(), ";")}
+		}
+
+		close(entries)
+	}()
+
+	return entries
+}
+
+// Header returns a string slice which describes the data given by the NextEntry
+// function.
+//
+// Used to convert a Timeline to any file format.
+func (t Timeline) Header() []string {
+	return []string{"Date", "Location", "Login", "Logout", "Duration", "Gap", "Flags"}
+}
@@ -0,0 +1,57 @@
+// This source file is part of the attendance list project
+// as a part of the go lecture by H. Neemann.
+// For this reason you have no permission to use, modify or
+// share this code without the agreement of the authors.
+//
+// Matriculation numbers of the authors: 5703004, 5736465
+
+// Package journal provides functionality for writing text based journal files.
+package journal
+
+import (
+	"testing"
+	"time"
+
+	"github.com/dateiexplorer/attendancelist/internal/timeutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetTimelineForPerson(t *testing.T) {
+	expected := [][]string{
+		{"2021-10-15", "DHBW Mosbach", "06:20:13", "13:40:10", "7h19m57s", "0s", ""},
+		{"2021-10-15", "Alte Mälzerei", "13:40:11", "", "10h19m48s", "1s", "no logout;gap"},
+		{"2021-11-30", "DHBW Mosbach", "06:00:00", "08:00:00", "2h0m0s", "0s", ""},
+		{"2021-11-30", "Alte Mälzerei", "08:30:00", "", "15h29m59s", "30m0s", "no logout;gap"},
+	}
+
+	journals, err := ReadJournals("testdata", timeutil.NewDate(2021, 10, 15), timeutil.NewDate(2021, 11, 30))
+	assert.NoError(t, err)
+
+	p := persons["HM"]
+	timeline := GetTimelineForPerson(journals, &p)
+
+	counter := 0
+	for actual := range timeline.NextEntry() {
+		assert.Equal(t, expected[counter], actual)
+		counter++
+	}
+
+	assert.Equal(t, len(expected), counter)
+	assert.Equal(t, len(expected[0]), len(timeline.Header()))
+}
+
+func TestGetTimelineForPersonNotExistingPerson(t *testing.T) {
+	journals, err := ReadJournals("testdata", timeutil.NewDate(2021, 10, 15), timeutil.NewDate(2021, 11, 30))
+	assert.NoError(t, err)
+
+	p := Person{"Susi", "Sorglos", Address{"Musterstraße", "20", "72327", "Musterstadt"}}
+	assert.Equal(t, Timeline{}, GetTimelineForPerson(journals, &p))
+}
+
+func TestTimelineEntryFlags(t *testing.T) {
+	e := TimelineEntry{Session: Session{Login: timeutil.InvalidTimestamp, Logout: timeutil.NewTimestamp(2021, 11, 30, 14, 0, 0)}}
+	assert.Equal(t, []string{"no login"}, e.Flags())
+
+	e.Gap = -time.Hour
+	assert.Equal(t, []string{"no login", "overlap"}, e.Flags())
+}
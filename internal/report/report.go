@@ -0,0 +1,287 @@
+// This source file is part of the attendance list project
+// as a part of the go lecture by H. Neemann.
+// For this reason you have no permission to use, modify or
+// share this code without the agreement of the authors.
+//
+// Matriculation numbers of the authors: 5703004, 5736465
+
+// Package report provides functionality for creating and verifying signed
+// report bundles which are sent to the health authority.
+//
+// A bundle is a zip archive which holds the report files, a manifest with the
+// checksums of all files and a detached signature of the manifest.
+package report
+
+import (
+	"archive/zip"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"path"
+	"sort"
+	"time"
+)
+
+// The names of the manifest and its signature in a bundle.
+const (
+	ManifestName  = "manifest.json"
+	SignatureName = "manifest.sig"
+)
+
+// A Checksum holds the SHA-256 checksum of a file as hexadecimal string.
+type Checksum struct {
+	Name   string `json:"name"`
+	SHA256 string `json:"sha256"`
+}
+
+// NewChecksum returns the Checksum of data with the given name.
+func NewChecksum(name string, data []byte) Checksum {
+	sum := sha256.Sum256(data)
+	return Checksum{name, hex.EncodeToString(sum[:])}
+}
+
+// ChecksumFile returns the Checksum of the file at filePath. The name of the
+// Checksum is the base name of the file.
+//
+// Returns an error if the file cannot be read.
+func ChecksumFile(filePath string) (Checksum, error) {
+	data, err := ioutil.ReadFile(filePath)
+	if err != nil {
+		return Checksum{}, fmt.Errorf("cannot read file: %w", err)
+	}
+
+	return NewChecksum(path.Base(filePath), data), nil
+}
+
+// A Manifest describes a bundle. Query holds the parameters the report was
+// created with, Journals the checksums of the journal files the report is
+// based on and Files the checksums of all other files in the bundle.
+type Manifest struct {
+	IndexCase   string            `json:"indexCase"`
+	Query       map[string]string `json:"query"`
+	GeneratedAt time.Time         `json:"generatedAt"`
+	Software    string            `json:"software"`
+	Journals    []Checksum        `json:"journals"`
+	Files       []Checksum        `json:"files"`
+}
+
+// A File is a file of a bundle.
+type File struct {
+	Name string
+	Data []byte
+}
+
+// Write writes a bundle with the files to w. The checksums of the files are
+// added to the Manifest m, which is then signed with the signer.
+//
+// Returns an error if a file has a reserved or duplicate name, the Manifest
+// cannot be signed or the bundle cannot be written.
+func Write(w io.Writer, m Manifest, files []File, signer crypto.Signer) error {
+	m.Files = make([]Checksum, 0, len(files))
+	seen := map[string]bool{ManifestName: true, SignatureName: true}
+	for _, f := range files {
+		if seen[f.Name] {
+			return fmt.Errorf("cannot write report: duplicate or reserved file name \"%v\"", f.Name)
+		}
+
+		seen[f.Name] = true
+		m.Files = append(m.Files, NewChecksum(f.Name, f.Data))
+	}
+
+	manifest, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return fmt.Errorf("cannot marshal manifest: %w", err)
+	}
+
+	signature, err := sign(signer, manifest)
+	if err != nil {
+		return fmt.Errorf("cannot sign manifest: %w", err)
+	}
+
+	files = append(files, File{ManifestName, manifest}, File{SignatureName, signature})
+
+	writer := zip.NewWriter(w)
+	for _, f := range files {
+		fw, err := writer.CreateHeader(&zip.FileHeader{Name: f.Name, Method: zip.Deflate, Modified: m.GeneratedAt})
+		if err != nil {
+			return fmt.Errorf("cannot write report: %w", err)
+		}
+
+		if _, err := fw.Write(f.Data); err != nil {
+			return fmt.Errorf("cannot write report: %w", err)
+		}
+	}
+
+	if err := writer.Close(); err != nil {
+		return fmt.Errorf("cannot write report: %w", err)
+	}
+
+	return nil
+}
+
+// Verify checks the integrity of the bundle in r with the size. The signature
+// of the manifest must be valid for the public key pub and every file of the
+// bundle must be listed in the manifest with the correct checksum and no file
+// name may be used twice.
+//
+// Returns the Manifest of the bundle or an error if the bundle is invalid.
+func Verify(r io.ReaderAt, size int64, pub crypto.PublicKey) (Manifest, error) {
+	reader, err := zip.NewReader(r, size)
+	if err != nil {
+		return Manifest{}, fmt.Errorf("cannot read report: %w", err)
+	}
+
+	files := make(map[string][]byte)
+	for _, zf := range reader.File {
+		// A second file with the same name could be shown instead of the
+		// verified one.
+		if _, ok := files[zf.Name]; ok {
+			return Manifest{}, fmt.Errorf("report contains file %v more than once", zf.Name)
+		}
+
+		rc, err := zf.Open()
+		if err != nil {
+			return Manifest{}, fmt.Errorf("cannot read %v: %w", zf.Name, err)
+		}
+
+		data, err := ioutil.ReadAll(rc)
+		rc.Close()
+		if err != nil {
+			return Manifest{}, fmt.Errorf("cannot read %v: %w", zf.Name, err)
+		}
+
+		files[zf.Name] = data
+	}
+
+	manifest, ok := files[ManifestName]
+	if !ok {
+		return Manifest{}, fmt.Errorf("report contains no %v", ManifestName)
+	}
+
+	signature, ok := files[SignatureName]
+	if !ok {
+		return Manifest{}, fmt.Errorf("report contains no %v", SignatureName)
+	}
+
+	if err := verify(pub, manifest, signature); err != nil {
+		return Manifest{}, err
+	}
+
+	var m Manifest
+	if err := json.Unmarshal(manifest, &m); err != nil {
+		return Manifest{}, fmt.Errorf("cannot parse manifest: %w", err)
+	}
+
+	listed := map[string]bool{ManifestName: true, SignatureName: true}
+	for _, c := range m.Files {
+		listed[c.Name] = true
+		data, ok := files[c.Name]
+		if !ok {
+			return m, fmt.Errorf("file %v is missing", c.Name)
+		}
+
+		if NewChecksum(c.Name, data) != c {
+			return m, fmt.Errorf("checksum of file %v does not match", c.Name)
+		}
+	}
+
+	unlisted := make([]string, 0)
+	for name := range files {
+		if !listed[name] {
+			unlisted = append(unlisted, name)
+		}
+	}
+
+	if len(unlisted) > 0 {
+		sort.Strings(unlisted)
+		return m, fmt.Errorf("files not listed in the manifest: %v", unlisted)
+	}
+
+	return m, nil
+}
+
+// LoadSigner loads the private key of a PEM encoded X.509 key pair, e.g. the
+// certificate and key of the web service.
+//
+// Returns an error if the files cannot be read or the key cannot sign.
+func LoadSigner(certFile, keyFile string) (crypto.Signer, error) {
+	cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		return nil, fmt.Errorf("cannot load key pair: %w", err)
+	}
+
+	signer, ok := cert.PrivateKey.(crypto.Signer)
+	if !ok {
+		return nil, fmt.Errorf("cannot sign with a key of type %T", cert.PrivateKey)
+	}
+
+	return signer, nil
+}
+
+// LoadPublicKey loads the public key of a PEM encoded X.509 certificate.
+//
+// Returns an error if the file cannot be read or parsed.
+func LoadPublicKey(certFile string) (crypto.PublicKey, error) {
+	data, err := ioutil.ReadFile(certFile)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read certificate: %w", err)
+	}
+
+	block, _ := pem.Decode(data)
+	if block == nil || block.Type != "CERTIFICATE" {
+		return nil, fmt.Errorf("cannot parse certificate: no PEM encoded certificate found")
+	}
+
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("cannot parse certificate: %w", err)
+	}
+
+	return cert.PublicKey, nil
+}
+
+// sign signs the SHA-256 digest of data with the signer. Ed25519 keys sign the
+// data itself.
+func sign(signer crypto.Signer, data []byte) ([]byte, error) {
+	if _, ok := signer.Public().(ed25519.PublicKey); ok {
+		return signer.Sign(rand.Reader, data, crypto.Hash(0))
+	}
+
+	digest := sha256.Sum256(data)
+	return signer.Sign(rand.Reader, digest[:], crypto.SHA256)
+}
+
+// verify checks the signature of data made by sign with the public key pub.
+func verify(pub crypto.PublicKey, data, signature []byte) error {
+	digest := sha256.Sum256(data)
+
+	valid := false
+	switch key := pub.(type) {
+	case *rsa.PublicKey:
+		valid = rsa.VerifyPKCS1v15(key, crypto.SHA256, digest[:], signature) == nil
+	case *ecdsa.PublicKey:
+		valid = ecdsa.VerifyASN1(key, digest[:], signature)
+	case ed25519.PublicKey:
+		valid = ed25519.Verify(key, data, signature)
+	default:
+		return fmt.Errorf("cannot verify with a key of type %T", pub)
+	}
+
+	if !valid {
+		return errors.New("signature of the manifest is invalid")
+	}
+
+	return nil
+}
@@ -0,0 +1,213 @@
+// This source file is part of the attendance list project
+// as a part of the go lecture by H. Neemann.
+// For this reason you have no permission to use, modify or
+// share this code without the agreement of the authors.
+//
+// Matriculation numbers of the authors: 5703004, 5736465
+
+// Package report provides functionality for creating and verifying signed
+// report bundles which are sent to the health authority.
+package report
+
+import (
+	"archive/zip"
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var manifest = Manifest{
+	IndexCase:   "Hans,Müller,Feldweg,12,74722,Buchen",
+	Query:       map[string]string{"person": "Hans", "dates": "2021/10/15"},
+	GeneratedAt: time.Date(2021, 12, 1, 8, 0, 0, 0, time.UTC),
+	Software:    "analyzer 1.0.0",
+	Journals:    []Checksum{NewChecksum("2021-10-15.journal", []byte("journal"))},
+}
+
+var files = []File{
+	{"contacts.csv", []byte("FirstName,LastName\nGisela,Musterfrau\n")},
+	{"attendances/2021-10-15_DHBW Mosbach.csv", []byte("FirstName,LastName\nHans,Müller\n")},
+}
+
+func TestNewChecksum(t *testing.T) {
+	expected := Checksum{"empty", "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"}
+	assert.Equal(t, expected, NewChecksum("empty", nil))
+}
+
+func TestChecksumFile(t *testing.T) {
+	c, err := ChecksumFile("testdata/cert.pem")
+	assert.NoError(t, err)
+	assert.Equal(t, "cert.pem", c.Name)
+	assert.Equal(t, 64, len(c.SHA256))
+
+	_, err = ChecksumFile("testdata/missing.pem")
+	assert.Error(t, err)
+}
+
+func TestWriteAndVerify(t *testing.T) {
+	signer, err := LoadSigner("testdata/cert.pem", "testdata/key.pem")
+	assert.NoError(t, err)
+
+	pub, err := LoadPublicKey("testdata/cert.pem")
+	assert.NoError(t, err)
+
+	buf := new(bytes.Buffer)
+	assert.NoError(t, Write(buf, manifest, files, signer))
+
+	actual, err := Verify(bytes.NewReader(buf.Bytes()), int64(buf.Len()), pub)
+	assert.NoError(t, err)
+
+	expected := manifest
+	expected.Files = []Checksum{NewChecksum(files[0].Name, files[0].Data), NewChecksum(files[1].Name, files[1].Data)}
+	assert.Equal(t, expected, actual)
+}
+
+func TestWriteAndVerifyOtherKeys(t *testing.T) {
+	edPub, edPriv, err := ed25519.GenerateKey(rand.Reader)
+	assert.NoError(t, err)
+
+	ecPriv, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+
+	buf := new(bytes.Buffer)
+	assert.NoError(t, Write(buf, manifest, files, edPriv))
+	_, err = Verify(bytes.NewReader(buf.Bytes()), int64(buf.Len()), edPub)
+	assert.NoError(t, err)
+
+	buf.Reset()
+	assert.NoError(t, Write(buf, manifest, files, ecPriv))
+	_, err = Verify(bytes.NewReader(buf.Bytes()), int64(buf.Len()), ecPriv.Public())
+	assert.NoError(t, err)
+
+	// Wrong public key
+	_, err = Verify(bytes.NewReader(buf.Bytes()), int64(buf.Len()), edPub)
+	assert.Error(t, err)
+}
+
+func TestWriteDuplicateName(t *testing.T) {
+	_, priv, _ := ed25519.GenerateKey(rand.Reader)
+
+	err := Write(new(bytes.Buffer), manifest, []File{{ManifestName, nil}}, priv)
+	assert.Error(t, err)
+
+	err = Write(new(bytes.Buffer), manifest, []File{files[0], files[0]}, priv)
+	assert.Error(t, err)
+}
+
+// rewrite returns the bundle with the files replaced by the given ones. A file
+// with nil data is removed.
+func rewrite(t *testing.T, bundle []byte, replace map[string][]byte) []byte {
+	reader, err := zip.NewReader(bytes.NewReader(bundle), int64(len(bundle)))
+	assert.NoError(t, err)
+
+	buf := new(bytes.Buffer)
+	writer := zip.NewWriter(buf)
+	for _, zf := range reader.File {
+		data, ok := replace[zf.Name]
+		if !ok {
+			rc, err := zf.Open()
+			assert.NoError(t, err)
+			b := new(bytes.Buffer)
+			_, err = b.ReadFrom(rc)
+			assert.NoError(t, err)
+			rc.Close()
+			data = b.Bytes()
+		}
+		delete(replace, zf.Name)
+
+		if data == nil {
+			continue
+		}
+
+		fw, err := writer.Create(zf.Name)
+		assert.NoError(t, err)
+		fw.Write(data)
+	}
+
+	for name, data := range replace {
+		fw, err := writer.Create(name)
+		assert.NoError(t, err)
+		fw.Write(data)
+	}
+
+	assert.NoError(t, writer.Close())
+	return buf.Bytes()
+}
+
+func TestVerifyTampered(t *testing.T) {
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	assert.NoError(t, err)
+
+	buf := new(bytes.Buffer)
+	assert.NoError(t, Write(buf, manifest, files, priv))
+	bundle := buf.Bytes()
+
+	tests := []struct {
+		name    string
+		replace map[string][]byte
+	}{
+		{"modified file", map[string][]byte{"contacts.csv": []byte("FirstName,LastName\n")}},
+		{"missing file", map[string][]byte{"contacts.csv": nil}},
+		{"additional file", map[string][]byte{"notes.txt": []byte("note")}},
+		{"modified manifest", map[string][]byte{ManifestName: []byte("{}")}},
+		{"missing manifest", map[string][]byte{ManifestName: nil}},
+		{"missing signature", map[string][]byte{SignatureName: nil}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tampered := rewrite(t, bundle, tt.replace)
+			_, err := Verify(bytes.NewReader(tampered), int64(len(tampered)), pub)
+			assert.Error(t, err)
+		})
+	}
+
+	_, err = Verify(bytes.NewReader([]byte("no zip")), 6, pub)
+	assert.Error(t, err)
+}
+
+func TestVerifyDuplicateName(t *testing.T) {
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	assert.NoError(t, err)
+
+	buf := new(bytes.Buffer)
+	assert.NoError(t, Write(buf, manifest, files, priv))
+	bundle := buf.Bytes()
+	_, err = Verify(bytes.NewReader(bundle), int64(len(bundle)), pub)
+	assert.NoError(t, err)
+
+	// The original files are kept and a second contacts.csv is appended.
+	reader, err := zip.NewReader(bytes.NewReader(bundle), int64(len(bundle)))
+	assert.NoError(t, err)
+
+	tampered := new(bytes.Buffer)
+	writer := zip.NewWriter(tampered)
+	for _, zf := range reader.File {
+		assert.NoError(t, writer.Copy(zf))
+	}
+
+	fw, err := writer.Create("contacts.csv")
+	assert.NoError(t, err)
+	fw.Write([]byte("FirstName,LastName\n"))
+	assert.NoError(t, writer.Close())
+
+	_, err = Verify(bytes.NewReader(tampered.Bytes()), int64(tampered.Len()), pub)
+	assert.Error(t, err)
+}
+
+func TestLoadKeysFailed(t *testing.T) {
+	_, err := LoadSigner("testdata/cert.pem", "testdata/missing.pem")
+	assert.Error(t, err)
+
+	_, err = LoadPublicKey("testdata/missing.pem")
+	assert.Error(t, err)
+
+	_, err = LoadPublicKey("testdata/key.pem")
+	assert.Error(t, err)
+}
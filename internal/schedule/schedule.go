@@ -0,0 +1,316 @@
+// This source file is part of the attendance list project
+// as a part of the go lecture by H. Neemann.
+// For this reason you have no permission to use, modify or
+// share this code without the agreement of the authors.
+//
+// Matriculation numbers of the authors: 5703004, 5736465
+
+// Package schedule provides functionality for tracking the attendance of
+// lectures against a lecture schedule.
+package schedule
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dateiexplorer/attendancelist/internal/convert"
+	"github.com/dateiexplorer/attendancelist/internal/journal"
+	"github.com/dateiexplorer/attendancelist/internal/timeutil"
+)
+
+// A Lecture represents one appointment of a Course at a Location.
+type Lecture struct {
+	Course   string
+	Location journal.Location
+	Start    timeutil.Timestamp
+	End      timeutil.Timestamp
+}
+
+// A Schedule is a collection of Lectures sorted by their Start.
+type Schedule []Lecture
+
+// ReadSchedule reads a Schedule from a file in the filesystem. Files with the
+// extension ".ics" are read as iCalendar file, all other files as CSV file.
+//
+// Returns an error if the file cannot be opened or parsed.
+func ReadSchedule(filePath string) (Schedule, error) {
+	f, err := os.Open(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("cannot open schedule file: %w", err)
+	}
+	defer f.Close()
+
+	if strings.EqualFold(path.Ext(filePath), ".ics") {
+		return ReadScheduleFromICS(f)
+	}
+
+	return ReadScheduleFromCSV(f)
+}
+
+// ReadScheduleFromCSV reads a Schedule from CSV data with the columns
+//
+// course,location,start,end
+//
+// where start and end are formatted as "yyyy/MM/dd hh:mm[:ss]" in UTC.
+// A first line which starts with "course" is treated as header and skipped.
+//
+// Returns an error if the data cannot be parsed.
+func ReadScheduleFromCSV(r io.Reader) (Schedule, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = 4
+	reader.TrimLeadingSpace = true
+
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("cannot parse schedule: %w", err)
+	}
+
+	s := Schedule{}
+	for i, record := range records {
+		if i == 0 && strings.EqualFold(record[0], "course") {
+			continue
+		}
+
+		start, err := timeutil.ParseDateTime(record[2])
+		if err != nil {
+			return nil, fmt.Errorf("cannot parse start of lecture on line %v: %w", i+1, err)
+		}
+
+		end, err := timeutil.ParseDateTime(record[3])
+		if err != nil {
+			return nil, fmt.Errorf("cannot parse end of lecture on line %v: %w", i+1, err)
+		}
+
+		s = append(s, Lecture{record[0], journal.Location(record[1]), start, end})
+	}
+
+	return s.sorted(), nil
+}
+
+// ReadScheduleFromICS reads a Schedule from an iCalendar file. The summary of
+// an event is the name of the Course.
+//
+// Returns an error if the data cannot be parsed.
+func ReadScheduleFromICS(r io.Reader) (Schedule, error) {
+	events, err := convert.FromICS(r)
+	if err != nil {
+		return nil, fmt.Errorf("cannot parse schedule: %w", err)
+	}
+
+	s := Schedule{}
+	for _, e := range events {
+		s = append(s, Lecture{e.Summary, journal.Location(e.Location), timeutil.Timestamp{Time: e.Start}, timeutil.Timestamp{Time: e.End}})
+	}
+
+	return s.sorted(), nil
+}
+
+// sorted sorts the Schedule by the Start of the Lectures and returns it.
+func (s Schedule) sorted() Schedule {
+	sort.SliceStable(s, func(i, j int) bool {
+		return s[i].Start.Before(s[j].Start.Time)
+	})
+
+	return s
+}
+
+// Between returns all Lectures of the Schedule which take place from the Date
+// from to the Date to (inclusive).
+func (s Schedule) Between(from, to timeutil.Date) Schedule {
+	lectures := Schedule{}
+	for _, l := range s {
+		d := l.Start.Date()
+		if !d.Before(from) && !to.Before(d) {
+			lectures = append(lectures, l)
+		}
+	}
+
+	return lectures
+}
+
+// A LectureAttendance maps a journal.AttendanceEntry to the Lecture it
+// overlaps. Overlap is the time the Person attended the Lecture.
+type LectureAttendance struct {
+	Lecture Lecture
+	Entry   journal.AttendanceEntry
+	Overlap time.Duration
+}
+
+// A LectureAttendanceList is a collection of LectureAttendances.
+type LectureAttendanceList []LectureAttendance
+
+// MapAttendances maps every Session in the journals to the Lectures of the
+// Schedule it overlaps by at least minOverlap. The Sessions are taken from the
+// attendance list of the Location of the Lecture on the day of the Lecture.
+// A Session without a logout lasts until the end of its day.
+//
+// The LectureAttendanceList is sorted by the Lectures.
+func (s Schedule) MapAttendances(journals []journal.Journal, minOverlap time.Duration) LectureAttendanceList {
+	days := make(map[timeutil.Date]journal.Journal)
+	for _, j := range journals {
+		days[j.Date] = j
+	}
+
+	list := LectureAttendanceList{}
+	for _, l := range s {
+		j, ok := days[l.Start.Date()]
+		if !ok {
+			continue
+		}
+
+		for _, e := range j.GetAttendanceListForLocation(l.Location) {
+			start, end := e.Login(), e.Logout()
+			if end == timeutil.InvalidTimestamp {
+				end = timeutil.NewTimestamp(j.Date.Year, j.Date.Month, j.Date.Day, 23, 59, 59)
+			}
+			if start.Before(l.Start.Time) {
+				start = l.Start
+			}
+			if end.After(l.End.Time) {
+				end = l.End
+			}
+
+			overlap := end.Sub(start.Time)
+			if overlap > 0 && overlap >= minOverlap {
+				list = append(list, LectureAttendance{l, e, overlap})
+			}
+		}
+	}
+
+	return list
+}
+
+// NextEntry returns a read-only channel that loops through the hole
+// LectureAttendanceList and returns data of the LectureAttendance as a string
+// slice.
+//
+// Used to convert a LectureAttendanceList to any file format.
+func (l LectureAttendanceList) NextEntry() <-chan []string {
+	entries := make(chan []string)
+	go func() {
+		for _, a := range l {
+			p := a.Entry.Person()
+			login, logout := "", ""
+			if a.Entry.Login() != timeutil.InvalidTimestamp {
+				login = a.Entry.Login().Clock()
+			}
+
+			if a.Entry.Logout() != timeutil.InvalidTimestamp {
+				logout = a.Entry.Logout().Clock()
+			}
+
+			entries <- []string{a.Lecture.Course, string(a.Lecture.Location), a.Lecture.Start.String(), a.Lecture.End.String(),
+				p.FirstName, p.LastName, p.Address.Street, p.Address.Number, p.Address.ZipCode, p.Address.City,
+				login, logout, a.Overlap.String()}
+		}
+
+		close(entries)
+	}()
+
+	return entries
+}
+
+// Header returns a string slice which describes the data given by the NextEntry
+// function.
+//
+// Used to convert a LectureAttendanceList to any file format.
+func (l LectureAttendanceList) Header() []string {
+	return []string{"Course", "Location", "Start", "End", "FirstName", "LastName", "Street", "Number", "ZipCode", "City", "Login", "Logout", "Overlap"}
+}
+
+// A CourseAttendance holds how many of the Lectures of a Course a Person
+// attended.
+type CourseAttendance struct {
+	Course   string
+	Person   journal.Person
+	Attended int
+	Lectures int
+}
+
+// Percentage returns the share of attended Lectures in percent.
+func (c CourseAttendance) Percentage() float64 {
+	if c.Lectures == 0 {
+		return 0
+	}
+
+	return float64(c.Attended) * 100 / float64(c.Lectures)
+}
+
+// Summarize computes the CourseAttendance of every Person who attended at
+// least one Lecture of a Course in the Schedule s. A Lecture counts once, even
+// if the Person attended it with multiple Sessions.
+//
+// The CourseAttendanceList is sorted by Course and the first attendance of the
+// Persons.
+func (l LectureAttendanceList) Summarize(s Schedule) CourseAttendanceList {
+	lectures := make(map[string]int)
+	for _, lecture := range s {
+		lectures[lecture.Course]++
+	}
+
+	type key struct {
+		course string
+		person journal.Person
+	}
+
+	attended := make(map[key]map[Lecture]bool)
+	order := make([]key, 0)
+	for _, a := range l {
+		k := key{a.Lecture.Course, a.Entry.Person()}
+		if _, ok := attended[k]; !ok {
+			attended[k] = make(map[Lecture]bool)
+			order = append(order, k)
+		}
+
+		attended[k][a.Lecture] = true
+	}
+
+	list := CourseAttendanceList{}
+	for _, k := range order {
+		list = append(list, CourseAttendance{k.course, k.person, len(attended[k]), lectures[k.course]})
+	}
+
+	sort.SliceStable(list, func(i, j int) bool {
+		return list[i].Course < list[j].Course
+	})
+
+	return list
+}
+
+// A CourseAttendanceList is a collection of CourseAttendances.
+type CourseAttendanceList []CourseAttendance
+
+// NextEntry returns a read-only channel that loops through the hole
+// CourseAttendanceList and returns data of the CourseAttendance as a string
+// slice.
+//
+// Used to convert a CourseAttendanceList to any file format.
+func (l CourseAttendanceList) NextEntry() <-chan []string {
+	entries := make(chan []string)
+	go func() {
+		for _, c := range l {
+			entries <- []string{c.Course, c.Person.FirstName, c.Person.LastName,
+				c.Person.Address.Street, c.Person.Address.Number, c.Person.Address.ZipCode, c.Person.Address.City,
+				strconv.Itoa(c.Attended), strconv.Itoa(c.Lectures), strconv.FormatFloat(c.Percentage(), 'f', 1, 64)}
+		}
+
+		close(entries)
+	}()
+
+	return entries
+}
+
+// Header returns a string slice which describes the data given by the NextEntry
+// function.
+//
+// Used to convert a CourseAttendanceList to any file format.
+func (l CourseAttendanceList) Header() []string {
+	return []string{"Course", "FirstName", "LastName", "Street", "Number", "ZipCode", "City", "Attended", "Lectures", "Percentage"}
+}
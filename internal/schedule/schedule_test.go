@@ -0,0 +1,145 @@
+// This source file is part of the attendance list project
+// as a part of the go lecture by H. Neemann.
+// For this reason you have no permission to use, modify or
+// share this code without the agreement of the authors.
+//
+// Matriculation numbers of the authors: 5703004, 5736465
+
+// Package schedule provides functionality for tracking the attendance of
+// lectures against a lecture schedule.
+package schedule
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dateiexplorer/attendancelist/internal/journal"
+	"github.com/dateiexplorer/attendancelist/internal/timeutil"
+	"github.com/stretchr/testify/assert"
+)
+
+var lectures = Schedule{
+	{"Go", "DHBW Mosbach", timeutil.NewTimestamp(2021, 10, 15, 9, 0, 0), timeutil.NewTimestamp(2021, 10, 15, 12, 0, 0)},
+	{"Datenbanken", "Alte Mälzerei", timeutil.NewTimestamp(2021, 10, 15, 18, 0, 0), timeutil.NewTimestamp(2021, 10, 15, 19, 0, 0)},
+	{"Go", "DHBW Mosbach", timeutil.NewTimestamp(2021, 11, 23, 9, 0, 0), timeutil.NewTimestamp(2021, 11, 23, 12, 0, 0)},
+	{"Go", "DHBW Mosbach", timeutil.NewTimestamp(2021, 11, 30, 9, 0, 0), timeutil.NewTimestamp(2021, 11, 30, 12, 0, 0)},
+}
+
+func TestReadScheduleCSV(t *testing.T) {
+	actual, err := ReadSchedule("testdata/schedule.csv")
+	assert.NoError(t, err)
+	assert.Equal(t, lectures, actual)
+}
+
+func TestReadScheduleICS(t *testing.T) {
+	actual, err := ReadSchedule("testdata/schedule.ics")
+	assert.NoError(t, err)
+	assert.Equal(t, lectures, actual)
+}
+
+func TestReadScheduleFailed(t *testing.T) {
+	_, err := ReadSchedule("testdata/missing.csv")
+	assert.Error(t, err)
+
+	_, err = ReadScheduleFromCSV(strings.NewReader("Go,DHBW Mosbach,2021/10/15 09:00\n"))
+	assert.Error(t, err)
+
+	_, err = ReadScheduleFromCSV(strings.NewReader("Go,DHBW Mosbach,2021/10/15 09:00,tomorrow\n"))
+	assert.Error(t, err)
+}
+
+func TestScheduleBetween(t *testing.T) {
+	actual := lectures.Between(timeutil.NewDate(2021, 10, 16), timeutil.NewDate(2021, 11, 30))
+	assert.Equal(t, lectures[2:], actual)
+}
+
+func TestMapAttendances(t *testing.T) {
+	journals, err := journal.ReadJournals("testdata", timeutil.NewDate(2021, 10, 15), timeutil.NewDate(2021, 11, 30))
+	assert.NoError(t, err)
+
+	list := lectures.MapAttendances(journals, 0)
+	expected := []struct {
+		course  string
+		person  string
+		overlap time.Duration
+	}{
+		{"Go", "Hans", 3 * time.Hour},
+		{"Go", "Gisela", 2*time.Hour + 44*time.Minute + 40*time.Second},
+		{"Datenbanken", "Hans", time.Hour},
+		{"Datenbanken", "Otto", time.Hour},
+		{"Go", "Gisela", 3 * time.Hour},
+		{"Go", "Max", 2 * time.Hour},
+		{"Go", "Anne", time.Hour},
+	}
+
+	assert.Equal(t, len(expected), len(list))
+	for i, e := range expected {
+		assert.Equal(t, e.course, list[i].Lecture.Course)
+		assert.Equal(t, e.person, list[i].Entry.Person().FirstName)
+		assert.Equal(t, e.overlap, list[i].Overlap)
+	}
+
+	// Anne Meier attended only one hour.
+	list = lectures.MapAttendances(journals, 2*time.Hour)
+	assert.Equal(t, 4, len(list))
+}
+
+func TestSummarize(t *testing.T) {
+	expected := [][]string{
+		{"Datenbanken", "Hans", "Müller", "Feldweg", "12", "74722", "Buchen", "1", "1", "100.0"},
+		{"Datenbanken", "Otto", "Normalverbraucher", "Dieselstraße", "52", "70376", "Stuttgart", "1", "1", "100.0"},
+		{"Go", "Hans", "Müller", "Feldweg", "12", "74722", "Buchen", "1", "3", "33.3"},
+		{"Go", "Gisela", "Musterfrau", "Musterstraße", "10", "74821", "Mosbach", "2", "3", "66.7"},
+		{"Go", "Max", "Mustermann", "Musterstraße", "20", "74821", "Mosbach", "1", "3", "33.3"},
+		{"Go", "Anne", "Meier", "Hauptstraße", "18", "74821", "Mosbach", "1", "3", "33.3"},
+	}
+
+	journals, err := journal.ReadJournals("testdata", timeutil.NewDate(2021, 10, 15), timeutil.NewDate(2021, 11, 30))
+	assert.NoError(t, err)
+
+	summary := lectures.MapAttendances(journals, 0).Summarize(lectures)
+	counter := 0
+	for actual := range summary.NextEntry() {
+		assert.Equal(t, expected[counter], actual)
+		counter++
+	}
+
+	assert.Equal(t, len(expected), counter)
+	assert.Equal(t, len(expected[0]), len(summary.Header()))
+}
+
+func TestLectureAttendanceListNextEntry(t *testing.T) {
+	expected := []string{"Datenbanken", "Alte Mälzerei", "2021/10/15 18:00:00 UTC", "2021/10/15 19:00:00 UTC",
+		"Hans", "Müller", "Feldweg", "12", "74722", "Buchen", "13:40:11", "", "1h0m0s"}
+
+	list := LectureAttendanceList{{
+		lectures[1],
+		journal.NewAttendanceEntry(journal.NewPerson("Hans", "Müller", "Feldweg", "12", "74722", "Buchen"), timeutil.NewTimestamp(2021, 10, 15, 13, 40, 11), timeutil.InvalidTimestamp),
+		time.Hour,
+	}}
+
+	for actual := range list.NextEntry() {
+		assert.Equal(t, expected, actual)
+	}
+
+	assert.Equal(t, len(expected), len(list.Header()))
+}
+
+func TestLectureAttendanceListNextEntryOnlyLogout(t *testing.T) {
+	list := LectureAttendanceList{{
+		lectures[1],
+		journal.NewAttendanceEntry(journal.NewPerson("Hans", "Müller", "Feldweg", "12", "74722", "Buchen"), timeutil.InvalidTimestamp, timeutil.NewTimestamp(2021, 10, 15, 18, 30, 0)),
+		30 * time.Minute,
+	}}
+
+	for actual := range list.NextEntry() {
+		assert.Equal(t, "", actual[10])
+		assert.Equal(t, "18:30:00", actual[11])
+	}
+}
+
+func TestCourseAttendancePercentage(t *testing.T) {
+	assert.Equal(t, 0.0, CourseAttendance{Attended: 0, Lectures: 0}.Percentage())
+	assert.Equal(t, 50.0, CourseAttendance{Attended: 1, Lectures: 2}.Percentage())
+}
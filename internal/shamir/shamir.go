@@ -0,0 +1,207 @@
+// This source file is part of the attendance list project
+// as a part of the go lecture by H. Neemann.
+// For this reason you have no permission to use, modify or
+// share this code without the agreement of the authors.
+//
+// Matriculation numbers of the authors: 5703004, 5736465
+
+// Package shamir provides functionality for splitting a secret into shares with
+// Shamir's secret sharing scheme, so that a minimum number of shares is
+// required to recover the secret.
+//
+// Every byte of the secret is shared by its own random polynomial over the
+// finite field GF(2^8).
+package shamir
+
+import (
+	"crypto/rand"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"strconv"
+)
+
+// The type of a PEM block which holds a Share.
+const pemType = "KEY SHARE"
+
+// A Share is a part of a secret. X is the unique number of the Share which is
+// never zero, Threshold the number of Shares required to recover the secret
+// and Y the value of the polynomials at X.
+type Share struct {
+	X         byte
+	Threshold int
+	Y         []byte
+}
+
+// Split splits the secret into n Shares, where k Shares are required to
+// recover the secret.
+//
+// Returns an error if the secret is empty or k and n don't satisfy
+// 2 <= k <= n <= 255.
+func Split(secret []byte, n, k int) ([]Share, error) {
+	if len(secret) == 0 {
+		return nil, errors.New("cannot split an empty secret")
+	}
+
+	if k < 2 || k > n || n > 255 {
+		return nil, fmt.Errorf("cannot split secret into %v shares with a threshold of %v, 2 <= k <= n <= 255 is required", n, k)
+	}
+
+	shares := make([]Share, n)
+	for i := range shares {
+		shares[i] = Share{byte(i + 1), k, make([]byte, len(secret))}
+	}
+
+	// The coefficients of the polynomial for one byte of the secret, the
+	// constant term is the byte itself.
+	coefficients := make([]byte, k)
+	for b, s := range secret {
+		if _, err := rand.Read(coefficients[1:]); err != nil {
+			return nil, fmt.Errorf("cannot split secret: %w", err)
+		}
+		coefficients[0] = s
+
+		for i := range shares {
+			shares[i].Y[b] = evaluate(coefficients, shares[i].X)
+		}
+	}
+
+	return shares, nil
+}
+
+// Combine recovers the secret from the shares.
+//
+// Returns an error if there are less shares than required, two shares have the
+// same number or the shares don't belong to the same secret.
+func Combine(shares []Share) ([]byte, error) {
+	if len(shares) == 0 {
+		return nil, errors.New("cannot combine secret: no shares given")
+	}
+
+	threshold, length := shares[0].Threshold, len(shares[0].Y)
+	seen := make(map[byte]bool)
+	for _, s := range shares {
+		if s.Threshold != threshold || len(s.Y) != length {
+			return nil, errors.New("cannot combine secret: shares belong to different secrets")
+		}
+
+		if s.X == 0 || seen[s.X] {
+			return nil, fmt.Errorf("cannot combine secret: invalid or duplicate share number %v", s.X)
+		}
+		seen[s.X] = true
+	}
+
+	if len(shares) < threshold {
+		return nil, fmt.Errorf("cannot combine secret: %v shares are required, got %v", threshold, len(shares))
+	}
+
+	// Lagrange interpolation at x = 0 with the first threshold shares.
+	shares = shares[:threshold]
+	secret := make([]byte, length)
+	for i, si := range shares {
+		// The Lagrange basis polynomial of the share at 0.
+		basis := byte(1)
+		for j, sj := range shares {
+			if i != j {
+				basis = mul(basis, div(sj.X, sj.X^si.X))
+			}
+		}
+
+		for b := range secret {
+			secret[b] ^= mul(basis, si.Y[b])
+		}
+	}
+
+	return secret, nil
+}
+
+// MarshalPEM returns the Share as PEM block, the number and the threshold are
+// stored as headers.
+func (s Share) MarshalPEM() []byte {
+	return pem.EncodeToMemory(&pem.Block{
+		Type: pemType,
+		Headers: map[string]string{
+			"Share":     strconv.Itoa(int(s.X)),
+			"Threshold": strconv.Itoa(s.Threshold),
+		},
+		Bytes: s.Y,
+	})
+}
+
+// ParsePEM parses a Share from a PEM block created with MarshalPEM.
+//
+// Returns an error if the data holds no valid Share.
+func ParsePEM(data []byte) (Share, error) {
+	block, _ := pem.Decode(data)
+	if block == nil || block.Type != pemType {
+		return Share{}, errors.New("cannot parse share: no PEM encoded share found")
+	}
+
+	x, err := strconv.Atoi(block.Headers["Share"])
+	if err != nil || x < 1 || x > 255 {
+		return Share{}, fmt.Errorf("cannot parse share: invalid share number \"%v\"", block.Headers["Share"])
+	}
+
+	threshold, err := strconv.Atoi(block.Headers["Threshold"])
+	if err != nil || threshold < 2 {
+		return Share{}, fmt.Errorf("cannot parse share: invalid threshold \"%v\"", block.Headers["Threshold"])
+	}
+
+	return Share{byte(x), threshold, block.Bytes}, nil
+}
+
+// evaluate returns the value of the polynomial with the coefficients at x
+// using Horner's method.
+func evaluate(coefficients []byte, x byte) byte {
+	y := byte(0)
+	for i := len(coefficients) - 1; i >= 0; i-- {
+		y = mul(y, x) ^ coefficients[i]
+	}
+
+	return y
+}
+
+// Logarithm and exponential tables of GF(2^8) with the reducing polynomial
+// x^8 + x^4 + x^3 + x + 1 and the generator 3.
+var logTable, expTable = func() ([256]byte, [510]byte) {
+	var log [256]byte
+	var exp [510]byte
+
+	x := byte(1)
+	for i := 0; i < 255; i++ {
+		exp[i], exp[i+255] = x, x
+		log[x] = byte(i)
+
+		// Multiply x with the generator 3.
+		x ^= xtime(x)
+	}
+
+	return log, exp
+}()
+
+// xtime multiplies a with x in GF(2^8).
+func xtime(a byte) byte {
+	if a&0x80 != 0 {
+		return a<<1 ^ 0x1b
+	}
+
+	return a << 1
+}
+
+// mul multiplies a and b in GF(2^8).
+func mul(a, b byte) byte {
+	if a == 0 || b == 0 {
+		return 0
+	}
+
+	return expTable[int(logTable[a])+int(logTable[b])]
+}
+
+// div divides a by b in GF(2^8), b must not be zero.
+func div(a, b byte) byte {
+	if a == 0 {
+		return 0
+	}
+
+	return expTable[int(logTable[a])+255-int(logTable[b])]
+}
@@ -0,0 +1,98 @@
+// This source file is part of the attendance list project
+// as a part of the go lecture by H. Neemann.
+// For this reason you have no permission to use, modify or
+// share this code without the agreement of the authors.
+//
+// Matriculation numbers of the authors: 5703004, 5736465
+
+// Package synthetic provides functionality for generating realistic synthetic
+// journals with known contacts, e.g. for load tests of the analyzer.
+package synthetic
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/dateiexplorer/attendancelist/internal/journal"
+	"github.com/dateiexplorer/attendancelist/internal/timeutil"
+)
+
+// A Contact is the known meet of two Persons at a Location from Start to End.
+type Contact struct {
+	Person, Other journal.Person
+	Location      journal.Location
+	Start, End    timeutil.Timestamp
+}
+
+// A ContactList is the ground truth of all contacts of a Dataset.
+type ContactList []Contact
+
+// Contacts returns all pairs of Visits at the same Location which overlap by
+// at least one second, computed directly from the Visits and independent of
+// the journal files. Every pair is listed once, the Person of the earlier Visit
+// first.
+func (d Dataset) Contacts() ContactList {
+	contacts := ContactList{}
+	open := make(map[journal.Location][]Visit)
+	for _, v := range d.Visits {
+		// The Visits are sorted by their Start, so every Visit which ended
+		// before this one starts can't overlap any later Visit.
+		present := open[v.Location][:0]
+		for _, o := range open[v.Location] {
+			if !o.End.After(v.Start.Time) {
+				continue
+			}
+
+			present = append(present, o)
+			if o.Person == v.Person {
+				continue
+			}
+
+			end := o.End
+			if v.End.Before(end.Time) {
+				end = v.End
+			}
+
+			contacts = append(contacts, Contact{o.Person, v.Person, v.Location, v.Start, end})
+		}
+
+		open[v.Location] = append(present, v)
+	}
+
+	sort.SliceStable(contacts, func(i, j int) bool {
+		return contacts[i].Start.Before(contacts[j].Start.Time)
+	})
+
+	return contacts
+}
+
+// Duration returns the time the Persons of the Contact met.
+func (c Contact) Duration() time.Duration {
+	return c.End.Sub(c.Start.Time)
+}
+
+// NextEntry returns a read-only channel that loops through the hole
+// ContactList and returns data of the Contact as a string slice.
+//
+// Used to convert a ContactList to any file format.
+func (l ContactList) NextEntry() <-chan []string {
+	entries := make(chan []string)
+	go func() {
+		for _, c := range l {
+			entries <- []string{c.Person.String(), c.Other.String(), string(c.Location), c.Start.String(), c.End.String(), fmt.Sprint(c.Duration())}
+		}
+
+		close(entries)
+	}()
+
+	return entries
+}
+
+// Header returns a string slice which describes the data given by the NextEntry
+// function.
+//
+// Used to convert a ContactList to any file format.
+func (l ContactList) Header() []string {
+	return []string{"Person", "Contact", "Location", "Start", "End", "Duration"}
+}
@@ -0,0 +1,103 @@
+// This source file is part of the attendance list project
+// as a part of the go lecture by H. Neemann.
+// For this reason you have no permission to use, modify or
+// share this code without the agreement of the authors.
+//
+// Matriculation numbers of the authors: 5703004, 5736465
+
+// Package synthetic provides functionality for generating realistic synthetic
+// journals with known contacts, e.g. for load tests of the analyzer.
+package synthetic
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"strings"
+	"time"
+)
+
+// A Distribution draws random durations, e.g. the arrival time since midnight
+// or the dwell time of a visit.
+type Distribution interface {
+	Draw(r *rand.Rand) time.Duration
+}
+
+// Uniform is a Distribution of durations equally distributed between Min and
+// Max.
+type Uniform struct {
+	Min, Max time.Duration
+}
+
+// Draw returns a random duration between Min and Max.
+func (u Uniform) Draw(r *rand.Rand) time.Duration {
+	if u.Max <= u.Min {
+		return u.Min
+	}
+
+	return u.Min + time.Duration(r.Int63n(int64(u.Max-u.Min)))
+}
+
+// Normal is a normal Distribution of durations with the Mean and the standard
+// deviation StdDev.
+type Normal struct {
+	Mean, StdDev time.Duration
+}
+
+// Draw returns a normally distributed random duration.
+func (n Normal) Draw(r *rand.Rand) time.Duration {
+	return n.Mean + time.Duration(r.NormFloat64()*float64(n.StdDev))
+}
+
+// Exponential is an exponential Distribution of durations with the Mean.
+type Exponential struct {
+	Mean time.Duration
+}
+
+// Draw returns an exponentially distributed random duration.
+func (e Exponential) Draw(r *rand.Rand) time.Duration {
+	return time.Duration(r.ExpFloat64() * float64(e.Mean))
+}
+
+// ParseDistribution parses a Distribution of the form "uniform:min,max",
+// "normal:mean,stddev" or "exp:mean", where all parameters are durations like
+// "8h" or "45m".
+//
+// Returns an error if the string doesn't apply one of the forms.
+func ParseDistribution(value string) (Distribution, error) {
+	kind := value
+	var params []time.Duration
+	if i := strings.Index(value, ":"); i >= 0 {
+		kind = value[:i]
+		for _, p := range strings.Split(value[i+1:], ",") {
+			d, err := time.ParseDuration(strings.TrimSpace(p))
+			if err != nil {
+				return nil, fmt.Errorf("cannot parse distribution \"%v\": %w", value, err)
+			}
+
+			params = append(params, d)
+		}
+	}
+
+	switch {
+	case kind == "uniform" && len(params) == 2 && params[0] <= params[1]:
+		return Uniform{params[0], params[1]}, nil
+	case kind == "normal" && len(params) == 2 && params[1] >= 0:
+		return Normal{params[0], params[1]}, nil
+	case kind == "exp" && len(params) == 1 && params[0] > 0:
+		return Exponential{params[0]}, nil
+	default:
+		return nil, fmt.Errorf("cannot parse distribution \"%v\": expected uniform:min,max, normal:mean,stddev or exp:mean", value)
+	}
+}
+
+// poisson returns a Poisson distributed random number with the mean lambda.
+func poisson(r *rand.Rand, lambda float64) int {
+	l := math.Exp(-lambda)
+	k := 0
+	for p := r.Float64(); p > l; p *= r.Float64() {
+		k++
+	}
+
+	return k
+}
@@ -0,0 +1,221 @@
+// This source file is part of the attendance list project
+// as a part of the go lecture by H. Neemann.
+// For this reason you have no permission to use, modify or
+// share this code without the agreement of the authors.
+//
+// Matriculation numbers of the authors: 5703004, 5736465
+
+// Package synthetic provides functionality for generating realistic synthetic
+// journals with known contacts, e.g. for load tests of the analyzer.
+package synthetic
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"os"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/dateiexplorer/attendancelist/internal/journal"
+	"github.com/dateiexplorer/attendancelist/internal/timeutil"
+)
+
+// The last second of a day. Visits which would end later are cut here.
+const endOfDay = 24*time.Hour - time.Second
+
+// Names and addresses the Persons are composed of.
+var (
+	firstNames = []string{"Hans", "Gisela", "Max", "Anne", "Lieschen", "Otto", "Erika", "Peter", "Maria", "Jonas",
+		"Lea", "Paul", "Sophie", "Felix", "Emma", "Lukas", "Mia", "Leon", "Hannah", "Tim"}
+	lastNames = []string{"Müller", "Schmidt", "Schneider", "Fischer", "Weber", "Meyer", "Wagner", "Becker", "Schulz", "Hoffmann",
+		"Koch", "Richter", "Klein", "Wolf", "Neumann", "Schwarz", "Braun", "Zimmermann", "Krüger", "Hartmann"}
+	streets = []string{"Hauptstraße", "Schulstraße", "Gartenstraße", "Bahnhofstraße", "Feldweg", "Lindenstraße",
+		"Bergstraße", "Kirchstraße", "Waldstraße", "Ringstraße"}
+	cities = []struct{ zipCode, city string }{
+		{"74821", "Mosbach"}, {"74722", "Buchen"}, {"74889", "Sinsheim"}, {"69117", "Heidelberg"},
+		{"68159", "Mannheim"}, {"70376", "Stuttgart"}, {"74072", "Heilbronn"}, {"10115", "Berlin"},
+	}
+)
+
+// Config describes the synthetic journals to generate.
+//
+// Every Person visits VisitsPerDay Locations a day on average. The time of
+// arrival since midnight is drawn from Arrival, the time of stay from Dwell.
+// The same Seed generates the same journals.
+type Config struct {
+	People       int
+	Locations    []journal.Location
+	From         timeutil.Date
+	Days         int
+	VisitsPerDay float64
+	Arrival      Distribution
+	Dwell        Distribution
+	Seed         int64
+}
+
+// A Visit is the stay of a Person at a Location from Start to End.
+type Visit struct {
+	Person     journal.Person
+	SessionID  string
+	Location   journal.Location
+	Start, End timeutil.Timestamp
+}
+
+// A Dataset holds the generated Persons and Visits in chronological order.
+type Dataset struct {
+	Persons []journal.Person
+	Visits  []Visit
+}
+
+// Generate generates a Dataset as described by the Config c.
+//
+// Returns an error if the Config is invalid.
+func Generate(c Config) (Dataset, error) {
+	switch {
+	case c.People < 0 || c.Days < 0 || c.VisitsPerDay < 0:
+		return Dataset{}, errors.New("cannot generate journals: the number of people, days and visits must not be negative")
+	case len(c.Locations) == 0:
+		return Dataset{}, errors.New("cannot generate journals: no locations given")
+	case c.Arrival == nil || c.Dwell == nil:
+		return Dataset{}, errors.New("cannot generate journals: no arrival or dwell time distribution given")
+	}
+
+	r := rand.New(rand.NewSource(c.Seed))
+	d := Dataset{Persons: generatePersons(r, c.People), Visits: []Visit{}}
+	for day := 0; day < c.Days; day++ {
+		date := c.From.AddDays(day)
+		for _, p := range d.Persons {
+			d.Visits = append(d.Visits, generateVisits(r, c, p, date)...)
+		}
+	}
+
+	sort.SliceStable(d.Visits, func(i, j int) bool {
+		return d.Visits[i].Start.Before(d.Visits[j].Start.Time)
+	})
+
+	return d, nil
+}
+
+// generatePersons returns n different random Persons.
+func generatePersons(r *rand.Rand, n int) []journal.Person {
+	persons := make([]journal.Person, 0, n)
+	known := make(map[journal.Person]bool, n)
+	for len(persons) < n {
+		c := cities[r.Intn(len(cities))]
+		p := journal.NewPerson(firstNames[r.Intn(len(firstNames))], lastNames[r.Intn(len(lastNames))],
+			streets[r.Intn(len(streets))], strconv.Itoa(1+r.Intn(len(persons)+100)), c.zipCode, c.city)
+		if !known[p] {
+			known[p] = true
+			persons = append(persons, p)
+		}
+	}
+
+	return persons
+}
+
+// generateVisits returns the Visits of the Person p on the date. A Person
+// visits only one Location at a time, arrivals during another Visit are
+// dropped.
+func generateVisits(r *rand.Rand, c Config, p journal.Person, date timeutil.Date) []Visit {
+	n := poisson(r, c.VisitsPerDay)
+	arrivals := make([]time.Duration, 0, n)
+	for i := 0; i < n; i++ {
+		arrivals = append(arrivals, clamp(c.Arrival.Draw(r), 0, endOfDay-time.Second))
+	}
+
+	sort.Slice(arrivals, func(i, j int) bool {
+		return arrivals[i] < arrivals[j]
+	})
+
+	visits := make([]Visit, 0, n)
+	free := time.Duration(0)
+	for _, arrival := range arrivals {
+		// Draw the remaining values for every arrival to keep the random
+		// sequence independent of dropped visits.
+		dwell := clamp(c.Dwell.Draw(r), time.Minute, endOfDay)
+		loc := c.Locations[r.Intn(len(c.Locations))]
+		id := fmt.Sprintf("%016x", r.Uint64())
+
+		if arrival < free {
+			continue
+		}
+
+		end := clamp(arrival+dwell, arrival+time.Second, endOfDay)
+		visits = append(visits, Visit{p, id, loc, date.At(arrival), date.At(end)})
+
+		// Leave one second, so a logout and the next login don't coincide.
+		free = end + time.Second
+	}
+
+	return visits
+}
+
+// clamp truncates the duration d to seconds and limits it to min and max.
+func clamp(d, min, max time.Duration) time.Duration {
+	d = d.Truncate(time.Second)
+	if d < min {
+		return min
+	}
+
+	if d > max {
+		return max
+	}
+
+	return d
+}
+
+// Entries returns the login and logout JournalEntries of all Visits in the
+// order they are written to the journal files. If a login and a logout have
+// the same Timestamp, the logout comes first, so the Visits don't overlap.
+func (d Dataset) Entries() []journal.JournalEntry {
+	entries := make([]journal.JournalEntry, 0, 2*len(d.Visits))
+	for _, v := range d.Visits {
+		entries = append(entries,
+			journal.NewJournalEntry(v.Start, v.SessionID, journal.Login, v.Location, v.Person),
+			journal.NewJournalEntry(v.End, v.SessionID, journal.Logout, v.Location, v.Person))
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		a, b := entries[i], entries[j]
+		if !a.Timestamp.Equal(b.Timestamp.Time) {
+			return a.Timestamp.Before(b.Timestamp.Time)
+		}
+
+		return a.Event == journal.Logout && b.Event == journal.Login
+	})
+
+	return entries
+}
+
+// Write writes the JournalEntries of the Dataset with the write function, e.g.
+// journal.WriteToJournalFile, to the journal files in the dir directory.
+//
+// Returns an error if a journal file for a date of the Dataset already exists,
+// because the generated entries would be mixed with the existing ones, or if
+// the writing operations causes an error.
+func (d Dataset) Write(dir string, write func(dir string, e *journal.JournalEntry) error) error {
+	entries := d.Entries()
+	dates := make(map[timeutil.Date]bool)
+	for _, e := range entries {
+		date := e.Timestamp.Date()
+		if dates[date] {
+			continue
+		}
+
+		if _, err := os.Stat(journal.FilePath(dir, date)); err == nil {
+			return fmt.Errorf("cannot generate journals: journal file for %v already exists", date)
+		}
+
+		dates[date] = true
+	}
+
+	for _, e := range entries {
+		if err := write(dir, &e); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
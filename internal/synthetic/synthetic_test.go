@@ -0,0 +1,206 @@
+// This source file is part of the attendance list project
+// as a part of the go lecture by H. Neemann.
+// For this reason you have no permission to use, modify or
+// share this code without the agreement of the authors.
+//
+// Matriculation numbers of the authors: 5703004, 5736465
+
+// Package synthetic provides functionality for generating realistic synthetic
+// journals with known contacts, e.g. for load tests of the analyzer.
+package synthetic
+
+import (
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/dateiexplorer/attendancelist/internal/journal"
+	"github.com/dateiexplorer/attendancelist/internal/timeutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func testConfig() Config {
+	return Config{
+		People:       40,
+		Locations:    []journal.Location{"DHBW Mosbach", "Alte Mälzerei", "Mensa"},
+		From:         timeutil.NewDate(2021, 10, 15),
+		Days:         3,
+		VisitsPerDay: 2,
+		Arrival:      Normal{12 * time.Hour, 3 * time.Hour},
+		Dwell:        Exponential{time.Hour},
+		Seed:         42,
+	}
+}
+
+func TestParseDistribution(t *testing.T) {
+	d, err := ParseDistribution("uniform:8h,18h")
+	assert.NoError(t, err)
+	assert.Equal(t, Uniform{8 * time.Hour, 18 * time.Hour}, d)
+
+	d, err = ParseDistribution("normal:12h, 2h30m")
+	assert.NoError(t, err)
+	assert.Equal(t, Normal{12 * time.Hour, 150 * time.Minute}, d)
+
+	d, err = ParseDistribution("exp:45m")
+	assert.NoError(t, err)
+	assert.Equal(t, Exponential{45 * time.Minute}, d)
+
+	for _, value := range []string{"", "uniform", "uniform:18h,8h", "normal:12h", "exp:0s", "exp:x", "gamma:1h"} {
+		_, err := ParseDistribution(value)
+		assert.Error(t, err, value)
+	}
+}
+
+func TestDistributionDraw(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 100; i++ {
+		d := Uniform{8 * time.Hour, 18 * time.Hour}.Draw(r)
+		assert.True(t, d >= 8*time.Hour && d < 18*time.Hour)
+		assert.True(t, Exponential{time.Hour}.Draw(r) >= 0)
+	}
+
+	assert.Equal(t, time.Hour, Uniform{time.Hour, time.Hour}.Draw(r))
+	assert.Equal(t, time.Hour, Normal{time.Hour, 0}.Draw(r))
+}
+
+func TestGenerate(t *testing.T) {
+	c := testConfig()
+	d, err := Generate(c)
+	assert.NoError(t, err)
+	assert.Equal(t, c.People, len(d.Persons))
+	assert.NotEmpty(t, d.Visits)
+
+	persons := make(map[journal.Person]bool)
+	for _, p := range d.Persons {
+		persons[p] = true
+	}
+	assert.Equal(t, c.People, len(persons))
+
+	// Visits don't overlap for the same person and stay on one day.
+	last := make(map[journal.Person]timeutil.Timestamp)
+	for i, v := range d.Visits {
+		assert.True(t, v.Start.Before(v.End.Time))
+		assert.Equal(t, v.Start.Date(), v.End.Date())
+		assert.False(t, v.Start.Date().Before(c.From))
+		assert.True(t, v.Start.Date().Before(c.From.AddDays(c.Days)))
+		if end, ok := last[v.Person]; ok {
+			assert.True(t, end.Before(v.Start.Time))
+		}
+		last[v.Person] = v.End
+
+		if i > 0 {
+			assert.False(t, v.Start.Before(d.Visits[i-1].Start.Time))
+		}
+	}
+
+	// The same seed generates the same dataset.
+	other, err := Generate(c)
+	assert.NoError(t, err)
+	assert.Equal(t, d, other)
+
+	c.Seed++
+	other, err = Generate(c)
+	assert.NoError(t, err)
+	assert.NotEqual(t, d, other)
+}
+
+func TestGenerateInvalidConfig(t *testing.T) {
+	c := testConfig()
+	c.Locations = nil
+	_, err := Generate(c)
+	assert.Error(t, err)
+
+	c = testConfig()
+	c.People = -1
+	_, err = Generate(c)
+	assert.Error(t, err)
+
+	c = testConfig()
+	c.Dwell = nil
+	_, err = Generate(c)
+	assert.Error(t, err)
+}
+
+func TestContacts(t *testing.T) {
+	p := []journal.Person{
+		journal.NewPerson("Hans", "Müller", "Feldweg", "12", "74722", "Buchen"),
+		journal.NewPerson("Max", "Mustermann", "Musterstraße", "20", "74821", "Mosbach"),
+		journal.NewPerson("Anne", "Meier", "Hauptstraße", "18", "74821", "Mosbach"),
+	}
+
+	at := func(h, m int) timeutil.Timestamp {
+		return timeutil.NewTimestamp(2021, 10, 15, h, m, 0)
+	}
+
+	d := Dataset{p, []Visit{
+		{p[0], "a", "DHBW Mosbach", at(9, 0), at(12, 0)},
+		{p[1], "b", "DHBW Mosbach", at(10, 0), at(11, 0)},
+		{p[2], "c", "Mensa", at(11, 0), at(13, 0)},
+		{p[1], "d", "DHBW Mosbach", at(12, 0), at(13, 0)},
+	}}
+
+	// Touching visits are no contact.
+	assert.Equal(t, ContactList{{p[0], p[1], "DHBW Mosbach", at(10, 0), at(11, 0)}}, d.Contacts())
+	assert.Equal(t, time.Hour, d.Contacts()[0].Duration())
+}
+
+func TestGroundTruthMatchesJournalContacts(t *testing.T) {
+	for seed := int64(1); seed <= 5; seed++ {
+		c := testConfig()
+		c.Seed = seed
+		checkGroundTruth(t, c)
+	}
+}
+
+// checkGroundTruth checks that the contacts the journal package finds in the
+// written journals are the known contacts of the generated Dataset.
+func checkGroundTruth(t *testing.T, c Config) {
+	d, err := Generate(c)
+	assert.NoError(t, err)
+
+	dir := t.TempDir()
+	assert.NoError(t, d.Write(dir, journal.WriteToJournalFile))
+
+	// Journal files for the dates exist now.
+	assert.Error(t, d.Write(dir, journal.WriteToJournalFile))
+
+	journals, err := journal.ReadJournals(dir, c.From, c.From.AddDays(c.Days-1))
+	assert.NoError(t, err)
+	assert.Equal(t, c.Days, len(journals))
+
+	// Every contact of the ground truth appears for both persons.
+	expected := make(map[journal.Person][]journal.Contact)
+	for _, contact := range d.Contacts() {
+		expected[contact.Person] = append(expected[contact.Person], journal.NewContact(contact.Other, contact.Location, contact.Start, contact.End))
+		expected[contact.Other] = append(expected[contact.Other], journal.NewContact(contact.Person, contact.Location, contact.Start, contact.End))
+	}
+	assert.NotEmpty(t, expected)
+
+	for _, p := range d.Persons {
+		actual := []journal.Contact{}
+		for _, j := range journals {
+			for _, contact := range j.GetContactsForPerson(&p) {
+				if contact.Duration > 0 {
+					actual = append(actual, contact)
+				}
+			}
+		}
+
+		assert.ElementsMatch(t, expected[p], actual, p.String())
+	}
+}
+
+func TestContactListNextEntry(t *testing.T) {
+	p := journal.NewPerson("Hans", "Müller", "Feldweg", "12", "74722", "Buchen")
+	q := journal.NewPerson("Max", "Mustermann", "Musterstraße", "20", "74821", "Mosbach")
+	l := ContactList{{p, q, "Mensa", timeutil.NewTimestamp(2021, 10, 15, 12, 0, 0), timeutil.NewTimestamp(2021, 10, 15, 12, 30, 0)}}
+
+	entries := [][]string{}
+	for e := range l.NextEntry() {
+		entries = append(entries, e)
+	}
+
+	assert.Equal(t, [][]string{{"Hans,Müller,Feldweg,12,74722,Buchen", "Max,Mustermann,Musterstraße,20,74821,Mosbach", "Mensa",
+		"2021/10/15 12:00:00 UTC", "2021/10/15 12:30:00 UTC", "30m0s"}}, entries)
+	assert.Equal(t, []string{"Person", "Contact", "Location", "Start", "End", "Duration"}, l.Header())
+}
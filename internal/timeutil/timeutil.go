@@ -57,6 +57,22 @@ func ParseTimestamp(value string) (Timestamp, error) {
 	return Timestamp{time}, nil
 }
 
+// ParseDateTime parses a string typed in by a user and returns the Timestamp
+// value it represents in timezone UTC.
+//
+// The format of the input value must be in form "yyyy/MM/dd hh:mm:ss" or
+// "yyyy/MM/dd hh:mm". If the given string cannot be parsed an error and an
+// InvalidTimestamp will be returned.
+func ParseDateTime(value string) (Timestamp, error) {
+	for _, layout := range []string{"2006/01/02 15:04:05", "2006/01/02 15:04"} {
+		if t, err := time.Parse(layout, strings.TrimSpace(value)); err == nil {
+			return Timestamp{t}, nil
+		}
+	}
+
+	return InvalidTimestamp, fmt.Errorf("error parsing timestamp \"%v\": expected format yyyy/MM/dd hh:mm[:ss]", value)
+}
+
 // Now returns the current Timestamp in timezone UTC.
 // It calls the time.Now function and wraps it in the Timestamp type.
 func Now() Timestamp {
@@ -129,3 +145,63 @@ func ParseDate(value string) (Date, error) {
 func (d Date) String() string {
 	return fmt.Sprintf("%04d-%02d-%02d", d.Year, int(d.Month), d.Day)
 }
+
+// AddDays returns the Date n days after d. A negative n returns a Date before d.
+func (d Date) AddDays(n int) Date {
+	return NewDate(time.Date(d.Year, d.Month, d.Day+n, 0, 0, 0, 0, time.UTC).Date())
+}
+
+// Before reports whether the Date d is before the Date o.
+func (d Date) Before(o Date) bool {
+	return d.At(0).Before(o.At(0).Time)
+}
+
+// At returns the Timestamp at the time of day clock on the Date d.
+func (d Date) At(clock time.Duration) Timestamp {
+	return Timestamp{time.Date(d.Year, d.Month, d.Day, 0, 0, 0, 0, time.UTC).Add(clock)}
+}
+
+// ParseDateRange parses a string of the layout "yyyy/MM/dd" or
+// "yyyy/MM/dd-yyyy/MM/dd" into the first and the last Date of the range.
+// A single Date is a range of one day.
+//
+// If the string doesn't apply the layout or the first Date is after the last
+// Date an error and two InvalidDates will be returned.
+func ParseDateRange(value string) (Date, Date, error) {
+	values := strings.Split(value, "-")
+	if len(values) > 2 {
+		return InvalidDate, InvalidDate, errors.New("ParseDateRange: invalid length")
+	}
+
+	from, err := ParseDate(values[0])
+	if err != nil {
+		return InvalidDate, InvalidDate, err
+	}
+
+	to := from
+	if len(values) == 2 {
+		if to, err = ParseDate(values[1]); err != nil {
+			return InvalidDate, InvalidDate, err
+		}
+	}
+
+	if to.Before(from) {
+		return InvalidDate, InvalidDate, fmt.Errorf("error parsing date range \"%v\": %v is before %v", value, to, from)
+	}
+
+	return from, to, nil
+}
+
+// ParseClock parses a time of day of the layout "hh:mm" or "hh:mm:ss" and
+// returns the duration since midnight.
+//
+// If the string doesn't apply the layout an error will be returned.
+func ParseClock(value string) (time.Duration, error) {
+	for _, layout := range []string{"15:04:05", "15:04"} {
+		if t, err := time.Parse(layout, strings.TrimSpace(value)); err == nil {
+			return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute + time.Duration(t.Second())*time.Second, nil
+		}
+	}
+
+	return 0, fmt.Errorf("error parsing time of day \"%v\": expected format hh:mm[:ss]", value)
+}
@@ -36,6 +36,23 @@ func TestParseTimestampWithInvalidData(t *testing.T) {
 	assert.Equal(t, InvalidTimestamp, ts)
 }
 
+func TestParseDateTime(t *testing.T) {
+	expected := map[string]Timestamp{
+		"2021/10/15 15:30:25": NewTimestamp(2021, 10, 15, 15, 30, 25),
+		"2021/10/15 10:30":    NewTimestamp(2021, 10, 15, 10, 30, 0),
+	}
+
+	for k, v := range expected {
+		actual, err := ParseDateTime(k)
+		assert.NoError(t, err)
+		assert.Equal(t, v, actual)
+	}
+
+	actual, err := ParseDateTime("2021/10/15")
+	assert.Error(t, err)
+	assert.Equal(t, InvalidTimestamp, actual)
+}
+
 func TestTimestampDate(t *testing.T) {
 	expected := Date{2021, 10, 15}
 	actual := NewTimestamp(2021, 10, 15, 15, 20, 10).Date()
@@ -109,3 +126,66 @@ func TestDateString(t *testing.T) {
 	date := Date{2021, 10, 15}
 	assert.Equal(t, "2021-10-15", date.String())
 }
+
+func TestDateAddDays(t *testing.T) {
+	assert.Equal(t, Date{2021, 11, 1}, Date{2021, 10, 31}.AddDays(1))
+	assert.Equal(t, Date{2020, 12, 31}, Date{2021, 1, 1}.AddDays(-1))
+	assert.Equal(t, Date{2021, 10, 15}, Date{2021, 10, 15}.AddDays(0))
+}
+
+func TestDateBefore(t *testing.T) {
+	assert.True(t, Date{2021, 10, 14}.Before(Date{2021, 10, 15}))
+	assert.False(t, Date{2021, 10, 15}.Before(Date{2021, 10, 15}))
+	assert.False(t, Date{2022, 1, 1}.Before(Date{2021, 10, 15}))
+}
+
+func TestDateAt(t *testing.T) {
+	expected := NewTimestamp(2021, 10, 15, 9, 30, 0)
+	actual := Date{2021, 10, 15}.At(9*time.Hour + 30*time.Minute)
+	assert.Equal(t, expected, actual)
+}
+
+func TestParseDateRange(t *testing.T) {
+	from, to, err := ParseDateRange("2021/10/15")
+	assert.NoError(t, err)
+	assert.Equal(t, Date{2021, 10, 15}, from)
+	assert.Equal(t, Date{2021, 10, 15}, to)
+
+	from, to, err = ParseDateRange("2021/10/15-2021/11/30")
+	assert.NoError(t, err)
+	assert.Equal(t, Date{2021, 10, 15}, from)
+	assert.Equal(t, Date{2021, 11, 30}, to)
+}
+
+func TestParseDateRangeFailed(t *testing.T) {
+	inputs := []string{
+		"2021/10/15-2021/11/30-2021/12/01",
+		"2021/10/xx-2021/11/30",
+		"2021/10/15-2021/11/xx",
+		// Last date before first date
+		"2021/11/30-2021/10/15",
+	}
+
+	for _, input := range inputs {
+		from, to, err := ParseDateRange(input)
+		assert.Error(t, err)
+		assert.Equal(t, InvalidDate, from)
+		assert.Equal(t, InvalidDate, to)
+	}
+}
+
+func TestParseClock(t *testing.T) {
+	expected := map[string]time.Duration{
+		"09:00":    9 * time.Hour,
+		"12:30:15": 12*time.Hour + 30*time.Minute + 15*time.Second,
+	}
+
+	for k, v := range expected {
+		actual, err := ParseClock(k)
+		assert.NoError(t, err)
+		assert.Equal(t, v, actual)
+	}
+
+	_, err := ParseClock("25:00")
+	assert.Error(t, err)
+}